@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/daigoro86dev/k8s-healer/pkg/healer"
+	"github.com/daigoro86dev/k8s-healer/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	benchPods             int
+	benchEvents           int
+	benchUnhealthyPercent int
+	benchRate             float64
+	benchSeed             int64
+	benchOutput           string
+)
+
+// benchCmd load-tests the decision pipeline with synthetic Pod updates.
+var benchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "Load-test the decision pipeline with synthetic pod updates (no API calls).",
+	Long: `Generates synthetic Pod updates and feeds them through the processing queue and the decision
+pipeline with the configured cooldown, thresholds, actions, conditions and queue settings, without
+connecting to a cluster. Pods that would be healed are only marked as healed. Reports throughput,
+queue-to-decision latency and memory, to validate performance changes and size the healer.
+
+Usage Examples:
+  k8s-healer bench --pods 50000 --events 1000000
+  k8s-healer bench --pods 10000 --events 200000 --rate 5000 --queue-workers 8
+  k8s-healer bench --unhealthy-condition 'pod.status.phase == "Pending"' -o json
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := validateFlags(); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if benchPods < 1 || benchEvents < 1 || benchUnhealthyPercent < 0 || benchUnhealthyPercent > 100 {
+			fmt.Println("Error: --pods and --events must be at least 1 and --unhealthy-percent between 0 and 100.")
+			os.Exit(1)
+		}
+
+		h := healer.NewBenchHealer()
+		h.HealCooldown = healCooldown
+		h.MinPodAge = minPodAge
+		h.MinUnhealthyDuration = minUnhealthy
+		h.RestartThreshold = restartThreshold
+		h.DefaultAction = healAction
+		h.QueueSize = queueSize
+		h.QueueWorkers = queueWorkers
+		var err error
+		if h.ExitCodeActions, err = healer.ParseExitCodeActions(exitCodeActions); err != nil {
+			fmt.Printf("Error parsing --exit-code-action: %v\n", err)
+			os.Exit(1)
+		}
+		if h.CheckActions, err = healer.ParseCheckActions(checkActions); err != nil {
+			fmt.Printf("Error parsing --check-action: %v\n", err)
+			os.Exit(1)
+		}
+		if h.CustomConditions, err = util.CompileCELConditions(celConditions); err != nil {
+			fmt.Printf("Error compiling custom conditions: %v\n", err)
+			os.Exit(1)
+		}
+
+		out := cmd.OutOrStdout()
+		fmt.Fprintf(out, "Benchmarking %d updates over %d pods (%d%% unhealthy) with %d workers and a queue of %d...\n",
+			benchEvents, benchPods, benchUnhealthyPercent, queueWorkers, queueSize)
+
+		// The pipeline logs every decision; keep it from dominating the measurement
+		h.Log = slog.New(slog.DiscardHandler)
+		res := h.Bench(healer.BenchConfig{
+			Pods:             benchPods,
+			Events:           benchEvents,
+			UnhealthyPercent: benchUnhealthyPercent,
+			Rate:             benchRate,
+			Seed:             benchSeed,
+		})
+
+		if benchOutput == "json" {
+			enc := json.NewEncoder(out)
+			enc.SetIndent("", "  ")
+			_ = enc.Encode(res)
+			return
+		}
+		printBenchResult(out, res)
+	},
+}
+
+func init() {
+	benchCmd.Flags().IntVar(&benchPods, "pods", 10000, "Number of distinct synthetic pods.")
+	benchCmd.Flags().IntVar(&benchEvents, "events", 100000, "Number of pod updates to generate.")
+	benchCmd.Flags().IntVar(&benchUnhealthyPercent, "unhealthy-percent", 5, "Share of updates showing a crash-looping pod.")
+	benchCmd.Flags().Float64Var(&benchRate, "rate", 0, "Updates per second; 0 generates them as fast as possible.")
+	benchCmd.Flags().Int64Var(&benchSeed, "seed", 1, "Random seed, for reproducible runs.")
+	benchCmd.Flags().StringVarP(&benchOutput, "output", "o", "table", "Output format: table or json.")
+	rootCmd.AddCommand(benchCmd)
+}
+
+func printBenchResult(out io.Writer, r healer.BenchResult) {
+	fmt.Fprintf(out, "\nUpdates:     %d generated, %d checked, %d merged, %d dropped (queue full)\n",
+		r.Events, r.Processed, r.Merged, r.Dropped)
+	fmt.Fprintf(out, "Heals:       %d\n", r.Heals)
+	fmt.Fprintf(out, "Duration:    %s\n", r.Duration.Round(time.Millisecond))
+	fmt.Fprintf(out, "Throughput:  %.0f checks/s\n", r.Throughput)
+	fmt.Fprintf(out, "Latency:     p50 %s, p90 %s, p99 %s, max %s (queued to decided)\n",
+		r.LatencyP50.Round(time.Microsecond), r.LatencyP90.Round(time.Microsecond),
+		r.LatencyP99.Round(time.Microsecond), r.LatencyMax.Round(time.Microsecond))
+	fmt.Fprintf(out, "Memory:      %.1f MiB allocated (%.0f B/update), %.1f MiB retained\n",
+		float64(r.AllocatedBytes)/(1<<20), float64(r.AllocatedBytes)/float64(r.Events), float64(r.RetainedBytes)/(1<<20))
+	fmt.Fprintf(out, "Goroutines:  %d\n", r.Goroutines)
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/user"
+	"text/tabwriter"
+	"time"
+
+	"github.com/daigoro86dev/k8s-healer/pkg/control"
+	"github.com/spf13/cobra"
+)
+
+var (
+	blackoutNamespace string
+	blackoutFor       time.Duration
+	blackoutReason    string
+	controlActor      string
+)
+
+// blackoutCmd records a temporary, self-expiring suppression of healing in the control ConfigMap.
+var blackoutCmd = &cobra.Command{
+	Use:   "blackout",
+	Short: "Suppress healing in a namespace for a limited time (e.g. during a migration).",
+	Long: `Records a time-bounded blackout in the control ConfigMap. While it is active, the healer
+keeps observing but only notifies. Blackouts expire automatically; every change is written
+to the audit trail in the same ConfigMap.
+
+Usage Examples:
+  k8s-healer blackout --namespace prod --for 2h --reason "db migration"
+  k8s-healer blackout --namespace 'team-a-*' --for 30m --reason "chaos day"
+  k8s-healer blackout list
+  k8s-healer blackout end <id>
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if blackoutNamespace == "" || blackoutFor <= 0 || blackoutReason == "" {
+			fmt.Println("Error: --namespace, --for and --reason are required.")
+			os.Exit(1)
+		}
+
+		now := time.Now().UTC()
+		b := control.Blackout{
+			ID:        newOperationID(),
+			Namespace: blackoutNamespace,
+			Reason:    blackoutReason,
+			CreatedBy: actorName(),
+			CreatedAt: now,
+			ExpiresAt: now.Add(blackoutFor),
+		}
+
+		err := controlStore().Update(context.Background(), func(s *control.State) error {
+			s.Blackouts = append(s.Blackouts, b)
+			s.AddAudit(b.CreatedBy, "blackout-created",
+				fmt.Sprintf("%s (namespace %s, until %s, reason %q)", b.ID, b.Namespace, b.ExpiresAt.Format(time.RFC3339), b.Reason))
+			return nil
+		})
+		if err != nil {
+			fmt.Printf("Error recording blackout: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Blackout %s active for namespace %s until %s.\n", b.ID, b.Namespace, b.ExpiresAt.Local().Format(time.RFC1123))
+	},
+}
+
+var blackoutListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List active blackouts.",
+	Run: func(cmd *cobra.Command, args []string) {
+		state, err := controlStore().Load(context.Background())
+		if err != nil {
+			fmt.Printf("Error loading control state: %v\n", err)
+			os.Exit(1)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAMESPACE\tEXPIRES\tCREATED BY\tREASON")
+		now := time.Now()
+		for _, b := range state.Blackouts {
+			if !b.Active(now) {
+				continue
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s (in %s)\t%s\t%s\n", b.ID, b.Namespace, b.ExpiresAt.Local().Format(time.RFC3339),
+				b.ExpiresAt.Sub(now).Round(time.Minute), b.CreatedBy, b.Reason)
+		}
+		_ = w.Flush()
+	},
+}
+
+var blackoutEndCmd = &cobra.Command{
+	Use:   "end <id>",
+	Short: "End a blackout before it expires.",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		id := args[0]
+		err := controlStore().Update(context.Background(), func(s *control.State) error {
+			for i, b := range s.Blackouts {
+				if b.ID == id {
+					s.Blackouts = append(s.Blackouts[:i], s.Blackouts[i+1:]...)
+					s.AddAudit(actorName(), "blackout-ended", fmt.Sprintf("%s (namespace %s)", b.ID, b.Namespace))
+					return nil
+				}
+			}
+			return fmt.Errorf("no blackout with id %s", id)
+		})
+		if err != nil {
+			fmt.Printf("Error ending blackout: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Blackout %s ended.\n", id)
+	},
+}
+
+func init() {
+	blackoutCmd.Flags().StringVar(&blackoutNamespace, "namespace", "", "Namespace (or glob, '*' for all) to black out.")
+	blackoutCmd.Flags().DurationVar(&blackoutFor, "for", 0, "How long the blackout lasts (e.g. 2h).")
+	blackoutCmd.Flags().StringVar(&blackoutReason, "reason", "", "Why healing is suppressed; recorded in the audit trail.")
+	blackoutCmd.AddCommand(blackoutListCmd, blackoutEndCmd)
+	rootCmd.AddCommand(blackoutCmd)
+}
+
+// controlStore connects to the cluster and returns the control ConfigMap store.
+func controlStore() *control.Store {
+	clientset, err := buildClientset(kubeconfigPath)
+	if err != nil {
+		fmt.Printf("Error setting up Kubernetes client: %v\n", err)
+		os.Exit(1)
+	}
+	return control.NewStore(clientset, controlNamespace, controlConfigMap)
+}
+
+// actorName identifies the human or automation running a control command.
+func actorName() string {
+	if controlActor != "" {
+		return controlActor
+	}
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return "unknown"
+}
+
+// newOperationID returns a short random identifier for control objects.
+func newOperationID() string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
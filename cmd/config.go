@@ -0,0 +1,83 @@
+package main
+
+import (
+	"github.com/daigoro86dev/k8s-healer/pkg/healer"
+	"github.com/spf13/cobra"
+)
+
+var (
+	configPath string
+	fileConfig *healer.Config // Loaded from --config, with the settings overridden by flags removed
+)
+
+// configFlags maps flags to the configuration file settings they override when set explicitly.
+var configFlags = map[string]func(*healer.Config){
+	"namespaces":             func(c *healer.Config) { c.Namespaces = nil },
+	"exclude-namespaces":     func(c *healer.Config) { c.ExcludeNamespaces = nil },
+	"namespace-selector":     func(c *healer.Config) { c.NamespaceSelector = "" },
+	"discover-namespaces":    func(c *healer.Config) { c.DiscoverNamespaces = nil },
+	"watch-strategy":         func(c *healer.Config) { c.WatchStrategy = "" },
+	"cluster-threshold":      func(c *healer.Config) { c.ClusterThreshold = nil },
+	"kube-api-qps":           func(c *healer.Config) { c.KubeAPIQPS = nil },
+	"kube-api-burst":         func(c *healer.Config) { c.KubeAPIBurst = nil },
+	"cluster-name":           func(c *healer.Config) { c.ClusterName = "" },
+	"mode":                   func(c *healer.Config) { c.Mode = "" },
+	"heal-cooldown":          func(c *healer.Config) { c.HealCooldown = nil },
+	"max-heal-cooldown":      func(c *healer.Config) { c.MaxHealCooldown = nil },
+	"cooldown-reset":         func(c *healer.Config) { c.CooldownReset = nil },
+	"heal-cache-size":        func(c *healer.Config) { c.HealCacheSize = nil },
+	"max-heals-per-minute":   func(c *healer.Config) { c.MaxHealsPerMinute = nil },
+	"owner-heal-budget":      func(c *healer.Config) { c.OwnerHealBudget = nil },
+	"owner-budget-window":    func(c *healer.Config) { c.OwnerBudgetWindow = nil },
+	"breaker-threshold":      func(c *healer.Config) { c.BreakerThreshold = nil },
+	"breaker-interval":       func(c *healer.Config) { c.BreakerInterval = nil },
+	"breaker-cooloff":        func(c *healer.Config) { c.BreakerCooloff = nil },
+	"flap-threshold":         func(c *healer.Config) { c.FlapThreshold = nil },
+	"flap-window":            func(c *healer.Config) { c.FlapWindow = nil },
+	"chronic-threshold":      func(c *healer.Config) { c.ChronicThreshold = nil },
+	"chronic-window":         func(c *healer.Config) { c.ChronicWindow = nil },
+	"min-pod-age":            func(c *healer.Config) { c.MinPodAge = nil },
+	"min-unhealthy-duration": func(c *healer.Config) { c.MinUnhealthyDuration = nil },
+	"restart-threshold":      func(c *healer.Config) { c.RestartThreshold = nil },
+	"unhealthy-condition":    func(c *healer.Config) { c.UnhealthyConditions = nil },
+	"selector":               func(c *healer.Config) { c.LabelSelector = "" },
+	"label-selector":         func(c *healer.Config) { c.LabelSelector = "" },
+	"field-selector":         func(c *healer.Config) { c.FieldSelector = "" },
+	"owner-kinds":            func(c *healer.Config) { c.OwnerKinds = nil },
+	"protected-namespaces":   func(c *healer.Config) { c.Protected.Namespaces = nil },
+	"protected-pods":         func(c *healer.Config) { c.Protected.Pods = nil },
+	"event-detection":        func(c *healer.Config) { c.EventDetection = nil },
+	"event-reasons":          func(c *healer.Config) { c.EventReasons = nil },
+	"event-threshold":        func(c *healer.Config) { c.EventThreshold = nil },
+	"heal-action":            func(c *healer.Config) { c.Action = "" },
+	"exit-code-action":       func(c *healer.Config) { c.ExitCodeActions = nil },
+	"check-action":           func(c *healer.Config) { c.CheckActions = nil },
+	"require-approval":       func(c *healer.Config) { c.RequireApproval = nil },
+	"notify-routes":          func(c *healer.Config) { c.Notifications = nil },
+	"freeze-calendar-url":    func(c *healer.Config) { c.FreezeCalendarURL = "" },
+	"no-heal-window":         func(c *healer.Config) { c.NoHealWindows = nil },
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", "",
+		"Path to a YAML configuration file (namespaces, thresholds, cooldowns, actions, per-namespace overrides, notifications). Flags set explicitly take precedence.")
+}
+
+// loadConfigFile loads --config, if set, into fileConfig. Settings given explicitly as flags are
+// dropped from it, so flags always take precedence over the file.
+func loadConfigFile(cmd *cobra.Command) error {
+	if configPath == "" {
+		return nil
+	}
+	cfg, err := healer.LoadConfig(configPath)
+	if err != nil {
+		return err
+	}
+	for name, clear := range configFlags {
+		if cmd.Flags().Changed(name) {
+			clear(cfg)
+		}
+	}
+	fileConfig = cfg
+	return nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/daigoro86dev/k8s-healer/pkg/fleet"
+	"github.com/spf13/cobra"
+)
+
+var (
+	fleetEndpoints []string
+	fleetTimeout   time.Duration
+	fleetOutput    string
+	fleetListen    string
+)
+
+// fleetCmd aggregates the status APIs of many healer instances into one summary.
+var fleetCmd = &cobra.Command{
+	Use:   "fleet",
+	Short: "Aggregate the status of multiple healer instances (e.g. one per cluster).",
+	Long: `Queries the status API (--status-addr) of every given healer instance and prints a single
+summary of heals, circuit breakers, blackouts and degraded namespaces across the fleet.
+
+Usage Examples:
+  k8s-healer fleet --endpoints http://healer.prod-eu:8080,http://healer.prod-us:8080
+  k8s-healer fleet --endpoints ... -o json
+  k8s-healer fleet --endpoints ... --listen :9090      # serve the aggregate on /fleet
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(fleetEndpoints) == 0 {
+			fmt.Println("Error: --endpoints is required.")
+			os.Exit(1)
+		}
+		client := &http.Client{Timeout: fleetTimeout}
+
+		if fleetListen != "" {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/fleet", func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_ = json.NewEncoder(w).Encode(fleet.Collect(r.Context(), client, fleetEndpoints))
+			})
+			serveHTTP("fleet view", fleetListen, mux)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), fleetTimeout)
+		defer cancel()
+		summary := fleet.Collect(ctx, client, fleetEndpoints)
+
+		if fleetOutput == "json" {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			_ = enc.Encode(summary)
+			return
+		}
+		printFleetSummary(summary)
+	},
+}
+
+func init() {
+	fleetCmd.Flags().StringSliceVar(&fleetEndpoints, "endpoints", nil, "Comma-separated base URLs of healer status APIs.")
+	fleetCmd.Flags().DurationVar(&fleetTimeout, "timeout", 10*time.Second, "Timeout for querying the instances.")
+	fleetCmd.Flags().StringVarP(&fleetOutput, "output", "o", "table", "Output format: table or json.")
+	fleetCmd.Flags().StringVar(&fleetListen, "listen", "", "Serve the aggregated view as JSON on /fleet at this address instead of printing it.")
+	rootCmd.AddCommand(fleetCmd)
+}
+
+func printFleetSummary(s fleet.Summary) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "INSTANCE\tCLUSTER\tAPI\tHEALS(1h)\tFAILED(1h)\tBREAKERS\tBLACKOUTS")
+	for _, inst := range s.Instances {
+		if inst.Status == nil {
+			fmt.Fprintf(w, "%s\t-\tunreachable\t-\t-\t-\t-\t(%s)\n", inst.Endpoint, inst.Error)
+			continue
+		}
+		st := inst.Status
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%d\t%d\n", st.Healer, st.Cluster, st.APIState,
+			st.HealsLastHour, st.FailedHealsLastHour, len(st.CircuitBreakers), len(st.Blackouts))
+	}
+	_ = w.Flush()
+
+	fmt.Printf("\nFleet: %d reachable, %d unreachable — %d heals (%d failed) in the last hour, %d open circuit breakers, %d active blackouts.\n",
+		s.Reachable, s.Unreachable, s.HealsLastHour, s.FailedHealsLastHour, s.OpenCircuitBreakers, s.ActiveBlackouts)
+	if len(s.DegradedAPIs) > 0 {
+		fmt.Printf("Degraded API servers: %s\n", strings.Join(s.DegradedAPIs, ", "))
+	}
+	if len(s.DegradedNamespaces) > 0 {
+		fmt.Println("\nMost-healed namespaces:")
+		for i, ns := range s.DegradedNamespaces {
+			if i == 10 {
+				break
+			}
+			fmt.Printf("  %s/%s: %d heals, %d failed\n", ns.Cluster, ns.Namespace, ns.Heals, ns.Failures)
+		}
+	}
+}
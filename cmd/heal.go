@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/daigoro86dev/k8s-healer/pkg/healer"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+var (
+	healEndpoint string
+	healStrategy string
+	healReason   string
+	healTimeout  time.Duration
+	healToken    string
+)
+
+// healCmd asks a running healer to heal a Pod or a workload through its control API.
+var healCmd = &cobra.Command{
+	Use:   "heal <pod|kind/name>",
+	Short: "Ask a running healer to heal a pod or a workload now, with a chosen strategy.",
+	Long: `Sends a manual heal to the control API (--status-addr) of a running healer instance, which
+runs it through the same guards as automatic heals (opt-outs, cooldowns, pause, blackouts, freezes,
+paused Deployments, API health, the decision webhook, approvals, budgets and the rate limit) and records it in its history,
+notifications and audit trail. The target is a pod name or a workload reference (deployment/<name>,
+statefulset/<name>) in the namespace given with -n. The call is authenticated with --token, or the
+bearer token of the kubeconfig user, which needs RBAC to create controloperations.k8s-healer.io.
+
+Strategies:
+  delete           Delete the pod, or every unready pod of the workload (default: --heal-action of the healer)
+  evict            Like delete, through the Eviction API so PodDisruptionBudgets are honored
+  rollout-restart  Restart the owning Deployment/StatefulSet like 'kubectl rollout restart'
+  rollback         Roll the owning Deployment back to its previous revision, if its newest one is crash-looping
+
+Usage Examples:
+  k8s-healer heal api-7d8f9 -n prod --healer http://healer:8080 --reason "stuck on a dead connection"
+  k8s-healer heal deployment/api -n prod --healer http://healer:8080 --strategy evict --reason "INC-4711"
+  k8s-healer heal deployment/api -n prod --healer http://healer:8080 --strategy rollback --reason "bad release"
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if healEndpoint == "" || healReason == "" {
+			fmt.Println("Error: --healer and --reason are required.")
+			os.Exit(1)
+		}
+		if namespaces == "" || strings.ContainsAny(namespaces, ",*") {
+			fmt.Println("Error: -n must name exactly one namespace.")
+			os.Exit(1)
+		}
+		if healStrategy != "" && healStrategy != healer.ActionDelete && healStrategy != healer.StrategyEvict &&
+			healStrategy != healer.ActionRolloutRestart && healStrategy != healer.ActionRollback {
+			fmt.Printf("Error: invalid --strategy %q (delete, evict, rollout-restart or rollback).\n", healStrategy)
+			os.Exit(1)
+		}
+
+		req := map[string]string{"namespace": namespaces, "strategy": healStrategy, "reason": healReason}
+		if kind, name, ok := strings.Cut(args[0], "/"); ok {
+			if strings.EqualFold(kind, "pod") || strings.EqualFold(kind, "po") {
+				req["pod"] = name
+			} else {
+				req["kind"], req["name"] = kind, name
+			}
+		} else {
+			req["pod"] = args[0]
+		}
+
+		status, response, err := postControl(healEndpoint, "/control/heal", req)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		printHealResponse(status, response)
+		if status != http.StatusOK && status != http.StatusAccepted {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	healCmd.Flags().StringVar(&healEndpoint, "healer", "", "Base URL of the healer's status API (e.g. http://healer:8080).")
+	healCmd.Flags().StringVar(&healStrategy, "strategy", "", "Heal strategy: delete, evict, rollout-restart or rollback. Required for workloads.")
+	healCmd.Flags().StringVar(&healReason, "reason", "", "Why the heal is requested; recorded in the audit trail.")
+	healCmd.Flags().DurationVar(&healTimeout, "timeout", time.Minute, "Timeout for each attempt of the heal request.")
+	healCmd.Flags().StringVar(&healToken, "token", "", "Bearer token for the control API (defaults to the kubeconfig user's token).")
+	rootCmd.AddCommand(healCmd)
+}
+
+// controlAttempts is how often postControl tries a call before giving up.
+const controlAttempts = 3
+
+// postControl calls a control API operation with the token from controlToken. Calls that don't reach
+// the healer, fail at a gateway or find the operation still in progress are retried with the same
+// idempotency key, so the healer runs the operation once and answers retries with its result.
+func postControl(endpoint, path string, body interface{}) (int, map[string]interface{}, error) {
+	token, err := controlToken()
+	if err != nil {
+		return 0, nil, err
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return 0, nil, err
+	}
+	url := strings.TrimSuffix(endpoint, "/") + path
+	key := newOperationID()
+	client := &http.Client{Timeout: healTimeout}
+	for attempt := 1; ; attempt++ {
+		status, response, retry, err := callControl(client, url, token, key, data)
+		if !retry || attempt == controlAttempts {
+			return status, response, err
+		}
+		if err != nil {
+			fmt.Printf("Retrying: %v\n", err)
+		}
+		time.Sleep(time.Duration(attempt) * time.Second)
+	}
+}
+
+// callControl makes one attempt of a control API call and reports whether it is worth retrying.
+func callControl(client *http.Client, url, token, key string, data []byte) (int, map[string]interface{}, bool, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return 0, nil, false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set(healer.IdempotencyKeyHeader, key)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, nil, true, fmt.Errorf("failed to reach the healer: %w", err)
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return 0, nil, true, fmt.Errorf("failed to read the healer's response: %w", err)
+	}
+	var response map[string]interface{}
+	if err := json.Unmarshal(raw, &response); err != nil {
+		// Not an answer of the healer, e.g. a proxy in front of it failing
+		return 0, nil, resp.StatusCode >= 500, fmt.Errorf("unexpected response (HTTP %d): %s", resp.StatusCode, strings.TrimSpace(string(raw)))
+	}
+	// The operation is still running, e.g. after a retried timeout
+	retry := resp.StatusCode == http.StatusConflict && resp.Header.Get("Retry-After") != ""
+	return resp.StatusCode, response, retry, nil
+}
+
+// controlToken returns the bearer token identifying the caller to the control API: --token, or the
+// token of the kubeconfig user.
+func controlToken() (string, error) {
+	if healToken != "" {
+		return healToken, nil
+	}
+	config, err := clientcmd.BuildConfigFromFlags("", kubeconfigPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to build Kubernetes config: %w", err)
+	}
+	if config.BearerTokenFile != "" {
+		token, err := os.ReadFile(config.BearerTokenFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read the kubeconfig token file: %w", err)
+		}
+		return strings.TrimSpace(string(token)), nil
+	}
+	if config.BearerToken == "" {
+		return "", fmt.Errorf("the kubeconfig user has no bearer token; pass one with --token (e.g. from 'kubectl create token')")
+	}
+	return config.BearerToken, nil
+}
+
+func printHealResponse(status int, r map[string]interface{}) {
+	switch status {
+	case http.StatusOK, http.StatusBadGateway:
+	case http.StatusAccepted:
+		fmt.Printf("%v: %v; approve it on the pod to proceed.\n", r["target"], r["error"])
+		return
+	default:
+		fmt.Printf("Heal refused (HTTP %d): %v\n", status, r["error"])
+		return
+	}
+	fmt.Printf("Healed %v with strategy %v:\n", r["target"], r["strategy"])
+	results, _ := r["results"].([]interface{})
+	for _, item := range results {
+		res, _ := item.(map[string]interface{})
+		if e, ok := res["error"]; ok {
+			fmt.Printf("  ❌ %v: %v failed: %v\n", res["pod"], res["action"], e)
+		} else {
+			fmt.Printf("  ✅ %v: %v\n", res["pod"], res["action"])
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/daigoro86dev/k8s-healer/pkg/healer"
+	"github.com/daigoro86dev/k8s-healer/pkg/history"
+	"github.com/daigoro86dev/k8s-healer/pkg/state"
+	"github.com/daigoro86dev/k8s-healer/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	historySince     time.Duration
+	historyRollups   bool
+	historyWorkloads bool
+	historyOutput    string
+)
+
+// historyCmd prints the heal history saved to a --state-dir database or --state-redis-url.
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Print the heal history saved in a --state-dir database or in Redis.",
+	Long: `Reads the heal records, or the monthly rollups they are compacted into, from the state
+database a healer started with --state-dir saves to, or from the Redis state healers started with
+--state-redis-url share. The database can only be read while no healer has it open, e.g. after the
+healer stopped or from a copy of the directory; Redis can be read at any time.
+
+Usage Examples:
+  k8s-healer history --state-dir /var/lib/k8s-healer
+  k8s-healer history --state-dir /var/lib/k8s-healer -n 'prod-*' --since 24h
+  k8s-healer history --state-dir /var/lib/k8s-healer --rollups -o json
+  k8s-healer history --state-dir /var/lib/k8s-healer --workloads --since 168h
+  k8s-healer history --state-redis-url redis://redis.k8s-healer:6379/0
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var db state.Store
+		var err error
+		switch {
+		case stateRedisURL != "":
+			db, err = state.OpenRedis(stateRedisURL, stateRedisPrefix)
+		case stateDir != "":
+			db, err = state.OpenReadOnly(stateDir)
+		default:
+			fmt.Println("Error: --state-dir or --state-redis-url is required.")
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer db.Close()
+		records, rollups, err := healer.LoadHistory(db)
+		if err != nil {
+			fmt.Printf("Error reading the heal history: %v\n", err)
+			os.Exit(1)
+		}
+
+		var patterns []string
+		if namespaces != "" {
+			patterns = strings.Split(namespaces, ",")
+		}
+		selected := func(namespace string) bool {
+			for _, p := range patterns {
+				if util.MatchNamespace(strings.TrimSpace(p), namespace) {
+					return true
+				}
+			}
+			return len(patterns) == 0
+		}
+
+		if historyRollups {
+			var out []history.Rollup
+			for _, r := range rollups {
+				if selected(r.Namespace) {
+					out = append(out, r)
+				}
+			}
+			printHistory(out, func(w *tabwriter.Writer) {
+				fmt.Fprintln(w, "MONTH\tNAMESPACE\tCHECK\tHEALS\tFAILURES\tEFFECTIVE\tRELAPSED\tUNRECOVERED")
+				for _, r := range out {
+					fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%d\t%d\t%d\n", r.Month, r.Namespace, r.Check, r.Heals, r.Failures, r.Effective,
+						r.Relapsed, r.Unrecovered)
+				}
+			})
+			return
+		}
+
+		var out []history.Record
+		for _, r := range records {
+			if selected(r.Namespace) && (historySince <= 0 || time.Since(r.Time) <= historySince) {
+				out = append(out, r)
+			}
+		}
+		if historyWorkloads {
+			stats := history.Workloads(out)
+			printHistory(stats, func(w *tabwriter.Writer) {
+				fmt.Fprintln(w, "NAMESPACE\tOWNER\tHEALS\tFAILURES\tUNRECOVERED\tRELAPSED\tSUCCESS\tRECOVERY P50\tP90\tMAX")
+				for _, s := range stats {
+					fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%d\t%.0f%%\t%s\t%s\t%s\n", s.Namespace, s.Owner, s.Heals, s.Failures,
+						s.Unrecovered, s.Relapsed, 100*s.SuccessRate, seconds(s.RecoveryP50Seconds), seconds(s.RecoveryP90Seconds),
+						seconds(s.RecoveryMaxSeconds))
+				}
+			})
+			return
+		}
+		printHistory(out, func(w *tabwriter.Writer) {
+			fmt.Fprintln(w, "TIME\tNAMESPACE\tPOD\tOWNER\tCHECK\tACTION\tRESULT\tOUTCOME\tRECOVERY")
+			for _, r := range out {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", r.Time.Local().Format(time.RFC3339), r.Namespace, r.Pod,
+					r.Owner, r.Check, r.Action, r.Result, r.Outcome, seconds(r.RecoverySeconds))
+			}
+		})
+	},
+}
+
+func init() {
+	historyCmd.Flags().DurationVar(&historySince, "since", 0, "Only print heals within this long ago (e.g. 24h). 0 prints all of them.")
+	historyCmd.Flags().BoolVar(&historyRollups, "rollups", false, "Print the monthly rollups of compacted heals instead of the heal records.")
+	historyCmd.Flags().BoolVar(&historyWorkloads, "workloads", false,
+		"Print the success rate and recovery times per workload instead of the heal records.")
+	historyCmd.Flags().StringVarP(&historyOutput, "output", "o", "table", "Output format: table or json.")
+	rootCmd.AddCommand(historyCmd)
+}
+
+// seconds formats a duration in seconds for the tables, or "-" if it is unknown.
+func seconds(s float64) string {
+	if s <= 0 {
+		return "-"
+	}
+	return time.Duration(s * float64(time.Second)).Round(time.Second).String()
+}
+
+// printHistory prints v as JSON with -o json, or as the table written by table otherwise.
+func printHistory(v interface{}, table func(w *tabwriter.Writer)) {
+	if historyOutput == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(v)
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	table(w)
+	_ = w.Flush()
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"k8s.io/klog/v2"
+)
+
+var (
+	logLevel  string
+	logFormat string
+)
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "info",
+		"Minimum level of the log messages written: debug, info, warn or error.")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "text",
+		"Format of the log messages: text (logfmt key=value pairs) or json.")
+}
+
+// setupLogging makes the logger selected by --log-level and --log-format the default logger, used
+// by the healer and by the Kubernetes client libraries alike.
+func setupLogging() error {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(logLevel)); err != nil {
+		return fmt.Errorf("invalid --log-level %q (expected debug, info, warn or error)", logLevel)
+	}
+	opts := &slog.HandlerOptions{Level: level}
+
+	var handler slog.Handler
+	switch strings.ToLower(logFormat) {
+	case "text":
+		handler = slog.NewTextHandler(os.Stderr, opts)
+	case "json":
+		handler = slog.NewJSONHandler(os.Stderr, opts)
+	default:
+		return fmt.Errorf("invalid --log-format %q (expected text or json)", logFormat)
+	}
+
+	logger := slog.New(handler)
+	slog.SetDefault(logger)
+	klog.SetSlogLogger(logger)
+	return nil
+}
+
+// fatal logs msg at error level and exits.
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
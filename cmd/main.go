@@ -3,25 +3,157 @@ package main
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
-	"path/filepath" // Used for wildcard matching (Glob/Match)
+	"path/filepath"
 	"strings"
 	"syscall"
 	"time"
+	_ "time/tzdata" // IANA zones for schedules, even in minimal container images
 
+	"github.com/daigoro86dev/k8s-healer/pkg/annotations"
+	"github.com/daigoro86dev/k8s-healer/pkg/audit"
+	"github.com/daigoro86dev/k8s-healer/pkg/control"
 	"github.com/daigoro86dev/k8s-healer/pkg/healer"
+	"github.com/daigoro86dev/k8s-healer/pkg/history"
+	"github.com/daigoro86dev/k8s-healer/pkg/notify"
+	"github.com/daigoro86dev/k8s-healer/pkg/schedule"
+	"github.com/daigoro86dev/k8s-healer/pkg/state"
+	"github.com/daigoro86dev/k8s-healer/pkg/util"
+	"github.com/daigoro86dev/k8s-healer/pkg/webhook"
 	"github.com/spf13/cobra"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
 var (
-	kubeconfigPath string
-	namespaces     string
-	healCooldown   time.Duration
+	kubeconfigPath    string
+	kubeAPIQPS        float32
+	kubeAPIBurst      int
+	namespaces        string
+	excludeNamespaces string
+	namespaceSelector string
+	watchAnnotated    bool
+	watchStrategy     string
+	clusterThreshold  int
+	healCooldown      time.Duration
+	maxHealCooldown   time.Duration
+	cooldownReset     time.Duration
+	healCacheSize     int
+	maxHealsPerMinute int
+	ownerHealBudget   float64
+	ownerBudgetWindow time.Duration
+	breakerThreshold  int
+	breakerInterval   time.Duration
+	breakerCooloff    time.Duration
+	flapThreshold     int
+	flapWindow        time.Duration
+	chronicThreshold  int
+	chronicWindow     time.Duration
+	minUnhealthy      time.Duration
+	restartThreshold  int32
+	minPodAge         time.Duration
+	celConditions     []string
+	labelSelector     string
+	fieldSelector     string
+	ownerKinds        []string
+	eventDetection    bool
+	eventReasons      []string
+	eventThreshold    int32
+
+	protectedNamespaces     []string
+	protectedPods           []string
+	protectSystemNamespaces bool
+
+	historyMaxAge          time.Duration
+	historyMaxRecords      int
+	historyRollupMonths    int
+	historyCompactInterval time.Duration
+	effectivenessWindow    time.Duration
+	recoveryTimeout        time.Duration
+	healRecords            bool
+	healRecordTTL          time.Duration
+	eventStreamPath        string
+	auditLogPath           string
+	auditLogMaxSizeMB      int
+	auditLogMaxAge         time.Duration
+	auditLogMaxBackups     int
+
+	clusterName      string
+	notifyRoutesPath string
+
+	apiHealthThrottle     bool
+	apiLatencyThreshold   time.Duration
+	apiErrorRateThreshold float64
+	degradedHealInterval  time.Duration
+
+	completedPodTTL          time.Duration
+	completedPodTTLOverrides map[string]string
+	cleanupDisruptedPods     bool
+
+	pausedDeploymentBehavior  string
+	mode                      string
+	healAction                string
+	useEviction               bool
+	respectPDBs               bool
+	checkResourceQuota        bool
+	replacementPrefix         string
+	remediationWebhookURL     string
+	remediationWebhookTimeout time.Duration
+	queueSize                 int
+	queueWorkers              int
+	healConcurrency           int
+	healRetries               int
+	resyncPeriod              time.Duration
+	reconcileInterval         time.Duration
+	approvalNamespaces        []string
+	preDeleteCommand          string
+	preDeleteContainer        string
+	preDeleteTimeout          time.Duration
+	startupReport             bool
+	startupReportNotify       bool
+	remediationJobTemplate    string
+	remediationJobDelete      bool
+	allowFinalizerRemoval     bool
+	stuckTerminatingAfter     time.Duration
+	healGracePeriod           int64
+	healPropagation           string
+	exitCodeActions           map[string]string
+	checkActions              map[string]string
+	scaleCyclePause           time.Duration
+	memoryBumpPercent         int
+	memoryBumpMax             string
+	containerRestartCommand   string
+
+	decisionWebhookURL      string
+	decisionWebhookTimeout  time.Duration
+	decisionWebhookFailOpen bool
+
+	controlNamespace string
+	controlConfigMap string
+
+	stateConfigMap     string
+	checkpointInterval time.Duration
+	stateDir           string
+	stateRedisURL      string
+	stateRedisPrefix   string
+
+	freezeCalendarURL     string
+	freezeCalendarRefresh time.Duration
+	noHealWindows         []string
+	noHealWindowDuration  time.Duration
+
+	statusAddr  string
+	metricsAddr string
+	debugAddr   string
+
+	timezone           string
+	namespaceTimezones map[string]string
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -31,143 +163,836 @@ var rootCmd = &cobra.Command{
 	Long: `k8s-healer monitors specified Kubernetes namespaces for persistently unhealthy pods (e.g., in CrashLoopBackOff) 
 and performs a healing action by deleting the pod, forcing its controller to recreate it.
 
-The -n/--namespaces flag supports comma-separated values and simple wildcards (*).
+The -n/--namespaces and -N/--exclude-namespaces flags support comma-separated values, simple
+wildcards (*) and regular expressions prefixed with re: (e.g. 're:^team-(a|b)-prod$').
 
 Usage Examples:
   k8s-healer -n prod,staging              # Watch specific namespaces
   k8s-healer -n 'app-*-dev,kube-*'        # Watch namespaces matching wildcards
   k8s-healer                              # Watch all namespaces
+  k8s-healer -N 'kube-*,cert-manager'     # Watch all namespaces except these
+  k8s-healer -n 're:^team-(a|b)-prod$'    # Watch namespaces matching a regular expression
   k8s-healer -k /path/to/my/kubeconfig    # Use specific kubeconfig
 `,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return setupLogging()
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		startHealer(cmd)
+	},
+}
+
+// webhookCmd runs the admission webhook that annotates Pods lacking probes or limits.
+var webhookCmd = &cobra.Command{
+	Use:   "webhook",
+	Short: "Run the admission webhook that annotates Pods lacking probes/limits with healer hints.",
+	Long: `Runs a mutating admission webhook (register it with failurePolicy: Ignore) that never blocks
+requests. Pods created without liveness/readiness probes or resource limits get the
+k8s-healer.io/hints annotation and a warning. The healer logs the hints of the Pods it heals.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		startHealer()
+		startWebhook()
 	},
 }
 
+var (
+	webhookAddr string
+	tlsCertFile string
+	tlsKeyFile  string
+)
+
+func init() {
+	webhookCmd.Flags().StringVar(&webhookAddr, "webhook-addr", ":8443", "Address the webhook server listens on.")
+	webhookCmd.Flags().StringVar(&tlsCertFile, "tls-cert-file", "", "TLS certificate file for the webhook server (required).")
+	webhookCmd.Flags().StringVar(&tlsKeyFile, "tls-key-file", "", "TLS private key file for the webhook server (required).")
+	rootCmd.AddCommand(webhookCmd)
+}
+
 func init() {
 	// Global flags handled by Cobra
 	rootCmd.PersistentFlags().StringVarP(&kubeconfigPath, "kubeconfig", "k", "", "Path to the kubeconfig file (defaults to standard locations).")
+	rootCmd.PersistentFlags().Float32Var(&kubeAPIQPS, "kube-api-qps", healer.DefaultKubeAPIQPS,
+		"Sustained requests per second to the API server, shared by all informers and heals.")
+	rootCmd.PersistentFlags().IntVar(&kubeAPIBurst, "kube-api-burst", healer.DefaultKubeAPIBurst,
+		"Requests allowed in a burst above --kube-api-qps, e.g. while listing pods on startup.")
 	rootCmd.PersistentFlags().StringVarP(&namespaces, "namespaces", "n", "", "Comma-separated list of namespaces/workspaces to watch (e.g., 'prod,staging'). Supports wildcards (*). Defaults to all namespaces if empty.")
+	rootCmd.PersistentFlags().StringVarP(&excludeNamespaces, "exclude-namespaces", "N", "", "Comma-separated list of namespaces not to watch or heal, with the same wildcard syntax as -n (e.g., 'kube-*,cert-manager').")
+	rootCmd.PersistentFlags().StringVar(&namespaceSelector, "namespace-selector", "", "Label selector choosing the namespaces to watch (e.g. 'environment=prod,tier!=system'), kept current as labels change. Combines with -n and -N.")
+	rootCmd.PersistentFlags().BoolVar(&watchAnnotated, "discover-namespaces", false, "Watch namespaces annotated k8s-healer.io/watch=true, picking them up and dropping them as the annotation is added and removed. Combines with -n, -N and --namespace-selector.")
+	rootCmd.PersistentFlags().StringVar(&watchStrategy, "watch-strategy", healer.WatchAuto,
+		"How Pods are watched: per-namespace (informers per namespace), cluster (one cluster-wide informer filtered in process; needs cluster-wide list/watch) or auto (cluster above --cluster-threshold namespaces).")
+	rootCmd.PersistentFlags().IntVar(&clusterThreshold, "cluster-threshold", healer.DefaultClusterWatchThreshold,
+		"With --watch-strategy=auto, the number of watched namespaces above which a single cluster-wide informer is used.")
 	rootCmd.PersistentFlags().DurationVar(&healCooldown, "heal-cooldown", 10*time.Minute,
-		"Minimum time between healing the same Pod (e.g. 10m, 30s).")
+		"Minimum time between healing the same workload (e.g. 10m, 30s).")
+	rootCmd.PersistentFlags().DurationVar(&maxHealCooldown, "max-heal-cooldown", healer.DefaultMaxHealCooldown,
+		"Cap on the cooldown, which doubles with each heal of a workload that fails again within --cooldown-reset of its cooldown (10m, 20m, 40m, ...). Set it to --heal-cooldown for a fixed cooldown.")
+	rootCmd.PersistentFlags().DurationVar(&cooldownReset, "cooldown-reset", healer.DefaultCooldownReset,
+		"How long a workload must stay healthy after its cooldown for the cooldown to start over at --heal-cooldown.")
+	rootCmd.PersistentFlags().IntVar(&healCacheSize, "heal-cache-size", healer.DefaultHealCacheSize,
+		"How many workloads' cooldowns are remembered; beyond it the least recently healed workloads are forgotten.")
+	rootCmd.PersistentFlags().IntVar(&maxHealsPerMinute, "max-heals-per-minute", 0,
+		"Cap on automatic heals across the cluster per minute, so a widespread outage isn't amplified by mass deletions; further heals are deferred. 0 means no limit.")
+	rootCmd.PersistentFlags().Float64Var(&ownerHealBudget, "owner-heal-budget", 0,
+		"Fraction of an owner's pods (e.g. 0.25) that may be healed per --owner-budget-window; further heals of its pods are deferred. 0 disables the budget.")
+	rootCmd.PersistentFlags().DurationVar(&ownerBudgetWindow, "owner-budget-window", healer.DefaultOwnerHealBudgetWindow,
+		"Window the --owner-heal-budget is counted over.")
+	rootCmd.PersistentFlags().IntVar(&breakerThreshold, "breaker-threshold", 0,
+		"Trip a circuit breaker when more heals than this happen within --breaker-interval: healing halts cluster-wide, with an alert, until --breaker-cooloff passes or it is reset. 0 disables it.")
+	rootCmd.PersistentFlags().DurationVar(&breakerInterval, "breaker-interval", healer.DefaultBreakerInterval,
+		"Interval heals are counted over for --breaker-threshold.")
+	rootCmd.PersistentFlags().DurationVar(&breakerCooloff, "breaker-cooloff", healer.DefaultBreakerCooloff,
+		"How long a tripped circuit breaker halts healing before resuming on its own.")
+	rootCmd.PersistentFlags().IntVar(&flapThreshold, "flap-threshold", 0,
+		"Quarantine a workload healed this many times within --flap-window without recovering: it is labeled k8s-healer.io/quarantined=true and no longer healed until the label is removed. 0 disables it.")
+	rootCmd.PersistentFlags().DurationVar(&flapWindow, "flap-window", healer.DefaultFlapWindow,
+		"Window heals are counted over for --flap-threshold.")
+	rootCmd.PersistentFlags().IntVar(&chronicThreshold, "chronic-threshold", 0,
+		"Switch a workload healed this many times within --chronic-window to notify-only and report it as a chronic failure in /status. 0 disables it.")
+	rootCmd.PersistentFlags().DurationVar(&chronicWindow, "chronic-window", healer.DefaultChronicWindow,
+		"Horizon heals are counted over for --chronic-threshold.")
+	rootCmd.PersistentFlags().DurationVar(&minPodAge, "min-pod-age", 0,
+		"Never heal Pods younger than this (e.g. 5m), to avoid fighting a rollout that is still converging.")
+	rootCmd.PersistentFlags().DurationVar(&minUnhealthy, "min-unhealthy-duration", 0,
+		"How long a Pod must stay unhealthy continuously before it is healed (e.g. 5m). 0 heals immediately.")
+	rootCmd.PersistentFlags().Int32Var(&restartThreshold, "restart-threshold", util.DefaultRestartThreshold,
+		"Container restarts before a CrashLoopBackOff or failing startup probe is healed.")
+	rootCmd.PersistentFlags().StringArrayVar(&celConditions, "unhealthy-condition", nil,
+		"Extra unhealthiness condition as a CEL expression over the Pod (repeatable), e.g. 'status.containerStatuses.exists(c, c.restartCount > 10)'.")
+	rootCmd.PersistentFlags().StringVarP(&labelSelector, "selector", "l", "",
+		"Label selector (e.g. 'app=api,tier!=batch'); only matching pods are watched and healed.")
+	rootCmd.PersistentFlags().StringVar(&labelSelector, "label-selector", "", "Alias of --selector.")
+	_ = rootCmd.PersistentFlags().MarkDeprecated("label-selector", "use --selector (-l) instead")
+	rootCmd.PersistentFlags().StringVar(&fieldSelector, "field-selector", "",
+		"Server-side field selector for the Pod informers (e.g. 'status.phase!=Succeeded').")
+	rootCmd.PersistentFlags().StringSliceVar(&ownerKinds, "owner-kinds", nil,
+		"Only heal pods controlled by these kinds (Deployment, ReplicaSet, StatefulSet, DaemonSet, Job or ReplicationController; Deployment matches its ReplicaSets' pods). Defaults to any owner.")
+	rootCmd.PersistentFlags().StringSliceVar(&protectedNamespaces, "protected-namespaces", nil,
+		"Namespaces (globs or 're:' regexes) the healer never acts on, even when watched through wildcards.")
+	rootCmd.PersistentFlags().StringSliceVar(&protectedPods, "protected-pods", nil,
+		"Pods the healer never acts on, as name or namespace/name globs (e.g. 'etcd-*,prod/ledger-*').")
+	rootCmd.PersistentFlags().BoolVar(&protectSystemNamespaces, "protect-system-namespaces", true,
+		"Never act on kube-system, kube-public and kube-node-lease.")
+	rootCmd.PersistentFlags().BoolVar(&eventDetection, "event-detection", false,
+		"Also watch Warning events about Pods and treat repeated ones as an unhealthiness signal.")
+	rootCmd.PersistentFlags().StringSliceVar(&eventReasons, "event-reasons", healer.DefaultEventReasons,
+		"Event reasons considered by --event-detection.")
+	rootCmd.PersistentFlags().Int32Var(&eventThreshold, "event-threshold", 5,
+		"Number of occurrences of a Warning event before the Pod is considered unhealthy.")
+	rootCmd.PersistentFlags().DurationVar(&historyMaxAge, "history-max-age", history.DefaultRetention.MaxAge,
+		"Detailed heal records older than this are compacted into monthly rollups (0 disables).")
+	rootCmd.PersistentFlags().IntVar(&historyMaxRecords, "history-max-records", history.DefaultRetention.MaxRecords,
+		"Maximum number of detailed heal records kept before compaction (0 disables).")
+	rootCmd.PersistentFlags().IntVar(&historyRollupMonths, "history-rollup-months", history.DefaultRetention.RollupMonths,
+		"Number of months of rollups to keep (0 keeps them forever).")
+	rootCmd.PersistentFlags().DurationVar(&historyCompactInterval, "history-compact-interval", time.Hour,
+		"How often heal history is compacted.")
+	rootCmd.PersistentFlags().DurationVar(&effectivenessWindow, "effectiveness-window", healer.DefaultEffectivenessWindow,
+		"How long a healed workload must stay healthy for the heal to count as effective.")
+	rootCmd.PersistentFlags().DurationVar(&recoveryTimeout, "recovery-timeout", healer.DefaultRecoveryTimeout,
+		"How long the replacement of a deleted or evicted pod has to become Ready before the heal counts as unrecovered, is notified and backs the cooldown off further. 0 disables it.")
+	rootCmd.PersistentFlags().BoolVar(&healRecords, "heal-records", false,
+		"Create a HealRecord custom resource for every heal in the healed pod's namespace (requires the HealRecord CRD).")
+	rootCmd.PersistentFlags().DurationVar(&healRecordTTL, "heal-record-ttl", healer.DefaultHealRecordTTL,
+		"How long HealRecords are kept before they are deleted (0 keeps them forever).")
+	rootCmd.PersistentFlags().StringVar(&eventStreamPath, "event-stream", "",
+		"Write every heal decision as a line of JSON to this file or named pipe, or to stdout if \"-\".")
+	rootCmd.PersistentFlags().StringVar(&auditLogPath, "audit-log", "",
+		"Append a record of every destructive action to this file, e.g. /var/log/k8s-healer/audit.log.")
+	rootCmd.PersistentFlags().IntVar(&auditLogMaxSizeMB, "audit-log-max-size", int(audit.DefaultRotation.MaxSize>>20),
+		"Size in MiB at which the audit log is rotated (0 disables).")
+	rootCmd.PersistentFlags().DurationVar(&auditLogMaxAge, "audit-log-max-age", audit.DefaultRotation.MaxAge,
+		"Age at which the audit log is rotated (0 disables).")
+	rootCmd.PersistentFlags().IntVar(&auditLogMaxBackups, "audit-log-max-backups", audit.DefaultRotation.MaxBackups,
+		"Number of rotated audit logs kept (0 keeps all).")
+	rootCmd.PersistentFlags().StringVar(&clusterName, "cluster-name", "",
+		"Name of this cluster, used in notifications and notification routing rules.")
+	rootCmd.PersistentFlags().StringVar(&notifyRoutesPath, "notify-routes", "",
+		"Path to a YAML file mapping clusters/namespaces/labels/severities to notification sinks. Broadcasts to all sinks if empty.")
+	rootCmd.PersistentFlags().BoolVar(&apiHealthThrottle, "api-health-throttle", true,
+		"Slow down healing while the API server is degraded and pause it while it is unavailable.")
+	rootCmd.PersistentFlags().DurationVar(&apiLatencyThreshold, "api-latency-threshold", 2*time.Second,
+		"p90 API request latency above which the API server is considered degraded.")
+	rootCmd.PersistentFlags().Float64Var(&apiErrorRateThreshold, "api-error-rate-threshold", 0.2,
+		"Fraction of API requests failing with 429/5xx above which the API server is considered degraded.")
+	rootCmd.PersistentFlags().DurationVar(&degradedHealInterval, "degraded-heal-interval", time.Minute,
+		"Minimum time between heals while the API server is degraded.")
+	rootCmd.PersistentFlags().DurationVar(&completedPodTTL, "gc-completed-pods-ttl", 0,
+		"Delete Succeeded/Failed pods that completed longer ago than this (e.g. 24h). 0 disables garbage collection.")
+	rootCmd.PersistentFlags().StringToStringVar(&completedPodTTLOverrides, "gc-namespace-ttl", nil,
+		"Per-namespace garbage collection TTLs as namespace=ttl pairs; namespaces may be globs, the most specific match wins (e.g. 'ci-*=1h,prod=0').")
+	rootCmd.PersistentFlags().BoolVar(&cleanupDisruptedPods, "cleanup-disrupted-pods", true,
+		"Delete managed Pods left in Failed state by preemption or node shutdown (recorded separately from heals).")
+	rootCmd.PersistentFlags().StringVar(&mode, "mode", healer.ModeOptOut,
+		"opt-out heals every pod not annotated k8s-healer.io/enabled=false; opt-in only heals pods whose pod, workload or namespace is annotated k8s-healer.io/enabled=true.")
+	rootCmd.PersistentFlags().StringVar(&pausedDeploymentBehavior, "paused-deployments", healer.PausedNotify,
+		"What to do with unhealthy Pods of paused Deployments: notify (report only), skip, or heal.")
+	rootCmd.PersistentFlags().StringVar(&healAction, "heal-action", healer.ActionDelete,
+		"Default healing action: delete (the Pod), rollout-restart or scale-cycle (the owning Deployment/StatefulSet), rollback (a broken Deployment release), job (run a remediation Job), memory-bump (raise the limit of OOM-killed containers), webhook (hand off to --remediation-webhook-url), notify or skip.")
+	rootCmd.PersistentFlags().Int64Var(&healGracePeriod, "heal-grace-period", -1,
+		"Grace period in seconds for deleting healed Pods; -1 uses the Pod's own, 0 force-deletes (guarded for data-sensitive Pods).")
+	rootCmd.PersistentFlags().StringVar(&healPropagation, "heal-propagation", "",
+		"Propagation policy for deleting healed Pods: background, foreground or orphan. Default: the API server's.")
+	rootCmd.PersistentFlags().BoolVar(&allowFinalizerRemoval, "allow-finalizer-removal", false,
+		"DANGEROUS: remove the finalizers of Pods stuck Terminating and force-delete them. Skips the cleanup the finalizers guard.")
+	rootCmd.PersistentFlags().DurationVar(&stuckTerminatingAfter, "stuck-terminating-after", healer.DefaultStuckTerminatingAfter,
+		"How long past its grace period a Pod must still be Terminating to count as wedged.")
+	rootCmd.PersistentFlags().StringVar(&remediationJobTemplate, "remediation-job-template", "",
+		"Job manifest (YAML) created by the job action, with the failing pod passed as HEALER_* env vars.")
+	rootCmd.PersistentFlags().BoolVar(&remediationJobDelete, "remediation-job-then-delete", false,
+		"Delete the pod after creating the remediation job instead of leaving it to the job.")
+	rootCmd.PersistentFlags().BoolVar(&startupReport, "startup-report", true,
+		"Log every currently unhealthy pod and the intended action once the caches synced.")
+	rootCmd.PersistentFlags().BoolVar(&startupReportNotify, "startup-report-notify", false,
+		"Also send the startup reconciliation report as a notification.")
+	rootCmd.PersistentFlags().StringVar(&preDeleteCommand, "pre-delete-command", "",
+		"Command exec'ed in a running container of a pod before it is deleted (e.g. 'curl -s localhost:8080/drain').")
+	rootCmd.PersistentFlags().StringVar(&preDeleteContainer, "pre-delete-container", "",
+		"Container to run the pre-delete command in. Default: the first running container.")
+	rootCmd.PersistentFlags().DurationVar(&preDeleteTimeout, "pre-delete-timeout", 30*time.Second,
+		"Timeout for the pre-delete command; the pod is deleted afterwards regardless of its outcome.")
+	rootCmd.PersistentFlags().StringSliceVar(&approvalNamespaces, "require-approval", nil,
+		"Namespaces (or globs) whose heals wait for approval via the k8s-healer.io/approved-by pod annotation.")
+	rootCmd.PersistentFlags().IntVar(&queueSize, "queue-size", healer.DefaultQueueSize,
+		"Maximum number of pod updates waiting to be checked; further updates are dropped until the next resync.")
+	rootCmd.PersistentFlags().IntVar(&queueWorkers, "queue-workers", healer.DefaultQueueWorkers,
+		"Number of workers checking queued pod updates.")
+	rootCmd.PersistentFlags().IntVar(&healConcurrency, "heal-concurrency", healer.DefaultHealConcurrency,
+		"Maximum number of heals carried out at once; heals of different workloads run in parallel, each workload is healed by one worker at a time.")
+	rootCmd.PersistentFlags().IntVar(&healRetries, "heal-retries", healer.DefaultHealRetries,
+		"How often a heal that failed on a transient API error (throttling, timeouts, an unavailable API server) is retried with exponential backoff before it counts as failed. 0 disables retries.")
+	rootCmd.PersistentFlags().DurationVar(&resyncPeriod, "resync-period", healer.DefaultResyncPeriod,
+		"Resync period of the pod, owner and event informers.")
+	rootCmd.PersistentFlags().DurationVar(&reconcileInterval, "reconcile-interval", healer.DefaultReconcileInterval,
+		"How often every cached pod is re-checked, independently of informer resyncs. 0 disables it.")
+	rootCmd.PersistentFlags().StringVar(&remediationWebhookURL, "remediation-webhook-url", "",
+		"Endpoint the webhook action POSTs heal decisions to; the pod is deleted only if it answers 2xx.")
+	rootCmd.PersistentFlags().DurationVar(&remediationWebhookTimeout, "remediation-webhook-timeout", 10*time.Second,
+		"Timeout for remediation webhook calls.")
+	rootCmd.PersistentFlags().StringVar(&replacementPrefix, "replacement-annotation-prefix", annotations.Prefix,
+		"Prefix of the healed-from and heal-reason annotations set on the pods replacing healed ones (e.g. 'healer.io/'). Empty disables them.")
+	rootCmd.PersistentFlags().BoolVar(&useEviction, "use-eviction", false,
+		"Remove Pods through the Eviction API instead of deleting them, honoring PodDisruptionBudgets.")
+	rootCmd.PersistentFlags().BoolVar(&respectPDBs, "respect-pdbs", true,
+		"Defer plain deletes of Ready pods whose PodDisruptionBudget allows no more disruptions.")
+	rootCmd.PersistentFlags().BoolVar(&checkResourceQuota, "check-resource-quota", true,
+		"Only notify instead of deleting a pod while the namespace's ResourceQuota has no room for its replacement.")
+	rootCmd.PersistentFlags().StringToStringVar(&exitCodeActions, "exit-code-action", nil,
+		"Action per last container exit code as code=action pairs, same actions as --heal-action (e.g. '1=notify,137=delete').")
+	rootCmd.PersistentFlags().StringToStringVar(&checkActions, "check-action", nil,
+		"Action per failing check as check=action pairs, checks: crashloop, startup-probe, events, custom (e.g. 'crashloop=scale-cycle').")
+	rootCmd.PersistentFlags().DurationVar(&scaleCyclePause, "scale-cycle-pause", healer.DefaultScaleCyclePause,
+		"How long a workload stays at zero replicas during the scale-cycle action.")
+	rootCmd.PersistentFlags().IntVar(&memoryBumpPercent, "memory-bump-percent", healer.DefaultMemoryBumpPercent,
+		"Percentage the memory-bump action raises an OOM-killed container's memory limit by.")
+	rootCmd.PersistentFlags().StringVar(&memoryBumpMax, "memory-bump-max", healer.DefaultMemoryBumpMax.String(),
+		"Cap on the memory limits set by the memory-bump action (e.g. 8Gi).")
+	rootCmd.PersistentFlags().StringVar(&containerRestartCommand, "container-restart-command", strings.Join(healer.DefaultContainerRestartCommand, " "),
+		"Command exec'ed in the failing container of Pods annotated k8s-healer.io/container-restart=true to restart only that container.")
+	rootCmd.PersistentFlags().StringVar(&decisionWebhookURL, "decision-webhook-url", "",
+		"URL consulted (POST) before every heal; it can veto the heal, change its action or extend the cooldown.")
+	rootCmd.PersistentFlags().DurationVar(&decisionWebhookTimeout, "decision-webhook-timeout", 5*time.Second,
+		"Timeout for decision webhook calls.")
+	rootCmd.PersistentFlags().BoolVar(&decisionWebhookFailOpen, "decision-webhook-fail-open", false,
+		"Proceed with heals when the decision webhook fails or times out (default: block them).")
+	rootCmd.PersistentFlags().StringVar(&controlNamespace, "control-namespace", defaultControlNamespace(),
+		"Namespace of the control ConfigMap holding blackouts and the control audit trail.")
+	rootCmd.PersistentFlags().StringVar(&controlConfigMap, "control-configmap", control.DefaultConfigMapName,
+		"Name of the control ConfigMap.")
+	rootCmd.PersistentFlags().StringVar(&stateConfigMap, "state-configmap", healer.DefaultStateConfigMapName,
+		"ConfigMap in --control-namespace the cooldowns and backoff state are checkpointed to and restored from on restart. Empty disables it.")
+	rootCmd.PersistentFlags().DurationVar(&checkpointInterval, "checkpoint-interval", healer.DefaultCheckpointInterval,
+		"How often the cooldowns are checkpointed to --state-configmap (and the state synced with --state-dir or --state-redis-url).")
+	rootCmd.PersistentFlags().StringVar(&stateDir, "state-dir", "",
+		"Directory of an embedded database the heal history, cooldowns and quarantines are saved to and restored from on restart; read by 'k8s-healer history'. Disabled if empty.")
+	rootCmd.PersistentFlags().StringVar(&stateRedisURL, "state-redis-url", "",
+		"Redis URL (redis://[user:password@]host:port/db) the heal history, cooldowns and quarantines are shared through by all healers using it, instead of --state-dir.")
+	rootCmd.PersistentFlags().StringVar(&stateRedisPrefix, "state-redis-prefix", state.DefaultRedisPrefix,
+		"Prefix of the --state-redis-url keys; healers sharing a prefix share their state.")
+	rootCmd.PersistentFlags().StringVar(&freezeCalendarURL, "freeze-calendar-url", "",
+		"iCalendar feed or JSON endpoint with change-freeze windows; healing in the namespaces they cover is notify-only while they last.")
+	rootCmd.PersistentFlags().DurationVar(&freezeCalendarRefresh, "freeze-calendar-refresh", healer.DefaultFreezeCalendarRefresh,
+		"How often the freeze calendar is reloaded.")
+	rootCmd.PersistentFlags().StringArrayVar(&noHealWindows, "no-heal-window", nil,
+		"Recurring maintenance window as '[TZ=<zone>] <cron>' (repeatable), e.g. '0 22 * * 5-6'; unhealthy pods are only reported while it is open.")
+	rootCmd.PersistentFlags().DurationVar(&noHealWindowDuration, "no-heal-window-duration", healer.DefaultNoHealWindowDuration,
+		"How long each --no-heal-window stays open after its schedule fires.")
+	rootCmd.PersistentFlags().StringVar(&timezone, "timezone", "UTC",
+		"IANA time zone schedule-based features (e.g. maintenance windows) are evaluated in (e.g. 'Europe/Berlin').")
+	rootCmd.PersistentFlags().StringToStringVar(&namespaceTimezones, "namespace-timezone", nil,
+		"Per-namespace time zone overrides as namespace=zone pairs; namespaces may be globs, the most specific match wins (e.g. 'apac-*=Asia/Tokyo').")
+	rootCmd.Flags().StringVar(&statusAddr, "status-addr", "",
+		"Address to serve the status and control API on (e.g. ':8080'), used by 'k8s-healer fleet'. Disabled if empty.")
+	rootCmd.Flags().StringVar(&metricsAddr, "metrics-addr", "",
+		"Address to serve Prometheus metrics on at /metrics (e.g. ':9090'). Disabled if empty.")
+	rootCmd.Flags().StringVar(&debugAddr, "debug-addr", "",
+		"Address to serve pprof profiles on at /debug/pprof/ (e.g. 'localhost:6060'). Disabled if empty.")
+	rootCmd.PersistentFlags().StringVar(&controlActor, "actor", "",
+		"Actor recorded in the audit trail for control commands (defaults to the current user).")
 }
 
-// resolveWildcardNamespaces connects to the cluster, lists all namespaces, and returns a concrete list
-// based on the input patterns, handling wildcards using filepath.Match.
-func resolveWildcardNamespaces(kubeconfigPath, namespacesInput string) ([]string, error) {
-	if namespacesInput == "" {
-		return []string{}, nil // Return empty list, signaling the healer to watch all.
+// defaultControlNamespace returns the namespace the healer runs in (POD_NAMESPACE, set via the
+// downward API), falling back to "default".
+func defaultControlNamespace() string {
+	if ns := os.Getenv("POD_NAMESPACE"); ns != "" {
+		return ns
 	}
+	return "default"
+}
 
-	patterns := strings.Split(namespacesInput, ",")
-	for i, p := range patterns {
-		patterns[i] = strings.TrimSpace(p)
+// buildClientset creates a clientset from the kubeconfig path, or the default locations if empty.
+func buildClientset(kubeconfigPath string) (*kubernetes.Clientset, error) {
+	config, err := clientcmd.BuildConfigFromFlags("", kubeconfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build Kubernetes config: %w", err)
 	}
+	return kubernetes.NewForConfig(config)
+}
 
-	// Check if any pattern contains a wildcard. If not, just return the list of patterns.
-	needsResolution := false
+// parseNamespacePatterns splits and validates the -n input. Literal names are watched as given;
+// globs and regexes are handed to the healer, which matches them against the namespaces of the
+// cluster as they come and go.
+func parseNamespacePatterns(namespacesInput string) ([]string, error) {
+	patterns := splitPatterns(namespacesInput)
 	for _, p := range patterns {
-		if strings.Contains(p, "*") {
-			needsResolution = true
-			break
+		if err := util.ValidateNamespacePattern(p); err != nil {
+			return nil, err
 		}
 	}
-	if !needsResolution {
-		return patterns, nil
+	return patterns, nil
+}
+
+// splitPatterns splits a comma-separated list of namespace patterns.
+func splitPatterns(input string) []string {
+	var patterns []string
+	for _, p := range strings.Split(input, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			patterns = append(patterns, p)
+		}
 	}
+	return patterns
+}
 
-	// --- Connect to Kubernetes to list existing namespaces ---
+// withoutNamespaces returns the namespaces not matching any of the exclusion patterns.
+func withoutNamespaces(namespaces, excluded []string) []string {
+	var kept []string
+	for _, ns := range namespaces {
+		skip := false
+		for _, pattern := range excluded {
+			if util.MatchNamespace(pattern, ns) {
+				skip = true
+				break
+			}
+		}
+		if skip {
+			slog.Info("Excluding namespace (-N)", "namespace", ns)
+			continue
+		}
+		kept = append(kept, ns)
+	}
+	return kept
+}
 
-	var config *rest.Config
-	var err error
+// parseDurationMap converts key=duration flag values into a map of durations.
+func parseDurationMap(in map[string]string) (map[string]time.Duration, error) {
+	out := make(map[string]time.Duration, len(in))
+	for k, v := range in {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid duration %q for %s: %w", v, k, err)
+		}
+		out[k] = d
+	}
+	return out, nil
+}
 
-	if kubeconfigPath != "" {
-		config, err = clientcmd.BuildConfigFromFlags("", kubeconfigPath)
-	} else {
-		config, err = clientcmd.BuildConfigFromFlags("", "")
+// buildNotifier creates the notification router over all configured sinks.
+func buildNotifier() (*notify.Router, error) {
+	var routing *notify.RoutingConfig
+	if notifyRoutesPath != "" {
+		var err error
+		if routing, err = notify.LoadRoutingConfig(notifyRoutesPath); err != nil {
+			return nil, err
+		}
+	} else if fileConfig != nil {
+		routing = fileConfig.Notifications
 	}
 
+	sinks, err := notify.BuildSinks(routing)
 	if err != nil {
-		return nil, fmt.Errorf("failed to build Kubernetes config for resolution: %w", err)
+		return nil, err
+	}
+	return notify.NewRouter(routing, sinks...)
+}
+
+// validateFlags checks enumerated flag values before anything connects to the cluster.
+func validateFlags() error {
+	switch pausedDeploymentBehavior {
+	case healer.PausedNotify, healer.PausedSkip, healer.PausedHeal:
+	default:
+		return fmt.Errorf("invalid --paused-deployments %q (expected notify, skip or heal)", pausedDeploymentBehavior)
+	}
+	if mode != healer.ModeOptOut && mode != healer.ModeOptIn {
+		return fmt.Errorf("invalid --mode %q (expected opt-out or opt-in)", mode)
+	}
+	switch watchStrategy {
+	case healer.WatchAuto, healer.WatchPerNamespace, healer.WatchCluster:
+	default:
+		return fmt.Errorf("invalid --watch-strategy %q (expected auto, per-namespace or cluster)", watchStrategy)
+	}
+	if clusterThreshold < 0 {
+		return fmt.Errorf("--cluster-threshold must not be negative")
+	}
+	if checkpointInterval <= 0 {
+		return fmt.Errorf("--checkpoint-interval must be positive")
+	}
+	if maxHealCooldown <= 0 || cooldownReset < 0 {
+		return fmt.Errorf("--max-heal-cooldown must be positive and --cooldown-reset must not be negative")
+	}
+	if healCacheSize <= 0 {
+		return fmt.Errorf("--heal-cache-size must be positive")
+	}
+	if maxHealsPerMinute < 0 {
+		return fmt.Errorf("--max-heals-per-minute must not be negative")
+	}
+	if ownerHealBudget < 0 || ownerHealBudget > 1 {
+		return fmt.Errorf("--owner-heal-budget must be between 0 and 1")
+	}
+	if ownerBudgetWindow <= 0 {
+		return fmt.Errorf("--owner-budget-window must be positive")
+	}
+	if breakerThreshold < 0 || breakerInterval <= 0 || breakerCooloff <= 0 {
+		return fmt.Errorf("--breaker-threshold must not be negative, --breaker-interval and --breaker-cooloff must be positive")
+	}
+	if flapThreshold < 0 || flapWindow <= 0 {
+		return fmt.Errorf("--flap-threshold must not be negative and --flap-window must be positive")
+	}
+	if chronicThreshold < 0 || chronicWindow <= 0 {
+		return fmt.Errorf("--chronic-threshold must not be negative and --chronic-window must be positive")
+	}
+	if restartThreshold < 1 {
+		return fmt.Errorf("--restart-threshold must be at least 1")
+	}
+	if !healer.IsValidAction(healAction) {
+		return fmt.Errorf("invalid --heal-action %q (expected delete, rollout-restart, rollback, scale-cycle, job, memory-bump, webhook, notify or skip)", healAction)
+	}
+	if remediationWebhookURL == "" && actionConfigured(healer.ActionWebhook) {
+		return fmt.Errorf("the webhook action requires --remediation-webhook-url")
+	}
+	if memoryBumpPercent < 1 {
+		return fmt.Errorf("--memory-bump-percent must be at least 1")
+	}
+	if _, err := resource.ParseQuantity(memoryBumpMax); err != nil {
+		return fmt.Errorf("invalid --memory-bump-max %q: %w", memoryBumpMax, err)
+	}
+	if queueSize < 1 || queueWorkers < 1 {
+		return fmt.Errorf("--queue-size and --queue-workers must be at least 1")
+	}
+	if healConcurrency < 1 {
+		return fmt.Errorf("--heal-concurrency must be at least 1")
+	}
+	if healRetries < 0 {
+		return fmt.Errorf("--heal-retries must not be negative")
+	}
+	if recoveryTimeout < 0 {
+		return fmt.Errorf("--recovery-timeout must not be negative")
+	}
+	if healRecordTTL < 0 {
+		return fmt.Errorf("--heal-record-ttl must not be negative")
+	}
+	if freezeCalendarRefresh <= 0 {
+		return fmt.Errorf("--freeze-calendar-refresh must be positive")
+	}
+	if historyCompactInterval <= 0 {
+		return fmt.Errorf("--history-compact-interval must be positive")
+	}
+	if auditLogMaxSizeMB < 0 || auditLogMaxAge < 0 || auditLogMaxBackups < 0 {
+		return fmt.Errorf("--audit-log-max-size, --audit-log-max-age and --audit-log-max-backups must not be negative")
+	}
+	if kubeAPIQPS <= 0 || kubeAPIBurst < 1 {
+		return fmt.Errorf("--kube-api-qps and --kube-api-burst must be positive")
+	}
+	if resyncPeriod < 0 || reconcileInterval < 0 {
+		return fmt.Errorf("--resync-period and --reconcile-interval must not be negative")
+	}
+	if remediationJobTemplate == "" && actionConfigured(healer.ActionJob) {
+		return fmt.Errorf("the job action requires --remediation-job-template")
+	}
+	if err := healer.ValidateOwnerKinds(ownerKinds); err != nil {
+		return fmt.Errorf("invalid --owner-kinds: %w", err)
+	}
+	for _, pattern := range protectedNamespaces {
+		if err := util.ValidateNamespacePattern(pattern); err != nil {
+			return fmt.Errorf("invalid --protected-namespaces: %w", err)
+		}
+	}
+	for _, pattern := range protectedPods {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid --protected-pods glob %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// actionConfigured reports whether the action is selected by any of the action policy flags or
+// the configuration file.
+func actionConfigured(action string) bool {
+	if healAction == action {
+		return true
+	}
+	if fileConfig != nil {
+		if fileConfig.Action == action {
+			return true
+		}
+		for _, a := range fileConfig.ExitCodeActions {
+			if a == action {
+				return true
+			}
+		}
+		for _, a := range fileConfig.CheckActions {
+			if a == action {
+				return true
+			}
+		}
+		for _, o := range fileConfig.Overrides {
+			if o.Action == action {
+				return true
+			}
+		}
+	}
+	for _, a := range exitCodeActions {
+		if strings.TrimSpace(a) == action {
+			return true
+		}
+	}
+	for _, a := range checkActions {
+		if strings.TrimSpace(a) == action {
+			return true
+		}
+	}
+	return false
+}
+
+// startHealer parses the flags and the configuration file, initializes the healer, and manages the
+// shutdown signals.
+func startHealer(cmd *cobra.Command) {
+	if err := loadConfigFile(cmd); err != nil {
+		fatal("Failed to load --config", "err", err)
+	}
+	if err := validateFlags(); err != nil {
+		fatal("Invalid flags", "err", err)
+	}
+	if fileConfig != nil && len(fileConfig.Namespaces) > 0 {
+		namespaces = strings.Join(fileConfig.Namespaces, ",")
 	}
 
-	clientset, err := kubernetes.NewForConfig(config)
+	if fileConfig != nil && len(fileConfig.ExcludeNamespaces) > 0 {
+		excludeNamespaces = strings.Join(fileConfig.ExcludeNamespaces, ",")
+	}
+	excluded := splitPatterns(excludeNamespaces)
+	for _, pattern := range excluded {
+		if err := util.ValidateNamespacePattern(pattern); err != nil {
+			fatal("Invalid -N", "err", err)
+		}
+	}
+
+	// Wildcards and regexes are resolved live by the healer, so namespaces created later are watched too
+	nsList, err := parseNamespacePatterns(namespaces)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create Kubernetes clientset for resolution: %w", err)
+		fatal("Invalid namespaces", "err", err)
+	}
+	if len(nsList) > 0 && len(excluded) > 0 {
+		nsList = withoutNamespaces(nsList, excluded)
+		if len(nsList) == 0 {
+			fatal("Every namespace selected by -n is excluded by -N")
+		}
 	}
 
-	// List all namespaces in the cluster
-	nsList, err := clientset.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
+	// Initialize the Healer module. This connects to Kubernetes.
+	if fileConfig != nil && fileConfig.KubeAPIQPS != nil {
+		kubeAPIQPS = *fileConfig.KubeAPIQPS
+	}
+	if fileConfig != nil && fileConfig.KubeAPIBurst != nil {
+		kubeAPIBurst = *fileConfig.KubeAPIBurst
+	}
+	h, err := healer.NewHealer(kubeconfigPath, nsList, healer.ClientOptions{QPS: kubeAPIQPS, Burst: kubeAPIBurst})
 	if err != nil {
-		return nil, fmt.Errorf("failed to list namespaces for wildcard resolution: %w", err)
+		fatal("Failed to set up the Kubernetes client", "err", err)
 	}
+	h.Log = slog.Default()
 
-	resolvedNamespaces := make(map[string]bool)
+	h.HealPolicies = operatorMode
+	h.HealCooldown = healCooldown
+	h.MaxHealCooldown = maxHealCooldown
+	h.CooldownReset = cooldownReset
+	h.HealCacheSize = healCacheSize
+	h.MaxHealsPerMinute = maxHealsPerMinute
+	h.OwnerHealBudget = ownerHealBudget
+	h.OwnerHealBudgetWindow = ownerBudgetWindow
+	h.BreakerThreshold = breakerThreshold
+	h.BreakerInterval = breakerInterval
+	h.BreakerCooloff = breakerCooloff
+	h.FlapThreshold = flapThreshold
+	h.FlapWindow = flapWindow
+	h.ChronicThreshold = chronicThreshold
+	h.ChronicWindow = chronicWindow
+	h.MinPodAge = minPodAge
+	h.MinUnhealthyDuration = minUnhealthy
+	h.RestartThreshold = restartThreshold
 
-	// Match existing namespaces against all patterns
-	for _, ns := range nsList.Items {
-		for _, pattern := range patterns {
-			match, err := filepath.Match(pattern, ns.Name)
-			if err != nil {
-				// This shouldn't happen with simple glob patterns
-				fmt.Printf("Warning: Invalid wildcard pattern '%s': %v\n", pattern, err)
-				continue
-			}
-			if match {
-				resolvedNamespaces[ns.Name] = true
-			}
+	// Validate the informer selectors locally; the API server would otherwise reject every list call.
+	if _, err := labels.Parse(labelSelector); err != nil {
+		fatal("Invalid --selector", "err", err)
+	}
+	if _, err := labels.Parse(namespaceSelector); err != nil {
+		fatal("Invalid --namespace-selector", "err", err)
+	}
+	if _, err := fields.ParseSelector(fieldSelector); err != nil {
+		fatal("Invalid --field-selector", "err", err)
+	}
+	h.PodLabelSelector = labelSelector
+	h.PodFieldSelector = fieldSelector
+	h.OwnerKinds = ownerKinds
+	h.ExcludedNamespaces = excluded
+	h.NamespaceSelector = namespaceSelector
+	h.WatchAnnotatedNamespaces = watchAnnotated
+	h.WatchStrategy = watchStrategy
+	h.ClusterWatchThreshold = clusterThreshold
+	h.ProtectedNamespaces = protectedNamespaces
+	h.ProtectedPods = protectedPods
+	h.ProtectSystemNamespaces = protectSystemNamespaces
+
+	h.EventDetection = eventDetection
+	h.EventReasons = eventReasons
+	h.EventThreshold = eventThreshold
+
+	h.History = history.NewStore(history.Retention{
+		MaxAge:       historyMaxAge,
+		MaxRecords:   historyMaxRecords,
+		RollupMonths: historyRollupMonths,
+	})
+	h.HistoryCompactInterval = historyCompactInterval
+	switch {
+	case stateDir != "" && stateRedisURL != "":
+		fatal("--state-dir and --state-redis-url are mutually exclusive")
+	case stateDir != "":
+		h.State, err = state.Open(stateDir)
+		if err != nil {
+			fatal("Failed to open --state-dir", "err", err)
+		}
+	case stateRedisURL != "":
+		h.State, err = state.OpenRedis(stateRedisURL, stateRedisPrefix)
+		if err != nil {
+			fatal("Failed to open --state-redis-url", "err", err)
+		}
+	}
+	h.EffectivenessWindow = effectivenessWindow
+	h.RecoveryTimeout = recoveryTimeout
+	h.HealRecords = healRecords
+	h.HealRecordTTL = healRecordTTL
+	switch eventStreamPath {
+	case "":
+	case "-":
+		h.EventStream = healer.NewEventStream(os.Stdout)
+	default:
+		// Opening a named pipe blocks until its reader is there
+		f, err := os.OpenFile(eventStreamPath, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+		if err != nil {
+			fatal("Failed to open --event-stream", "err", err)
+		}
+		h.EventStream = healer.NewEventStream(f)
+	}
+	if auditLogPath != "" {
+		h.Audit, err = audit.Open(auditLogPath, audit.Rotation{
+			MaxSize:    int64(auditLogMaxSizeMB) << 20,
+			MaxAge:     auditLogMaxAge,
+			MaxBackups: auditLogMaxBackups,
+		})
+		if err != nil {
+			fatal("Failed to open --audit-log", "err", err)
 		}
 	}
 
-	// Convert map keys to slice
-	var finalNsList []string
-	for ns := range resolvedNamespaces {
-		finalNsList = append(finalNsList, ns)
+	h.APIHealthThrottle = apiHealthThrottle
+	h.APILatencyThreshold = apiLatencyThreshold
+	h.APIErrorRateThreshold = apiErrorRateThreshold
+	h.DegradedHealInterval = degradedHealInterval
+
+	h.CompletedPodTTL = completedPodTTL
+	h.CompletedPodTTLOverrides, err = parseDurationMap(completedPodTTLOverrides)
+	if err != nil {
+		fatal("Invalid --gc-namespace-ttl", "err", err)
 	}
 
-	if len(finalNsList) > 0 {
-		fmt.Printf("Wildcards resolved. Watching %d namespaces: [%s]\n", len(finalNsList), strings.Join(finalNsList, ", "))
-	} else {
-		fmt.Println("Warning: Wildcard patterns did not match any existing namespaces.")
+	h.CleanupDisruptedPods = cleanupDisruptedPods
+	h.PausedDeploymentBehavior = pausedDeploymentBehavior
+	h.Mode = mode
+	h.Timezones, err = schedule.ParseZones(timezone, namespaceTimezones)
+	if err != nil {
+		fatal("Invalid time zones", "err", err)
 	}
 
-	return finalNsList, nil
-}
+	h.DefaultAction = healAction
+	h.UseEviction = useEviction
+	h.RespectPDBs = respectPDBs
+	h.CheckResourceQuota = checkResourceQuota
+	h.ReplacementAnnotationPrefix = replacementPrefix
+	h.FreezeCalendarURL = freezeCalendarURL
+	for _, spec := range noHealWindows {
+		w, err := schedule.ParseWindow(spec, noHealWindowDuration)
+		if err != nil {
+			fatal("Invalid --no-heal-window", "err", err)
+		}
+		h.NoHealWindows = append(h.NoHealWindows, w)
+	}
+	h.FreezeCalendarRefresh = freezeCalendarRefresh
+	h.RemediationWebhookURL = remediationWebhookURL
+	h.RemediationWebhookTimeout = remediationWebhookTimeout
+	h.QueueSize = queueSize
+	h.QueueWorkers = queueWorkers
+	h.HealConcurrency = healConcurrency
+	h.HealRetries = healRetries
+	h.ResyncPeriod = resyncPeriod
+	h.ReconcileInterval = reconcileInterval
+	h.ApprovalNamespaces = approvalNamespaces
+	h.PreDeleteCommand = strings.Fields(preDeleteCommand)
+	h.PreDeleteContainer = preDeleteContainer
+	h.PreDeleteTimeout = preDeleteTimeout
+	h.StartupReport = startupReport
+	h.StartupReportNotify = startupReportNotify
+	if remediationJobTemplate != "" {
+		if h.RemediationJobTemplate, err = healer.LoadJobTemplate(remediationJobTemplate); err != nil {
+			fatal("Failed to load --remediation-job-template", "err", err)
+		}
+	}
+	h.RemediationJobThenDelete = remediationJobDelete
+	h.AllowFinalizerRemoval = allowFinalizerRemoval
+	h.StuckTerminatingAfter = stuckTerminatingAfter
+	if healGracePeriod >= 0 {
+		h.HealGracePeriodSeconds = &healGracePeriod
+	}
+	h.HealPropagationPolicy, err = healer.ParsePropagationPolicy(healPropagation)
+	if err != nil {
+		fatal("Invalid --heal-propagation", "err", err)
+	}
+	h.ExitCodeActions, err = healer.ParseExitCodeActions(exitCodeActions)
+	if err != nil {
+		fatal("Invalid --exit-code-action", "err", err)
+	}
+	h.CheckActions, err = healer.ParseCheckActions(checkActions)
+	if err != nil {
+		fatal("Invalid --check-action", "err", err)
+	}
+	h.ScaleCyclePause = scaleCyclePause
+	h.MemoryBumpPercent = memoryBumpPercent
+	h.MemoryBumpMax = resource.MustParse(memoryBumpMax)
+
+	h.ContainerRestartCommand = strings.Fields(containerRestartCommand)
+
+	h.DecisionWebhookURL = decisionWebhookURL
+	h.DecisionWebhookTimeout = decisionWebhookTimeout
+	h.DecisionWebhookFailOpen = decisionWebhookFailOpen
 
-// startHealer parses the flags, initializes the healer, and manages the shutdown signals.
-func startHealer() {
-	// Resolve the raw namespace input (including wildcards) into a concrete list of existing namespaces
-	nsList, err := resolveWildcardNamespaces(kubeconfigPath, namespaces)
+	h.Control = control.NewStore(h.ClientSet, controlNamespace, controlConfigMap)
+	h.StateNamespace = controlNamespace
+	h.StateConfigMap = stateConfigMap
+	h.CheckpointInterval = checkpointInterval
+
+	h.ClusterName = clusterName
+	h.Notifier, err = buildNotifier()
 	if err != nil {
-		fmt.Printf("Error resolving namespaces: %v\n", err)
-		os.Exit(1)
+		fatal("Failed to configure notifications", "err", err)
 	}
 
-	// Initialize the Healer module. This connects to Kubernetes.
-	healer, err := healer.NewHealer(kubeconfigPath, nsList)
+	// Compile the custom CEL conditions up front so typos fail fast instead of on the first Pod event.
+	h.CustomConditions, err = util.CompileCELConditions(celConditions)
 	if err != nil {
-		fmt.Printf("Error setting up Kubernetes client: %v\n", err)
-		os.Exit(1)
+		fatal("Invalid custom conditions", "err", err)
+	}
+
+	// Settings from the configuration file replace the flag defaults; the notification routes were
+	// already used to build the notifier above, over all configured sinks.
+	if fileConfig != nil {
+		cfg := *fileConfig
+		cfg.Notifications = nil
+		if err := cfg.Apply(h); err != nil {
+			fatal("Failed to apply --config", "err", err)
+		}
+	}
+
+	stopTracing, err := setupTracing(h.Identity())
+	if err != nil {
+		fatal("Failed to set up tracing", "err", err)
 	}
 
-	healer.HealCooldown = healCooldown
 	// Setup signal handling (SIGINT/Ctrl+C and SIGTERM) for graceful shutdown.
 	termCh := make(chan os.Signal, 1)
 	signal.Notify(termCh, syscall.SIGINT, syscall.SIGTERM)
 
 	// Start the main watch loop in a goroutine. This will start the informers.
-	go healer.Watch()
+	go h.Watch()
+
+	if statusAddr != "" {
+		go serveHTTP("status API", statusAddr, h.StatusHandler())
+	}
+	if metricsAddr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", h.MetricsHandler())
+		go serveHTTP("metrics", metricsAddr, mux)
+	}
+	if debugAddr != "" {
+		go serveHTTP("pprof", debugAddr, debugMux())
+	}
 
 	// Wait for termination signal
 	<-termCh
-	fmt.Println("\nTermination signal received. Shutting down healer...")
+	slog.Info("Termination signal received; shutting down healer")
 
 	// Close the StopCh channel to signal all concurrent informers to stop gracefully.
-	close(healer.StopCh)
+	close(h.StopCh)
 
 	// Give informers a moment to stop before exiting the process.
 	time.Sleep(1 * time.Second)
-	fmt.Println("Healer stopped.")
+	h.Notifier.Close()
+	stopTracing()
+	slog.Info("Healer stopped")
+}
+
+// serveHTTP serves a plain HTTP endpoint for the lifetime of the process.
+func serveHTTP(name, addr string, handler http.Handler) {
+	server := &http.Server{Addr: addr, Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+	slog.Info("Serving HTTP endpoint", "endpoint", name, "addr", addr)
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		slog.Error("Failed to serve HTTP endpoint", "endpoint", name, "addr", addr, "err", err)
+	}
+}
+
+// startWebhook serves the admission webhook until SIGINT/SIGTERM.
+func startWebhook() {
+	if tlsCertFile == "" || tlsKeyFile == "" {
+		fatal("--tls-cert-file and --tls-key-file are required (the API server only calls webhooks over TLS)")
+	}
+
+	server := &http.Server{
+		Addr:              webhookAddr,
+		Handler:           webhook.NewServer().Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	termCh := make(chan os.Signal, 1)
+	signal.Notify(termCh, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-termCh
+		slog.Info("Termination signal received; shutting down webhook")
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(ctx)
+	}()
+
+	slog.Info("Admission webhook listening", "addr", webhookAddr)
+	if err := server.ListenAndServeTLS(tlsCertFile, tlsKeyFile); err != nil && err != http.ErrServerClosed {
+		fatal("Webhook server failed", "err", err)
+	}
+	slog.Info("Webhook stopped")
 }
 
 func main() {
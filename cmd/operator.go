@@ -0,0 +1,40 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// operatorMode makes the healer reconcile HealPolicy custom resources.
+var operatorMode bool
+
+// operatorCmd runs the healer in operator mode.
+var operatorCmd = &cobra.Command{
+	Use:   "operator",
+	Short: "Run the healer in operator mode, governed by HealPolicy custom resources.",
+	Long: `Runs the healer like the root command and additionally reconciles HealPolicy custom resources
+(k8s-healer.io/v1alpha1) in all namespaces, so the teams owning a namespace can declare how its Pods
+are healed: selectors, failure reasons, thresholds, actions and cooldowns. A Pod is governed by the
+first policy of its namespace, in name order, whose selector matches it. Each policy's status
+reports whether it is in effect.
+
+Install the CRD first: kubectl apply -f deploy/crds/k8s-healer.io_healpolicies.yaml
+
+Usage Examples:
+  k8s-healer operator
+  k8s-healer operator -n 'team-*' --status-addr :8080
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		operatorMode = true
+		startHealer(cmd)
+	},
+}
+
+func init() {
+	operatorCmd.Flags().StringVar(&statusAddr, "status-addr", "",
+		"Address to serve the status and control API on (e.g. ':8080'), used by 'k8s-healer fleet'. Disabled if empty.")
+	operatorCmd.Flags().StringVar(&metricsAddr, "metrics-addr", "",
+		"Address to serve Prometheus metrics on at /metrics (e.g. ':9090'). Disabled if empty.")
+	operatorCmd.Flags().StringVar(&debugAddr, "debug-addr", "",
+		"Address to serve pprof profiles on at /debug/pprof/ (e.g. 'localhost:6060'). Disabled if empty.")
+	rootCmd.AddCommand(operatorCmd)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+var (
+	otlpEndpoint     string
+	traceSampleRatio float64
+)
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&otlpEndpoint, "otlp-endpoint", "",
+		"URL of an OTLP/HTTP collector receiving traces of heals, e.g. http://otel-collector:4318. Tracing is off if empty.")
+	rootCmd.PersistentFlags().Float64Var(&traceSampleRatio, "trace-sample-ratio", 1,
+		"Share of heals traced, between 0 and 1.")
+}
+
+// setupTracing exports the traces of heals to --otlp-endpoint and returns a function flushing and
+// stopping the export. Without an endpoint tracing stays off and the function does nothing.
+func setupTracing(instance string) (func(), error) {
+	if otlpEndpoint == "" {
+		return func() {}, nil
+	}
+	if traceSampleRatio < 0 || traceSampleRatio > 1 {
+		return nil, fmt.Errorf("--trace-sample-ratio must be between 0 and 1")
+	}
+	u, err := url.Parse(otlpEndpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid --otlp-endpoint %q (expected an http or https URL)", otlpEndpoint)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = "/v1/traces"
+	}
+
+	exporter, err := otlptracehttp.New(context.Background(), otlptracehttp.WithEndpointURL(u.String()))
+	if err != nil {
+		return nil, fmt.Errorf("creating OTLP exporter: %w", err)
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(traceSampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(
+			semconv.ServiceName("k8s-healer"),
+			semconv.ServiceInstanceID(instance),
+		)),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	slog.Info("Exporting traces", "endpoint", u.String(), "sampleRatio", traceSampleRatio)
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := provider.Shutdown(ctx); err != nil {
+			slog.Warn("Failed to flush traces", "err", err)
+		}
+	}, nil
+}
@@ -0,0 +1,169 @@
+// Package annotations defines the annotations and labels recognized or set by the healer, so other
+// tools and admission policies can integrate with them programmatically.
+package annotations
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Prefix is the prefix of all annotations owned by the healer.
+const Prefix = "k8s-healer.io/"
+
+// Enabled set to "false" on a Pod, its workload or its namespace excludes the Pod from healing. In
+// opt-in mode, only Pods with Enabled set to "true" on one of them are healed.
+const Enabled = Prefix + "enabled"
+
+// Watch set to "true" on a namespace has it watched when the healer discovers namespaces by
+// annotation; removing it drops the namespace again.
+const Watch = Prefix + "watch"
+
+// Hints is set by the admission webhook on Pods that lack probes or resource limits.
+// Its value is a comma-separated list of hints (e.g. "no-liveness-probe,no-memory-limit").
+const Hints = Prefix + "hints"
+
+// ContainerRestart opts a multi-container Pod into container-level healing: when set to "true",
+// only the misbehaving container is restarted instead of deleting the whole Pod.
+const ContainerRestart = Prefix + "container-restart"
+
+// AllowForceDelete opts a Pod or its workload into force deletion (grace period zero) even though
+// it mounts ReadWriteOnce volumes or belongs to a StatefulSet.
+const AllowForceDelete = Prefix + "allow-force-delete"
+
+// ScaleCycleReplicas records a workload's replica count while the healer has scaled it to zero for
+// a cold restart. It is removed once the workload is scaled back up.
+const ScaleCycleReplicas = Prefix + "scale-cycle-replicas"
+
+// PreDeleteCommand and PreDeleteContainer override the pre-delete hook's command (split on
+// whitespace) and container for a Pod.
+const (
+	PreDeleteCommand   = Prefix + "pre-delete-command"
+	PreDeleteContainer = Prefix + "pre-delete-container"
+)
+
+// RestartThreshold and Cooldown override the restart threshold and the heal cooldown for a Pod when
+// set on the Pod or its workload (e.g. "10" and "30m"). They take precedence over HealPolicies and
+// namespace settings.
+const (
+	RestartThreshold = Prefix + "restart-threshold"
+	Cooldown         = Prefix + "cooldown"
+)
+
+// ApprovedBy approves a pending heal of a Pod in a namespace that requires approval.
+// Its value names the approver and is recorded with the heal.
+const ApprovedBy = Prefix + "approved-by"
+
+// RemediatedPod is set on remediation Jobs to the name of the Pod they were created for.
+const RemediatedPod = Prefix + "pod"
+
+// HealedFrom and HealReason are set on the replacement of a deleted Pod: the name of the Pod it
+// replaces and why that Pod was healed. The healer may be configured to set them under another
+// prefix; see WithPrefix.
+const (
+	HealedFrom = Prefix + "healed-from"
+	HealReason = Prefix + "heal-reason"
+)
+
+// Quarantined is the label the healer sets to "true" on a workload it stopped healing because it
+// kept failing right after each heal. Removing the label resumes healing.
+const Quarantined = Prefix + "quarantined"
+
+// ManagedByLabel and ManagedBy label the objects created by the healer, e.g. remediation Jobs.
+const (
+	ManagedByLabel = "app.kubernetes.io/managed-by"
+	ManagedBy      = "k8s-healer"
+)
+
+// WithPrefix returns the annotation under another prefix (e.g. "healer.io/healed-from").
+func WithPrefix(key, prefix string) string {
+	return prefix + strings.TrimPrefix(key, Prefix)
+}
+
+// IsTrue reports whether the annotation is set to "true".
+func IsTrue(annotations map[string]string, key string) bool {
+	return strings.TrimSpace(annotations[key]) == "true"
+}
+
+// IsDisabled reports whether the Enabled annotation opts out of healing.
+func IsDisabled(annotations map[string]string) bool {
+	return strings.EqualFold(strings.TrimSpace(annotations[Enabled]), "false")
+}
+
+// IsOptedIn reports whether the Enabled annotation opts in to healing.
+func IsOptedIn(annotations map[string]string) bool {
+	return strings.EqualFold(strings.TrimSpace(annotations[Enabled]), "true")
+}
+
+// ParseHints returns the hints listed in the Hints annotation.
+func ParseHints(annotations map[string]string) []string {
+	var hints []string
+	for _, hint := range strings.Split(annotations[Hints], ",") {
+		if hint = strings.TrimSpace(hint); hint != "" {
+			hints = append(hints, hint)
+		}
+	}
+	return hints
+}
+
+// FormatHints formats hints as the value of the Hints annotation.
+func FormatHints(hints []string) string {
+	return strings.Join(hints, ",")
+}
+
+// ParseApprovedBy returns who approved healing the Pod, or "".
+func ParseApprovedBy(annotations map[string]string) string {
+	return strings.TrimSpace(annotations[ApprovedBy])
+}
+
+// ParsePreDeleteCommand returns the pre-delete command override, or nil.
+func ParsePreDeleteCommand(annotations map[string]string) []string {
+	return strings.Fields(annotations[PreDeleteCommand])
+}
+
+// ParseScaleCycleReplicas returns the replica count recorded by a scale cycle in progress. ok is
+// false if the annotation is absent.
+func ParseScaleCycleReplicas(annotations map[string]string) (replicas int32, ok bool, err error) {
+	value, ok := annotations[ScaleCycleReplicas]
+	if !ok || value == "" {
+		return 0, false, nil
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 32)
+	if err != nil || n < 0 {
+		return 0, true, fmt.Errorf("invalid %s annotation %q", ScaleCycleReplicas, value)
+	}
+	return int32(n), true, nil
+}
+
+// ParseRestartThreshold returns the restart threshold override. ok is false if the annotation is
+// absent.
+func ParseRestartThreshold(annotations map[string]string) (threshold int32, ok bool, err error) {
+	value := strings.TrimSpace(annotations[RestartThreshold])
+	if value == "" {
+		return 0, false, nil
+	}
+	n, err := strconv.ParseInt(value, 10, 32)
+	if err != nil || n < 1 {
+		return 0, true, fmt.Errorf("invalid %s annotation %q", RestartThreshold, value)
+	}
+	return int32(n), true, nil
+}
+
+// ParseCooldown returns the heal cooldown override. ok is false if the annotation is absent.
+func ParseCooldown(annotations map[string]string) (cooldown time.Duration, ok bool, err error) {
+	value := strings.TrimSpace(annotations[Cooldown])
+	if value == "" {
+		return 0, false, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, true, fmt.Errorf("invalid %s annotation %q", Cooldown, value)
+	}
+	return d, true, nil
+}
+
+// JSONPointer escapes the annotation key for use in a JSON patch path ("~" -> "~0", "/" -> "~1").
+func JSONPointer(key string) string {
+	return "/metadata/annotations/" + strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
@@ -0,0 +1,209 @@
+// Package audit appends a record of every destructive action to a local file, rotated by size and
+// age, so actions can be reviewed without relying on log aggregation.
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Entry is one destructive action.
+type Entry struct {
+	Time      time.Time `json:"time"`
+	Healer    string    `json:"healer"` // Identity of the healer instance
+	Namespace string    `json:"namespace"`
+	Pod       string    `json:"pod"`
+	Owner     string    `json:"owner,omitempty"`
+	Check     string    `json:"check,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+	Action    string    `json:"action"`
+	Result    string    `json:"result"` // success or failure
+	Error     string    `json:"error,omitempty"`
+}
+
+// Rotation controls when the audit log is rotated and how many rotated files are kept.
+type Rotation struct {
+	MaxSize    int64         // Rotate once the file exceeds this many bytes; 0 disables
+	MaxAge     time.Duration // Rotate once the file is this old; 0 disables
+	MaxBackups int           // Rotated files kept, oldest deleted first; 0 keeps all
+}
+
+// DefaultRotation rotates daily or at 100 MiB and keeps a month of daily files.
+var DefaultRotation = Rotation{MaxSize: 100 << 20, MaxAge: 24 * time.Hour, MaxBackups: 30}
+
+// backupTimeFormat is appended to the path of rotated files; it sorts chronologically.
+const backupTimeFormat = "20060102T150405.000000000Z"
+
+// Log appends entries to the audit file as lines of JSON. The file is only ever appended to, and
+// rotated files are made read-only.
+type Log struct {
+	path     string
+	rotation Rotation
+
+	mu      sync.Mutex
+	file    *os.File
+	size    int64
+	created time.Time
+}
+
+// Open opens the audit log at path, creating it and its directory if needed.
+func Open(path string, rotation Rotation) (*Log, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, fmt.Errorf("creating audit log directory: %w", err)
+	}
+	l := &Log{path: path, rotation: rotation}
+	if err := l.open(time.Now()); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// open opens the current file. The age of an existing file counts from its first entry.
+func (l *Log) open(now time.Time) error {
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("opening audit log: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("opening audit log: %w", err)
+	}
+	l.file, l.size, l.created = f, info.Size(), now
+	if first, ok := firstEntry(l.path); ok && first.Time.Before(now) {
+		l.created = first.Time
+	}
+	return nil
+}
+
+// firstEntry reads the first entry of the file at path.
+func firstEntry(path string) (Entry, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Entry{}, false
+	}
+	defer f.Close()
+	var e Entry
+	if err := json.NewDecoder(f).Decode(&e); err != nil {
+		return Entry{}, false
+	}
+	return e, true
+}
+
+// Write appends an entry, rotating the file first if it is due.
+func (l *Log) Write(e Entry) error {
+	if l == nil {
+		return nil
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return fmt.Errorf("audit log is closed")
+	}
+	if l.due(e.Time, int64(len(line))) {
+		if err := l.rotate(e.Time); err != nil {
+			return err
+		}
+	}
+	n, err := l.file.Write(line)
+	l.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("writing audit log: %w", err)
+	}
+	return nil
+}
+
+// due reports whether the file must be rotated before writing n more bytes.
+func (l *Log) due(now time.Time, n int64) bool {
+	if l.size == 0 {
+		return false
+	}
+	if l.rotation.MaxSize > 0 && l.size+n > l.rotation.MaxSize {
+		return true
+	}
+	return l.rotation.MaxAge > 0 && now.Sub(l.created) >= l.rotation.MaxAge
+}
+
+// rotate renames the current file aside, makes it read-only, opens a new one and deletes the
+// rotated files beyond MaxBackups.
+func (l *Log) rotate(now time.Time) error {
+	if err := l.file.Close(); err != nil {
+		return fmt.Errorf("closing audit log: %w", err)
+	}
+	l.file = nil
+	backup := l.path + "." + now.UTC().Format(backupTimeFormat)
+	if err := os.Rename(l.path, backup); err != nil {
+		return fmt.Errorf("rotating audit log: %w", err)
+	}
+	_ = os.Chmod(backup, 0o400)
+	if err := l.open(now); err != nil {
+		return err
+	}
+	return l.prune()
+}
+
+// prune deletes the oldest rotated files beyond MaxBackups.
+func (l *Log) prune() error {
+	if l.rotation.MaxBackups <= 0 {
+		return nil
+	}
+	backups, err := l.Backups()
+	if err != nil {
+		return err
+	}
+	for len(backups) > l.rotation.MaxBackups {
+		if err := os.Remove(backups[0]); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("deleting rotated audit log: %w", err)
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
+
+// Backups returns the paths of the rotated files, oldest first.
+func (l *Log) Backups() ([]string, error) {
+	dir, base := filepath.Dir(l.path), filepath.Base(l.path)+"."
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("listing rotated audit logs: %w", err)
+	}
+	var backups []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.Type().IsRegular() || !strings.HasPrefix(name, base) {
+			continue
+		}
+		if _, err := time.Parse(backupTimeFormat, strings.TrimPrefix(name, base)); err == nil {
+			backups = append(backups, filepath.Join(dir, name))
+		}
+	}
+	sort.Strings(backups)
+	return backups, nil
+}
+
+// Close closes the audit log.
+func (l *Log) Close() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
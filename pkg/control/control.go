@@ -0,0 +1,195 @@
+package control
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+)
+
+// DefaultConfigMapName is the ConfigMap holding the healer's runtime control state.
+const DefaultConfigMapName = "k8s-healer-control"
+
+// maxAuditEntries bounds the audit trail kept in the ConfigMap (ConfigMaps are limited to 1MiB).
+const maxAuditEntries = 500
+
+// ConfigMap data keys.
+const (
+	blackoutsKey = "blackouts"
+	auditKey     = "audit"
+)
+
+// Blackout temporarily suppresses healing in the matching namespaces until it expires.
+type Blackout struct {
+	ID        string    `json:"id"`
+	Namespace string    `json:"namespace"` // Namespace name or glob ("*" for all)
+	Reason    string    `json:"reason"`
+	CreatedBy string    `json:"createdBy"`
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// Matches reports whether the blackout covers the namespace.
+func (b Blackout) Matches(namespace string) bool {
+	ok, _ := filepath.Match(b.Namespace, namespace)
+	return ok
+}
+
+// Active reports whether the blackout is in effect at the given time.
+func (b Blackout) Active(now time.Time) bool {
+	return now.Before(b.ExpiresAt)
+}
+
+// AuditEntry records one control-plane interaction with the healer.
+type AuditEntry struct {
+	Time      time.Time `json:"time"`
+	Actor     string    `json:"actor"`
+	Operation string    `json:"operation"`
+	Detail    string    `json:"detail"`
+}
+
+// State is the control state persisted in the ConfigMap.
+type State struct {
+	Blackouts []Blackout   `json:"blackouts"`
+	Audit     []AuditEntry `json:"audit"`
+}
+
+// ActiveBlackout returns the active blackout covering the namespace, or nil.
+func (s *State) ActiveBlackout(namespace string, now time.Time) *Blackout {
+	for i := range s.Blackouts {
+		b := &s.Blackouts[i]
+		if b.Active(now) && b.Matches(namespace) {
+			return b
+		}
+	}
+	return nil
+}
+
+// AddAudit appends an audit entry, keeping only the most recent entries.
+func (s *State) AddAudit(actor, operation, detail string) {
+	s.Audit = append(s.Audit, AuditEntry{Time: time.Now().UTC(), Actor: actor, Operation: operation, Detail: detail})
+	if len(s.Audit) > maxAuditEntries {
+		s.Audit = s.Audit[len(s.Audit)-maxAuditEntries:]
+	}
+}
+
+// PruneExpired removes expired blackouts, recording an audit entry for each, and returns how many were removed.
+func (s *State) PruneExpired(now time.Time, actor string) int {
+	kept := s.Blackouts[:0]
+	removed := 0
+	for _, b := range s.Blackouts {
+		if b.Active(now) {
+			kept = append(kept, b)
+			continue
+		}
+		removed++
+		s.AddAudit(actor, "blackout-expired", fmt.Sprintf("%s (namespace %s, reason %q)", b.ID, b.Namespace, b.Reason))
+	}
+	s.Blackouts = kept
+	return removed
+}
+
+// Store reads and writes the control state in a ConfigMap.
+type Store struct {
+	Client    kubernetes.Interface
+	Namespace string
+	Name      string
+}
+
+// NewStore returns a store for the named ConfigMap.
+func NewStore(client kubernetes.Interface, namespace, name string) *Store {
+	if name == "" {
+		name = DefaultConfigMapName
+	}
+	return &Store{Client: client, Namespace: namespace, Name: name}
+}
+
+// Load returns the current control state. A missing ConfigMap yields an empty state.
+func (s *Store) Load(ctx context.Context) (*State, error) {
+	cm, err := s.Client.CoreV1().ConfigMaps(s.Namespace).Get(ctx, s.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return &State{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get control ConfigMap %s/%s: %w", s.Namespace, s.Name, err)
+	}
+	return decodeState(cm)
+}
+
+// Update applies fn to the current state and writes it back, retrying on conflicts and creating
+// the ConfigMap if it does not exist yet.
+func (s *Store) Update(ctx context.Context, fn func(*State) error) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cm, err := s.Client.CoreV1().ConfigMaps(s.Namespace).Get(ctx, s.Name, metav1.GetOptions{})
+		create := apierrors.IsNotFound(err)
+		if err != nil && !create {
+			return fmt.Errorf("failed to get control ConfigMap %s/%s: %w", s.Namespace, s.Name, err)
+		}
+		if create {
+			cm = &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+				Name:      s.Name,
+				Namespace: s.Namespace,
+				Labels:    map[string]string{"app.kubernetes.io/managed-by": "k8s-healer"},
+			}}
+		}
+
+		state, err := decodeState(cm)
+		if err != nil {
+			return err
+		}
+		if err := fn(state); err != nil {
+			return err
+		}
+		if err := encodeState(cm, state); err != nil {
+			return err
+		}
+
+		if create {
+			_, err = s.Client.CoreV1().ConfigMaps(s.Namespace).Create(ctx, cm, metav1.CreateOptions{})
+		} else {
+			_, err = s.Client.CoreV1().ConfigMaps(s.Namespace).Update(ctx, cm, metav1.UpdateOptions{})
+		}
+		return err
+	})
+}
+
+func decodeState(cm *v1.ConfigMap) (*State, error) {
+	state := &State{}
+	if raw := cm.Data[blackoutsKey]; raw != "" {
+		if err := json.Unmarshal([]byte(raw), &state.Blackouts); err != nil {
+			return nil, fmt.Errorf("failed to decode %s in control ConfigMap: %w", blackoutsKey, err)
+		}
+	}
+	if raw := cm.Data[auditKey]; raw != "" {
+		if err := json.Unmarshal([]byte(raw), &state.Audit); err != nil {
+			return nil, fmt.Errorf("failed to decode %s in control ConfigMap: %w", auditKey, err)
+		}
+	}
+	sort.Slice(state.Blackouts, func(i, j int) bool { return state.Blackouts[i].ExpiresAt.Before(state.Blackouts[j].ExpiresAt) })
+	return state, nil
+}
+
+func encodeState(cm *v1.ConfigMap, state *State) error {
+	blackouts, err := json.MarshalIndent(state.Blackouts, "", "  ")
+	if err != nil {
+		return err
+	}
+	audit, err := json.MarshalIndent(state.Audit, "", "  ")
+	if err != nil {
+		return err
+	}
+	if cm.Data == nil {
+		cm.Data = make(map[string]string)
+	}
+	cm.Data[blackoutsKey] = string(blackouts)
+	cm.Data[auditKey] = string(audit)
+	return nil
+}
@@ -0,0 +1,120 @@
+package fleet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/daigoro86dev/k8s-healer/pkg/healer"
+)
+
+// Instance is the status of one healer instance, or the error encountered fetching it.
+type Instance struct {
+	Endpoint string         `json:"endpoint"`
+	Status   *healer.Status `json:"status,omitempty"`
+	Error    string         `json:"error,omitempty"`
+}
+
+// Summary aggregates the status of a fleet of healer instances.
+type Summary struct {
+	GeneratedAt         time.Time  `json:"generatedAt"`
+	Instances           []Instance `json:"instances"`
+	Reachable           int        `json:"reachable"`
+	Unreachable         int        `json:"unreachable"`
+	HealsLastHour       int        `json:"healsLastHour"`
+	FailedHealsLastHour int        `json:"failedHealsLastHour"`
+	OpenCircuitBreakers int        `json:"openCircuitBreakers"`
+	ActiveBlackouts     int        `json:"activeBlackouts"`
+	DegradedAPIs        []string   `json:"degradedApis,omitempty"` // clusters whose API server is not healthy
+
+	// DegradedNamespaces lists cluster/namespace pairs with recent heals, most heals first.
+	DegradedNamespaces []ClusterNamespace `json:"degradedNamespaces,omitempty"`
+}
+
+// ClusterNamespace is a namespace with recent heals in a given cluster.
+type ClusterNamespace struct {
+	Cluster   string `json:"cluster"`
+	Namespace string `json:"namespace"`
+	Heals     int    `json:"heals"`
+	Failures  int    `json:"failures"`
+}
+
+// Collect fetches /status from every endpoint concurrently and aggregates the results.
+func Collect(ctx context.Context, client *http.Client, endpoints []string) Summary {
+	instances := make([]Instance, len(endpoints))
+	var wg sync.WaitGroup
+	for i, ep := range endpoints {
+		wg.Add(1)
+		go func(i int, ep string) {
+			defer wg.Done()
+			st, err := fetchStatus(ctx, client, ep)
+			instances[i] = Instance{Endpoint: ep, Status: st}
+			if err != nil {
+				instances[i].Error = err.Error()
+			}
+		}(i, ep)
+	}
+	wg.Wait()
+
+	return Aggregate(instances)
+}
+
+// Aggregate summarizes already-fetched instance statuses.
+func Aggregate(instances []Instance) Summary {
+	sum := Summary{GeneratedAt: time.Now(), Instances: instances}
+	for _, inst := range instances {
+		if inst.Status == nil {
+			sum.Unreachable++
+			continue
+		}
+		st := inst.Status
+		sum.Reachable++
+		sum.HealsLastHour += st.HealsLastHour
+		sum.FailedHealsLastHour += st.FailedHealsLastHour
+		sum.OpenCircuitBreakers += len(st.CircuitBreakers)
+		sum.ActiveBlackouts += len(st.Blackouts)
+
+		cluster := st.Cluster
+		if cluster == "" {
+			cluster = inst.Endpoint
+		}
+		if st.APIState != healer.APIHealthy {
+			sum.DegradedAPIs = append(sum.DegradedAPIs, fmt.Sprintf("%s (%s)", cluster, st.APIState))
+		}
+		for _, ns := range st.DegradedNamespaces {
+			sum.DegradedNamespaces = append(sum.DegradedNamespaces, ClusterNamespace{
+				Cluster: cluster, Namespace: ns.Namespace, Heals: ns.Heals, Failures: ns.Failures,
+			})
+		}
+	}
+	sort.Slice(sum.DegradedNamespaces, func(i, j int) bool {
+		return sum.DegradedNamespaces[i].Heals > sum.DegradedNamespaces[j].Heals
+	})
+	return sum
+}
+
+func fetchStatus(ctx context.Context, client *http.Client, endpoint string) (*healer.Status, error) {
+	url := strings.TrimSuffix(endpoint, "/") + "/status"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	st := &healer.Status{}
+	if err := json.NewDecoder(resp.Body).Decode(st); err != nil {
+		return nil, fmt.Errorf("malformed status: %w", err)
+	}
+	return st, nil
+}
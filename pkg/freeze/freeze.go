@@ -0,0 +1,276 @@
+// Package freeze reads change-freeze windows from an external calendar, so healing can follow the
+// organization's change calendar. Two sources are supported: iCalendar feeds (RFC 5545) and a
+// simple JSON endpoint.
+package freeze
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// maxCalendarSize bounds the calendar documents read.
+const maxCalendarSize = 4 << 20
+
+// Window is a period during which healing in the matching namespaces is notify-only.
+type Window struct {
+	Summary    string    `json:"summary,omitempty"`
+	Start      time.Time `json:"start"`
+	End        time.Time `json:"end"`
+	Namespaces []string  `json:"namespaces,omitempty"` // Names or globs; all namespaces if empty
+}
+
+// Matches reports whether the window covers the namespace.
+func (w Window) Matches(namespace string) bool {
+	if len(w.Namespaces) == 0 {
+		return true
+	}
+	for _, pattern := range w.Namespaces {
+		if ok, _ := filepath.Match(pattern, namespace); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Active reports whether the window is in effect at the given time.
+func (w Window) Active(now time.Time) bool {
+	return !now.Before(w.Start) && now.Before(w.End)
+}
+
+// ActiveWindow returns the window in effect for the namespace at the given time, or nil. Of several
+// overlapping windows, the one ending last is returned.
+func ActiveWindow(windows []Window, namespace string, now time.Time) *Window {
+	var active *Window
+	for i := range windows {
+		w := &windows[i]
+		if w.Active(now) && w.Matches(namespace) && (active == nil || w.End.After(active.End)) {
+			active = w
+		}
+	}
+	return active
+}
+
+// Fetch downloads the calendar at url and returns its windows, and the iCalendar events skipped
+// because they couldn't be used. iCalendar documents are recognized by their BEGIN:VCALENDAR
+// header; anything else is parsed as JSON.
+func Fetch(ctx context.Context, client *http.Client, url string) ([]Window, []error, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Accept", "text/calendar, application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return nil, nil, fmt.Errorf("calendar returned HTTP %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxCalendarSize))
+	if err != nil {
+		return nil, nil, err
+	}
+	if strings.HasPrefix(strings.TrimSpace(string(body)), "BEGIN:VCALENDAR") {
+		windows, skipped := ParseICal(string(body))
+		return windows, skipped, nil
+	}
+	windows, err := ParseJSON(body)
+	return windows, nil, err
+}
+
+// ParseJSON parses windows from a JSON document: either an array of windows or an object with a
+// "windows" array, e.g.
+//
+//	{"windows": [{"summary": "Black Friday", "start": "2026-11-27T00:00:00Z", "end": "2026-11-30T00:00:00Z", "namespaces": ["shop-*"]}]}
+func ParseJSON(data []byte) ([]Window, error) {
+	var windows []Window
+	if strings.HasPrefix(strings.TrimSpace(string(data)), "[") {
+		if err := json.Unmarshal(data, &windows); err != nil {
+			return nil, fmt.Errorf("invalid freeze windows: %w", err)
+		}
+	} else {
+		var doc struct {
+			Windows []Window `json:"windows"`
+		}
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("invalid freeze windows: %w", err)
+		}
+		windows = doc.Windows
+	}
+	for i, w := range windows {
+		if w.Start.IsZero() || !w.End.After(w.Start) {
+			return nil, fmt.Errorf("freeze window %d (%s): end must be after start", i+1, w.Summary)
+		}
+	}
+	return windows, nil
+}
+
+// ParseICal parses the VEVENTs of an iCalendar document as windows. The namespaces of an event are
+// taken from its CATEGORIES; events without categories cover all namespaces. Events that can't be
+// used (invalid or unknown times, an unknown TZID, or recurrence, which isn't supported) are skipped
+// and returned as errors, so one bad event doesn't discard the whole calendar.
+func ParseICal(data string) ([]Window, []error) {
+	var (
+		windows  []Window
+		skipped  []error
+		inEvent  bool
+		current  Window
+		duration time.Duration
+		start    int   // Line of the current event's BEGIN
+		problem  error // First problem with the current event
+	)
+	for i, line := range unfold(data) {
+		name, params, value := splitProperty(line)
+		switch {
+		case name == "BEGIN" && value == "VEVENT":
+			inEvent, current, duration, start, problem = true, Window{}, 0, i+1, nil
+		case name == "END" && value == "VEVENT":
+			inEvent = false
+			if current.End.IsZero() && duration > 0 {
+				current.End = current.Start.Add(duration)
+			}
+			if problem == nil && (current.Start.IsZero() || !current.End.After(current.Start)) {
+				problem = fmt.Errorf("no valid DTSTART/DTEND")
+			}
+			if problem != nil {
+				skipped = append(skipped, fmt.Errorf("event %q at line %d skipped: %w", current.Summary, start, problem))
+				continue
+			}
+			windows = append(windows, current)
+		case !inEvent:
+		case problem != nil && name != "SUMMARY":
+		case name == "RRULE" || name == "RDATE":
+			problem = fmt.Errorf("recurring events (%s) are not supported; list each occurrence as its own event", name)
+		case name == "SUMMARY":
+			current.Summary = unescape(value)
+		case name == "CATEGORIES":
+			for _, ns := range strings.Split(value, ",") {
+				if ns = strings.TrimSpace(unescape(ns)); ns != "" {
+					current.Namespaces = append(current.Namespaces, ns)
+				}
+			}
+		case name == "DTSTART" || name == "DTEND":
+			t, err := parseICalTime(params, value)
+			if err != nil {
+				problem = fmt.Errorf("line %d: invalid %s: %w", i+1, name, err)
+				continue
+			}
+			if name == "DTSTART" {
+				current.Start = t
+				// An all-day event without DTEND lasts one day
+				if params["VALUE"] == "DATE" && current.End.IsZero() && duration == 0 {
+					duration = 24 * time.Hour
+				}
+			} else {
+				current.End = t
+			}
+		case name == "DURATION":
+			d, err := parseICalDuration(value)
+			if err != nil {
+				problem = fmt.Errorf("line %d: invalid DURATION: %w", i+1, err)
+				continue
+			}
+			duration = d
+		}
+	}
+	return windows, skipped
+}
+
+// unfold joins folded content lines (continuation lines start with a space or tab).
+func unfold(data string) []string {
+	var lines []string
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	scanner.Buffer(make([]byte, 64<<10), maxCalendarSize)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// splitProperty splits a content line such as "DTSTART;TZID=Europe/Berlin:20261224T000000" into
+// its name, parameters and value.
+func splitProperty(line string) (string, map[string]string, string) {
+	head, value, _ := strings.Cut(line, ":")
+	parts := strings.Split(head, ";")
+	params := make(map[string]string, len(parts)-1)
+	for _, p := range parts[1:] {
+		if k, v, ok := strings.Cut(p, "="); ok {
+			params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+		}
+	}
+	return strings.ToUpper(parts[0]), params, value
+}
+
+// parseICalTime parses DATE and DATE-TIME values, in UTC ("Z" suffix), in the TZID zone, or as
+// floating times in UTC.
+func parseICalTime(params map[string]string, value string) (time.Time, error) {
+	loc := time.UTC
+	if tzid := params["TZID"]; tzid != "" {
+		l, err := time.LoadLocation(tzid)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("unknown TZID %q", tzid)
+		}
+		loc = l
+	}
+	switch {
+	case params["VALUE"] == "DATE" || len(value) == 8:
+		return time.ParseInLocation("20060102", value, loc)
+	case strings.HasSuffix(value, "Z"):
+		return time.Parse("20060102T150405Z", value)
+	default:
+		return time.ParseInLocation("20060102T150405", value, loc)
+	}
+}
+
+// parseICalDuration parses durations such as "PT2H", "P1D" or "P1W".
+func parseICalDuration(value string) (time.Duration, error) {
+	s := strings.TrimPrefix(strings.TrimPrefix(value, "+"), "P")
+	if s == value || s == "" {
+		return 0, fmt.Errorf("%q is not a duration", value)
+	}
+	var d time.Duration
+	inTime := false
+	num := 0
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			num = num*10 + int(r-'0')
+			continue
+		case r == 'T':
+			inTime = true
+			continue
+		case r == 'W' && !inTime:
+			d += time.Duration(num) * 7 * 24 * time.Hour
+		case r == 'D' && !inTime:
+			d += time.Duration(num) * 24 * time.Hour
+		case r == 'H' && inTime:
+			d += time.Duration(num) * time.Hour
+		case r == 'M' && inTime:
+			d += time.Duration(num) * time.Minute
+		case r == 'S' && inTime:
+			d += time.Duration(num) * time.Second
+		default:
+			return 0, fmt.Errorf("%q is not a duration", value)
+		}
+		num = 0
+	}
+	return d, nil
+}
+
+// unescape resolves the text escapes of iCalendar values.
+func unescape(s string) string {
+	return strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(s)
+}
@@ -0,0 +1,160 @@
+package healer
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// Healing actions selectable by policy.
+const (
+	ActionDelete = "delete" // Delete the Pod and let its controller recreate it (default)
+	ActionNotify = "notify" // Report the failure without acting on it
+	ActionSkip   = "skip"   // Ignore the failure
+
+	// ActionRolloutRestart restarts the owning Deployment/StatefulSet like `kubectl rollout restart`,
+	// respecting its surge/unavailable budgets. Pods without such an owner are deleted instead.
+	ActionRolloutRestart = "rollout-restart"
+
+	// ActionRollback rolls the owning Deployment back to its previous revision when every Pod of the
+	// newest ReplicaSet is crash-looping. Otherwise the Pod is deleted as usual.
+	ActionRollback = "rollback"
+
+	// ActionScaleCycle scales the owning Deployment/StatefulSet to zero and back, for apps that need
+	// a full cold restart. Pods without such an owner are deleted instead.
+	ActionScaleCycle = "scale-cycle"
+
+	// ActionJob creates a remediation Job from a user-supplied template, then deletes the Pod if
+	// RemediationJobThenDelete is set.
+	ActionJob = "job"
+
+	// ActionMemoryBump raises the OOM-killed container's memory limit in the owning
+	// Deployment/StatefulSet by MemoryBumpPercent, up to MemoryBumpMax. Other failures, and limits
+	// already at the cap, are deleted instead.
+	ActionMemoryBump = "memory-bump"
+
+	// ActionWebhook POSTs the heal decision to an external remediation endpoint and deletes the Pod
+	// only if it approves (2xx).
+	ActionWebhook = "webhook"
+
+	// ActionRestartContainer restarts only the failing container. It is chosen automatically for
+	// opted-in multi-container Pods and falls back to ActionDelete when it can't be applied.
+	ActionRestartContainer = "restart-container"
+)
+
+// validActions lists the actions accepted in policy configuration.
+var validActions = map[string]bool{
+	ActionDelete: true,
+	ActionNotify: true,
+	ActionSkip:   true,
+
+	ActionRolloutRestart: true,
+	ActionRollback:       true,
+	ActionScaleCycle:     true,
+	ActionJob:            true,
+	ActionWebhook:        true,
+	ActionMemoryBump:     true,
+}
+
+// IsValidAction reports whether the action can be selected by policy.
+func IsValidAction(action string) bool {
+	return validActions[action]
+}
+
+// ParseCheckActions parses check=action pairs (e.g. {"crashloop": "scale-cycle"}).
+func ParseCheckActions(in map[string]string) (map[string]string, error) {
+	out := make(map[string]string, len(in))
+	for check, action := range in {
+		check, action = strings.TrimSpace(check), strings.TrimSpace(action)
+		if !knownChecks[check] {
+			return nil, fmt.Errorf("unknown check %q", check)
+		}
+		if !validActions[action] {
+			return nil, fmt.Errorf("invalid action %q for check %s", action, check)
+		}
+		out[check] = action
+	}
+	return out, nil
+}
+
+// parseChecks parses a list of check names into a set. An empty list yields nil, allowing all checks.
+func parseChecks(in []string) (map[string]bool, error) {
+	if len(in) == 0 {
+		return nil, nil
+	}
+	out := make(map[string]bool, len(in))
+	for _, check := range in {
+		check = strings.TrimSpace(check)
+		if !knownChecks[check] {
+			return nil, fmt.Errorf("unknown check %q", check)
+		}
+		out[check] = true
+	}
+	return out, nil
+}
+
+// ParseExitCodeActions parses exit-code=action pairs (e.g. {"1": "notify", "137": "delete"}).
+func ParseExitCodeActions(in map[string]string) (map[int32]string, error) {
+	out := make(map[int32]string, len(in))
+	for code, action := range in {
+		c, err := strconv.ParseInt(strings.TrimSpace(code), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exit code %q: %w", code, err)
+		}
+		action = strings.TrimSpace(action)
+		if !validActions[action] {
+			return nil, fmt.Errorf("invalid action %q for exit code %d", action, c)
+		}
+		out[int32(c)] = action
+	}
+	return out, nil
+}
+
+// performAction executes the healing action for the Pod and returns the action actually taken,
+// which may differ from the requested one when an action falls back to deletion.
+func (h *Healer) performAction(ctx context.Context, action string, pod *v1.Pod, owner *OwnerInfo, f *failure) (string, error) {
+	switch action {
+	case ActionRolloutRestart:
+		if owner != nil && (owner.Deployment != nil || owner.StatefulSet != nil) {
+			return ActionRolloutRestart, h.rolloutRestart(ctx, pod, owner)
+		}
+		h.Log.Info("Workload can't be rollout-restarted; deleting pod instead", "owner", owner.String(), "pod", pod.Namespace+"/"+pod.Name)
+	case ActionMemoryBump:
+		template, err := memoryBumpTarget(owner, f)
+		if err == nil {
+			if err = h.bumpMemory(ctx, pod, owner, template, f.Termination.Container); err == nil {
+				return ActionMemoryBump, nil
+			}
+		}
+		h.Log.Info("Not raising the memory limit; deleting pod instead", "pod", pod.Namespace+"/"+pod.Name, "why", err)
+	case ActionWebhook:
+		return h.remediateViaWebhook(ctx, pod, owner, f)
+	case ActionJob:
+		if err := h.runRemediationJob(ctx, pod, owner, f); err != nil || !h.RemediationJobThenDelete {
+			return ActionJob, err
+		}
+	case ActionScaleCycle:
+		if owner != nil && (owner.Deployment != nil || owner.StatefulSet != nil) {
+			return ActionScaleCycle, h.scaleCycle(ctx, pod, owner)
+		}
+		h.Log.Info("Workload can't be scale-cycled; deleting pod instead", "owner", owner.String(), "pod", pod.Namespace+"/"+pod.Name)
+	case ActionRollback:
+		previous, err := h.rollbackTarget(ctx, owner)
+		if err == nil {
+			return ActionRollback, h.rollbackDeployment(ctx, pod, owner, previous)
+		}
+		h.Log.Info("Not rolling back; deleting pod instead", "pod", pod.Namespace+"/"+pod.Name, "why", err)
+	case ActionDelete:
+		if container := containerRestartTarget(pod, f); container != "" {
+			err := h.restartContainer(ctx, pod, container)
+			if err == nil {
+				return ActionRestartContainer, nil
+			}
+			h.Log.Info("Falling back to deleting pod", "pod", pod.Namespace+"/"+pod.Name, "why", err)
+		}
+	}
+	return ActionDelete, h.triggerPodDeletion(ctx, pod, f)
+}
@@ -0,0 +1,160 @@
+package healer
+
+import (
+	"log/slog"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// APIState summarizes how the API server is responding to the healer's own requests.
+type APIState string
+
+const (
+	APIHealthy     APIState = "healthy"
+	APIDegraded    APIState = "degraded"    // Slow responses or elevated 429/5xx rates
+	APIUnavailable APIState = "unavailable" // Most requests failing
+)
+
+// apiHealthWindow is the sliding window over which API server responsiveness is judged.
+const apiHealthWindow = 2 * time.Minute
+
+// apiHealthMinSamples is the minimum number of requests in the window before a verdict is made.
+const apiHealthMinSamples = 10
+
+type apiSample struct {
+	at      time.Time
+	latency time.Duration
+	failed  bool // 429, 5xx or transport error
+}
+
+// apiHealthMonitor observes every request the healer sends to the API server (through a wrapped
+// transport) and classifies the control plane as healthy, degraded or unavailable.
+type apiHealthMonitor struct {
+	LatencyThreshold   time.Duration // p90 latency above this means degraded
+	ErrorRateThreshold float64       // fraction of failed requests above this means degraded
+
+	log *slog.Logger
+
+	mu      sync.Mutex
+	samples []apiSample
+	state   APIState
+}
+
+func newAPIHealthMonitor() *apiHealthMonitor {
+	return &apiHealthMonitor{
+		LatencyThreshold:   2 * time.Second,
+		ErrorRateThreshold: 0.2,
+		state:              APIHealthy,
+		log:                slog.Default(),
+	}
+}
+
+// wrap instruments a client-go transport; it is installed through rest.Config.Wrap.
+func (m *apiHealthMonitor) wrap(rt http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		start := time.Now()
+		resp, err := rt.RoundTrip(req)
+
+		failed := err != nil || (resp != nil && (resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500))
+		// Watches are long-lived by design; only their failures say something about the API server.
+		if req.URL.Query().Get("watch") == "true" && !failed {
+			return resp, err
+		}
+		m.observe(apiSample{at: start, latency: time.Since(start), failed: failed})
+		return resp, err
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func (m *apiHealthMonitor) observe(s apiSample) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.samples = append(m.samples, s)
+	m.trimLocked(s.at)
+}
+
+// trimLocked drops the samples that fell out of the sliding window.
+func (m *apiHealthMonitor) trimLocked(now time.Time) {
+	cutoff := now.Add(-apiHealthWindow)
+	i := 0
+	for i < len(m.samples) && m.samples[i].at.Before(cutoff) {
+		i++
+	}
+	if i > 0 {
+		m.samples = append(m.samples[:0], m.samples[i:]...)
+	}
+}
+
+// State evaluates the samples in the window and returns the current API server state,
+// logging transitions between states.
+func (m *apiHealthMonitor) State() APIState {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.trimLocked(time.Now())
+
+	state := APIHealthy
+	if n := len(m.samples); n >= apiHealthMinSamples {
+		failed := 0
+		latencies := make([]time.Duration, 0, n)
+		for _, s := range m.samples {
+			if s.failed {
+				failed++
+			}
+			latencies = append(latencies, s.latency)
+		}
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		p90 := latencies[(n*9)/10]
+		errorRate := float64(failed) / float64(n)
+
+		switch {
+		case errorRate > 0.5:
+			state = APIUnavailable
+		case errorRate > m.ErrorRateThreshold || p90 > m.LatencyThreshold:
+			state = APIDegraded
+		}
+	}
+
+	if state != m.state {
+		m.log.Warn("API server state changed", "from", m.state, "to", state)
+		m.state = state
+	}
+	return state
+}
+
+// actionKind distinguishes pod heals from housekeeping when throttling on API server health.
+type actionKind int
+
+const (
+	actionHeal         actionKind = iota // Healing an unhealthy workload
+	actionHousekeeping                   // Cleanup that can always wait (e.g. garbage collection)
+)
+
+// apiAllows decides whether an action may run given the API server's health. Housekeeping pauses
+// as soon as the control plane is degraded; heals are slowed to one per DegradedHealInterval while
+// degraded and paused while it is unavailable.
+func (h *Healer) apiAllows(kind actionKind) (bool, string) {
+	if !h.APIHealthThrottle {
+		return true, ""
+	}
+
+	switch h.apiHealth.State() {
+	case APIUnavailable:
+		return false, "API server unavailable"
+	case APIDegraded:
+		if kind == actionHousekeeping {
+			return false, "API server degraded"
+		}
+		h.apiThrottleMu.Lock()
+		defer h.apiThrottleMu.Unlock()
+		if time.Since(h.lastDegradedHeal) < h.DegradedHealInterval {
+			return false, "API server degraded, heals are slowed down"
+		}
+		h.lastDegradedHeal = time.Now()
+	}
+	return true, ""
+}
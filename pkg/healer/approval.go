@@ -0,0 +1,136 @@
+package healer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/daigoro86dev/k8s-healer/pkg/annotations"
+	"github.com/daigoro86dev/k8s-healer/pkg/notify"
+	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// EventApprovalRequired asks a human to approve a heal.
+const EventApprovalRequired notify.EventType = "approval-required"
+
+// requiresApproval reports whether heals in the namespace need a human approval.
+func (h *Healer) requiresApproval(namespace string) bool {
+	for _, pattern := range h.ApprovalNamespaces {
+		if ok, _ := filepath.Match(pattern, namespace); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// approvedBy returns who approved healing the Pod, or "".
+func approvedBy(pod *v1.Pod) string {
+	return annotations.ParseApprovedBy(pod.Annotations)
+}
+
+// requestApproval notifies that the heal awaits approval, at most once per cooldown. The notification
+// carries a server-side dry-run preview of the action so the approver sees its consequences.
+func (h *Healer) requestApproval(pod *v1.Pod, owner *OwnerInfo, f *failure, action string) {
+	podKey := fmt.Sprintf("%s/%s", pod.Namespace, pod.Name)
+	if !h.approvals.shouldReport(podKey, h.cooldownFor(pod)) {
+		return
+	}
+
+	preview := h.dryRunPreview(pod, owner, action)
+	h.Log.Warn("Heal awaits approval", "pod", podKey, "reason", f.Reason, "action", action, "preview", preview)
+
+	message := fmt.Sprintf("Heal (%s) awaits approval. Preview:\n- %s\nApprove with: kubectl annotate pod -n %s %s %s=<your name>",
+		action, strings.Join(preview, "\n- "), pod.Namespace, pod.Name, annotations.ApprovedBy)
+	h.notify(pod, EventApprovalRequired, notify.SeverityWarning, f, action, message)
+}
+
+// dryRunPreview describes what the action would do, using server-side dry runs where possible:
+// whether the API server would admit it, which PodDisruptionBudget applies, and which controller
+// recreates the Pod.
+func (h *Healer) dryRunPreview(pod *v1.Pod, owner *OwnerInfo, action string) []string {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var preview []string
+	dryRun := metav1.DeleteOptions{DryRun: []string{metav1.DryRunAll}}
+
+	switch action {
+	case ActionDelete:
+		var err error
+		verb := "delete"
+		if h.UseEviction {
+			verb = "eviction"
+			err = h.ClientSet.PolicyV1().Evictions(pod.Namespace).Evict(ctx, &policyv1.Eviction{
+				ObjectMeta:    metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace},
+				DeleteOptions: &dryRun,
+			})
+		} else {
+			err = h.ClientSet.CoreV1().Pods(pod.Namespace).Delete(ctx, pod.Name, dryRun)
+		}
+		preview = append(preview, admission(verb, err))
+	case ActionRolloutRestart, ActionScaleCycle, ActionRollback:
+		if owner == nil || (owner.Deployment == nil && owner.StatefulSet == nil) {
+			preview = append(preview, fmt.Sprintf("%s: no Deployment/StatefulSet owner, the Pod would be deleted instead", action))
+			break
+		}
+		// An empty patch exercises authorization and admission for the owner without changing it.
+		patch, _ := json.Marshal(map[string]interface{}{"metadata": map[string]interface{}{}})
+		opts := metav1.PatchOptions{DryRun: []string{metav1.DryRunAll}}
+		var err error
+		if owner.Deployment != nil {
+			_, err = h.ClientSet.AppsV1().Deployments(owner.Namespace).Patch(ctx, owner.Name, types.MergePatchType, patch, opts)
+		} else {
+			_, err = h.ClientSet.AppsV1().StatefulSets(owner.Namespace).Patch(ctx, owner.Name, types.MergePatchType, patch, opts)
+		}
+		preview = append(preview, admission(fmt.Sprintf("%s of %s", action, owner), err))
+	default:
+		preview = append(preview, fmt.Sprintf("%s: no dry run available", action))
+	}
+
+	preview = append(preview, h.pdbPreview(ctx, pod))
+	if owner != nil {
+		preview = append(preview, fmt.Sprintf("recreated by: %s", owner.Summary()))
+	} else {
+		preview = append(preview, "recreated by: nothing (no controller)")
+	}
+	return preview
+}
+
+// admission summarizes a dry-run result.
+func admission(what string, err error) string {
+	if err != nil {
+		return fmt.Sprintf("dry-run %s: rejected (%v)", what, err)
+	}
+	return fmt.Sprintf("dry-run %s: admitted", what)
+}
+
+// pdbPreview names the PodDisruptionBudgets covering the Pod and their remaining disruptions.
+func (h *Healer) pdbPreview(ctx context.Context, pod *v1.Pod) string {
+	pdbs, err := h.ClientSet.PolicyV1().PodDisruptionBudgets(pod.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Sprintf("disruption budget: unknown (%v)", err)
+	}
+	var matching []string
+	for _, pdb := range pdbs.Items {
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil || selector.Empty() || !selector.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+		matching = append(matching, fmt.Sprintf("%s (%d disruption(s) allowed)", pdb.Name, pdb.Status.DisruptionsAllowed))
+	}
+	if len(matching) == 0 {
+		return "disruption budget: none"
+	}
+	note := ""
+	if !h.UseEviction && !h.RespectPDBs {
+		note = " — not enforced on plain deletes, see --use-eviction"
+	}
+	return fmt.Sprintf("disruption budget: %s%s", strings.Join(matching, ", "), note)
+}
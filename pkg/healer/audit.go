@@ -0,0 +1,32 @@
+package healer
+
+import (
+	"time"
+
+	"github.com/daigoro86dev/k8s-healer/pkg/audit"
+	v1 "k8s.io/api/core/v1"
+)
+
+// auditAction appends a destructive action taken on the Pod to the audit log.
+func (h *Healer) auditAction(pod *v1.Pod, check, reason, action string, err error) {
+	if h.Audit == nil {
+		return
+	}
+	e := audit.Entry{
+		Time:      time.Now(),
+		Healer:    h.Identity(),
+		Namespace: pod.Namespace,
+		Pod:       pod.Name,
+		Owner:     h.owners.Resolve(pod).String(),
+		Check:     check,
+		Reason:    reason,
+		Action:    action,
+		Result:    "success",
+	}
+	if err != nil {
+		e.Result, e.Error = "failure", err.Error()
+	}
+	if err := h.Audit.Write(e); err != nil {
+		h.Log.Error("Failed to write the audit log", "pod", pod.Namespace+"/"+pod.Name, "action", action, "err", err)
+	}
+}
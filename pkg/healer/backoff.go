@@ -0,0 +1,86 @@
+package healer
+
+import (
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Defaults for the cooldown backoff.
+const (
+	DefaultMaxHealCooldown = 2 * time.Hour
+	DefaultCooldownReset   = time.Hour
+)
+
+// backoffCooldown doubles the base cooldown for every heal of the streak after the first, up to
+// MaxHealCooldown. A MaxHealCooldown not above the base keeps the cooldown fixed.
+func (h *Healer) backoffCooldown(base time.Duration, streak int) time.Duration {
+	d := base
+	for i := 1; i < streak && d < h.MaxHealCooldown; i++ {
+		d *= 2
+	}
+	if d > base && d > h.MaxHealCooldown {
+		d = h.MaxHealCooldown
+	}
+	return d
+}
+
+// coolingDown returns the last heal of the Pod's controller if its cooldown hasn't expired yet.
+func (h *Healer) coolingDown(pod *v1.Pod) (healMark, bool) {
+	mark, ok := h.lastHealed(pod)
+	if !ok || !time.Now().Before(mark.Until) {
+		return healMark{}, false
+	}
+	return mark, true
+}
+
+// recordCooldown records a heal of the Pod's controller at the given time. The heal continues the
+// controller's streak unless the controller stayed healthy for CooldownReset after its previous
+// cooldown expired; the cooldown then lasts the backed-off cooldown, or d if that is longer.
+func (h *Healer) recordCooldown(pod *v1.Pod, at time.Time, d time.Duration) {
+	mark := healMark{At: at, Pod: pod.Name, Streak: 1}
+	if ref := metav1.GetControllerOf(pod); ref != nil {
+		mark.Owner = ref.Kind + "/" + ref.Name
+	}
+	base := h.cooldownFor(pod)
+
+	h.healedMu.Lock()
+	key := cooldownKey(pod)
+	// A heal right after the cooldown expired means the previous one didn't fix the workload
+	if prev, ok := h.HealedPods.peek(key); ok && at.Before(prev.Until.Add(h.CooldownReset)) {
+		mark.Streak = prev.Streak + 1
+	}
+	d = max(d, h.backoffCooldown(base, mark.Streak))
+	mark.Until = at.Add(d)
+	h.HealedPods.set(key, mark)
+	h.HealedPods.trim(h.HealCacheSize)
+	h.healedMu.Unlock()
+	h.storeCooldown(key, mark)
+}
+
+// holdOff keeps the Pod's controller from being healed for d without recording a heal, e.g. when
+// the decision webhook refuses a heal but asks for a cooldown. It neither shortens a running
+// cooldown nor continues the backoff streak.
+func (h *Healer) holdOff(pod *v1.Pod, d time.Duration) {
+	now := time.Now()
+	mark := healMark{At: now, Until: now.Add(d), Pod: pod.Name}
+	if ref := metav1.GetControllerOf(pod); ref != nil {
+		mark.Owner = ref.Kind + "/" + ref.Name
+	}
+
+	h.healedMu.Lock()
+	key := cooldownKey(pod)
+	if prev, ok := h.HealedPods.peek(key); ok {
+		if !prev.Until.Before(mark.Until) {
+			h.healedMu.Unlock()
+			return
+		}
+		prev.Until = mark.Until
+		mark = prev
+	}
+	h.HealedPods.set(key, mark)
+	h.HealedPods.trim(h.HealCacheSize)
+	h.healedMu.Unlock()
+	h.storeCooldown(key, mark)
+}
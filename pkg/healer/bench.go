@@ -0,0 +1,277 @@
+package healer
+
+import (
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/daigoro86dev/k8s-healer/pkg/history"
+	"github.com/daigoro86dev/k8s-healer/pkg/util"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// BenchConfig describes the synthetic load generated by Bench.
+type BenchConfig struct {
+	Pods             int     // Distinct Pods the updates are spread over
+	Events           int     // Pod updates to generate
+	UnhealthyPercent int     // Share of updates showing a crash-looping Pod
+	Rate             float64 // Updates per second; 0 generates them as fast as possible
+	Seed             int64
+}
+
+// BenchResult reports how the decision pipeline coped with the synthetic load.
+type BenchResult struct {
+	Events    int `json:"events"`
+	Processed int `json:"processed"` // Updates checked; merged and dropped updates are never checked
+	Merged    int `json:"merged"`
+	Dropped   int `json:"dropped"`
+	Heals     int `json:"heals"` // Decisions to act on a Pod
+
+	Duration   time.Duration `json:"duration"`
+	Throughput float64       `json:"throughput"` // Checked updates per second
+
+	// Time from an update being queued to its check completing
+	LatencyP50 time.Duration `json:"latencyP50"`
+	LatencyP90 time.Duration `json:"latencyP90"`
+	LatencyP99 time.Duration `json:"latencyP99"`
+	LatencyMax time.Duration `json:"latencyMax"`
+
+	AllocatedBytes uint64 `json:"allocatedBytes"` // Total allocations during the run
+	RetainedBytes  int64  `json:"retainedBytes"`  // Live heap growth after the run, i.e. healer state
+	Goroutines     int    `json:"goroutines"`
+}
+
+// NewBenchHealer returns a Healer without a Kubernetes client, for running Bench. Only the
+// settings used by the decision pipeline (cooldowns, thresholds, actions, conditions, queue) apply.
+func NewBenchHealer() *Healer {
+	return &Healer{
+		StopCh:           make(chan struct{}),
+		Log:              slog.Default(),
+		HealedPods:       newHealCache(),
+		HealCooldown:     10 * time.Minute,
+		HealCacheSize:    DefaultHealCacheSize,
+		RestartThreshold: util.DefaultRestartThreshold,
+		EventReasons:     DefaultEventReasons,
+		EventThreshold:   5,
+		History:          history.NewStore(history.DefaultRetention),
+		events:           newEventSignals(),
+		unhealthy:        newUnhealthyTracker(),
+		policies:         newPolicyStore(),
+		owners:           benchOwners(),
+		DefaultAction:    ActionDelete,
+		QueueSize:        DefaultQueueSize,
+		queue:            newPodQueue(DefaultQueueSize),
+		QueueWorkers:     DefaultQueueWorkers,
+	}
+}
+
+// Bench feeds synthetic Pod updates through the processing queue and the decision pipeline, the
+// way informer updates are processed, without making any API calls: Pods that would be healed are
+// only marked as healed. It is meant for validating performance changes and sizing the healer.
+func (h *Healer) Bench(cfg BenchConfig) BenchResult {
+	if cfg.Pods < 1 {
+		cfg.Pods = 1
+	}
+	healthy, unhealthy := benchPods(cfg.Pods)
+	rnd := rand.New(rand.NewSource(cfg.Seed))
+
+	runtime.GC()
+	var before runtime.MemStats
+	runtime.ReadMemStats(&before)
+
+	var (
+		queuedAt  sync.Map // Pod key -> time its oldest pending update was queued
+		latencyMu sync.Mutex
+		latencies []time.Duration
+		processed atomic.Int64
+		heals     atomic.Int64
+	)
+	h.queue.setCapacity(h.QueueSize)
+	h.startQueueWorkers(func(pod *v1.Pod) {
+		if f := h.evaluatePod(pod); f != nil {
+			if action := h.actionFor(pod, f); action != ActionSkip && action != ActionNotify {
+				h.markHealed(pod, time.Now())
+				heals.Add(1)
+			}
+		}
+		processed.Add(1)
+		if t, ok := queuedAt.LoadAndDelete(pod.Namespace + "/" + pod.Name); ok {
+			latencyMu.Lock()
+			latencies = append(latencies, time.Since(t.(time.Time)))
+			latencyMu.Unlock()
+		}
+	})
+
+	var interval time.Duration
+	if cfg.Rate > 0 {
+		interval = time.Duration(float64(time.Second) / cfg.Rate)
+	}
+	start := time.Now()
+	for i := 0; i < cfg.Events; i++ {
+		if interval > 0 {
+			if wait := time.Until(start.Add(time.Duration(i) * interval)); wait > 0 {
+				time.Sleep(wait)
+			}
+		}
+		n := rnd.Intn(cfg.Pods)
+		pod := healthy[n]
+		if rnd.Intn(100) < cfg.UnhealthyPercent {
+			pod = unhealthy[n]
+		}
+		key := pod.Namespace + "/" + pod.Name
+		_, pending := queuedAt.LoadOrStore(key, time.Now())
+		if !h.queue.add(pod) && !pending {
+			queuedAt.Delete(key)
+		}
+	}
+
+	// Drain: every queued update has been checked once no key is pending and nothing is deferred
+	for {
+		pending := false
+		queuedAt.Range(func(any, any) bool {
+			pending = true
+			return false
+		})
+		if !pending && h.queue.stats().Depth == 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	elapsed := time.Since(start)
+	goroutines := runtime.NumGoroutine()
+	close(h.StopCh)
+
+	var after runtime.MemStats
+	runtime.ReadMemStats(&after)
+	allocated := after.TotalAlloc - before.TotalAlloc
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+	runtime.KeepAlive(healthy)
+	runtime.KeepAlive(unhealthy)
+
+	stats := h.queue.stats()
+	res := BenchResult{
+		Events:         cfg.Events,
+		Processed:      int(processed.Load()),
+		Merged:         int(stats.Merged),
+		Dropped:        int(stats.Dropped),
+		Heals:          int(heals.Load()),
+		Duration:       elapsed,
+		Throughput:     float64(processed.Load()) / elapsed.Seconds(),
+		AllocatedBytes: allocated,
+		RetainedBytes:  int64(after.HeapAlloc) - int64(before.HeapAlloc),
+		Goroutines:     goroutines,
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	if n := len(latencies); n > 0 {
+		res.LatencyP50 = latencies[n*50/100]
+		res.LatencyP90 = latencies[n*90/100]
+		res.LatencyP99 = latencies[n*99/100]
+		res.LatencyMax = latencies[n-1]
+	}
+	return res
+}
+
+// benchPods builds a healthy and a crash-looping variant of each synthetic Pod, spread over ten
+// namespaces and owned by a ReplicaSet, as an informer would deliver them.
+func benchPods(n int) (healthy, unhealthy []*v1.Pod) {
+	created := metav1.NewTime(time.Now().Add(-time.Hour))
+	for i := 0; i < n; i++ {
+		meta := metav1.ObjectMeta{
+			Name:              fmt.Sprintf("bench-%d", i),
+			Namespace:         fmt.Sprintf("bench-%d", i%10),
+			UID:               types.UID(fmt.Sprintf("bench-uid-%d", i)),
+			CreationTimestamp: created,
+			Labels:            map[string]string{"app": fmt.Sprintf("bench-%d", i%100)},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "apps/v1",
+				Kind:       "ReplicaSet",
+				Name:       fmt.Sprintf("bench-%d-5d8f7c9b4", i%100),
+				Controller: boolPtr(true),
+			}},
+		}
+		spec := v1.PodSpec{Containers: []v1.Container{{Name: "app", Image: "bench:latest"}}}
+
+		healthy = append(healthy, &v1.Pod{
+			ObjectMeta: meta,
+			Spec:       spec,
+			Status: v1.PodStatus{
+				Phase: v1.PodRunning,
+				ContainerStatuses: []v1.ContainerStatus{{
+					Name:  "app",
+					Ready: true,
+					State: v1.ContainerState{Running: &v1.ContainerStateRunning{StartedAt: created}},
+				}},
+			},
+		})
+		unhealthy = append(unhealthy, &v1.Pod{
+			ObjectMeta: meta,
+			Spec:       spec,
+			Status: v1.PodStatus{
+				Phase: v1.PodRunning,
+				ContainerStatuses: []v1.ContainerStatus{{
+					Name:         "app",
+					RestartCount: 5,
+					State:        v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+					LastTerminationState: v1.ContainerState{
+						Terminated: &v1.ContainerStateTerminated{ExitCode: 1, Reason: "Error"},
+					},
+				}},
+			},
+		})
+	}
+	return healthy, unhealthy
+}
+
+// benchOwners returns an owner cache serving the ReplicaSets and Deployments owning the bench
+// Pods from pre-filled listers, so owner lookups never reach the (absent) API server.
+func benchOwners() *ownerCache {
+	indexers := cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}
+	replicaSets := cache.NewIndexer(cache.MetaNamespaceKeyFunc, indexers)
+	deployments := cache.NewIndexer(cache.MetaNamespaceKeyFunc, indexers)
+	for i := 0; i < 100; i++ {
+		ns := fmt.Sprintf("bench-%d", i%10)
+		name := fmt.Sprintf("bench-%d", i)
+		d := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: ns,
+			UID:       types.UID("bench-deploy-uid-" + name),
+		}}
+		rs := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+			Name:      name + "-5d8f7c9b4",
+			Namespace: ns,
+			UID:       types.UID("bench-rs-uid-" + name),
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       d.Name,
+				UID:        d.UID,
+				Controller: boolPtr(true),
+			}},
+		}}
+		_ = deployments.Add(d)
+		_ = replicaSets.Add(rs)
+	}
+
+	c := newOwnerCache(nil)
+	c.listers[metav1.NamespaceAll] = &ownerListers{
+		replicaSets:  appslisters.NewReplicaSetLister(replicaSets),
+		deployments:  appslisters.NewDeploymentLister(deployments),
+		statefulSets: appslisters.NewStatefulSetLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, indexers)),
+	}
+	return c
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
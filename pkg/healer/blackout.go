@@ -0,0 +1,103 @@
+package healer
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/daigoro86dev/k8s-healer/pkg/control"
+)
+
+// controlCache holds the most recently loaded control state.
+type controlCache struct {
+	mu    sync.RWMutex
+	state *control.State
+}
+
+func (c *controlCache) get() *control.State {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.state
+}
+
+func (c *controlCache) set(state *control.State) {
+	c.mu.Lock()
+	c.state = state
+	c.mu.Unlock()
+}
+
+// Identity returns the actor name the healer uses in audit trails.
+func (h *Healer) Identity() string {
+	if h.InstanceName != "" {
+		return h.InstanceName
+	}
+	host, _ := os.Hostname()
+	return "k8s-healer/" + host
+}
+
+// startControlPoller periodically reloads the control ConfigMap and removes expired blackouts.
+func (h *Healer) startControlPoller() {
+	if h.Control == nil {
+		return
+	}
+	poll := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		state, err := h.Control.Load(ctx)
+		if err != nil {
+			h.Log.Warn("Failed to load control state", "err", err)
+			return
+		}
+
+		// Expire blackouts in the ConfigMap so the audit trail shows when suppression ended
+		if hasExpiredBlackouts(state, time.Now()) {
+			expired := 0
+			err := h.Control.Update(ctx, func(s *control.State) error {
+				expired = s.PruneExpired(time.Now(), h.Identity())
+				state = s
+				return nil
+			})
+			if err != nil {
+				h.Log.Warn("Failed to expire blackouts", "err", err)
+			} else if expired > 0 {
+				h.Log.Info("Blackouts expired", "expired", expired)
+			}
+		}
+		h.control.set(state)
+	}
+
+	poll()
+	go func() {
+		ticker := time.NewTicker(h.ControlPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				poll()
+			case <-h.StopCh:
+				return
+			}
+		}
+	}()
+}
+
+// hasExpiredBlackouts reports whether the state still lists blackouts that have expired.
+func hasExpiredBlackouts(state *control.State, now time.Time) bool {
+	for _, b := range state.Blackouts {
+		if !b.Active(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// activeBlackout returns the blackout currently suppressing healing in the namespace, or nil.
+func (h *Healer) activeBlackout(namespace string) *control.Blackout {
+	state := h.control.get()
+	if state == nil {
+		return nil
+	}
+	return state.ActiveBlackout(namespace, time.Now())
+}
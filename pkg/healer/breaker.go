@@ -0,0 +1,130 @@
+package healer
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/daigoro86dev/k8s-healer/pkg/notify"
+)
+
+// Circuit breaker notifications.
+const (
+	EventBreakerTripped notify.EventType = "circuit-breaker-tripped"
+	EventBreakerReset   notify.EventType = "circuit-breaker-reset"
+)
+
+// Defaults for BreakerInterval and BreakerCooloff.
+const (
+	DefaultBreakerInterval = 5 * time.Minute
+	DefaultBreakerCooloff  = 30 * time.Minute
+)
+
+// circuitBreaker halts healing cluster-wide once heals pile up: mass failures usually have a cause
+// the healer can't fix (a bad node, a registry outage), and healing through them only adds load.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	heals     []time.Time // Heals within the current interval, oldest first
+	tripped   bool
+	trippedAt time.Time
+	until     time.Time
+}
+
+// state returns why the breaker is open, or "" if it is closed. An open breaker whose cool-off has
+// passed is closed and reported as reset.
+func (b *circuitBreaker) state(now time.Time) (why string, reset bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.tripped {
+		return "", false
+	}
+	if !now.Before(b.until) {
+		b.tripped, b.heals = false, nil
+		return "", true
+	}
+	return fmt.Sprintf("circuit breaker open since %s after a heal storm, until %s or a manual reset",
+		b.trippedAt.Format(time.RFC3339), b.until.Format(time.RFC3339)), false
+}
+
+// record counts a heal and trips the breaker when the interval holds more than threshold heals.
+// It returns the number of heals in the interval if this heal tripped it, or 0.
+func (b *circuitBreaker) record(now time.Time, threshold int, interval, cooloff time.Duration) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	kept := b.heals[:0]
+	for _, at := range b.heals {
+		if now.Sub(at) < interval {
+			kept = append(kept, at)
+		}
+	}
+	b.heals = append(kept, now)
+	if b.tripped || len(b.heals) <= threshold {
+		return 0
+	}
+	b.tripped, b.trippedAt, b.until = true, now, now.Add(cooloff)
+	return len(b.heals)
+}
+
+// reset closes the breaker. It returns false if it wasn't open.
+func (b *circuitBreaker) reset() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	wasTripped := b.tripped
+	b.tripped, b.heals = false, nil
+	return wasTripped
+}
+
+// breakerOpen returns why healing is halted by the circuit breaker, or "" if it may proceed. Pods
+// keep being observed while it is open; their heals are reported as suppressed.
+func (h *Healer) breakerOpen() string {
+	if h.BreakerThreshold <= 0 {
+		return ""
+	}
+	why, reset := h.breaker.state(time.Now())
+	if reset {
+		h.Log.Info("Circuit breaker cool-off passed; healing resumes", "cooloff", h.BreakerCooloff)
+		h.notifyBreaker(EventBreakerReset, notify.SeverityInfo, "cool-off passed", "Healing resumes.")
+	}
+	return why
+}
+
+// recordBreakerHeal counts a heal toward the circuit breaker and raises the alarm if it trips.
+func (h *Healer) recordBreakerHeal() {
+	if h.BreakerThreshold <= 0 {
+		return
+	}
+	n := h.breaker.record(time.Now(), h.BreakerThreshold, h.BreakerInterval, h.BreakerCooloff)
+	if n == 0 {
+		return
+	}
+	reason := fmt.Sprintf("%d heals within %s (threshold %d)", n, h.BreakerInterval, h.BreakerThreshold)
+	h.Log.Error("Circuit breaker tripped; healing is halted and unhealthy pods are only reported", "reason", reason,
+		"cooloff", h.BreakerCooloff, "reset", "POST /control/reset-breaker")
+	h.notifyBreaker(EventBreakerTripped, notify.SeverityCritical, reason,
+		fmt.Sprintf("Mass failures usually have a cause the healer can't fix. Healing is halted for %s or until reset through the control API; unhealthy pods are only reported.", h.BreakerCooloff))
+}
+
+// notifyBreaker sends a cluster-wide circuit breaker notification.
+func (h *Healer) notifyBreaker(typ notify.EventType, severity notify.Severity, reason, message string) {
+	if h.Notifier == nil {
+		return
+	}
+	go h.Notifier.Dispatch(notify.Event{
+		Type:     typ,
+		Severity: severity,
+		Cluster:  h.ClusterName,
+		Reason:   reason,
+		Message:  message,
+		Time:     time.Now(),
+	})
+}
+
+func (h *Healer) resetBreakerOp(_ controlRequest, actor string) (int, interface{}) {
+	if !h.breaker.reset() {
+		return http.StatusOK, map[string]interface{}{"tripped": false}
+	}
+	h.Log.Info("Circuit breaker reset; healing resumes", "actor", actor)
+	h.notifyBreaker(EventBreakerReset, notify.SeverityInfo, "reset by "+actor, "Healing resumes.")
+	return http.StatusOK, map[string]interface{}{"tripped": false, "resetBy": actor}
+}
@@ -0,0 +1,135 @@
+package healer
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// DefaultOwnerHealBudgetWindow is the window OwnerHealBudget is counted over.
+const DefaultOwnerHealBudgetWindow = 10 * time.Minute
+
+// ownerHeals remembers when each owner's Pods were healed, keyed by namespace/Kind/Name.
+type ownerHeals struct {
+	mu    sync.Mutex
+	heals map[string][]time.Time
+}
+
+func newOwnerHeals() *ownerHeals {
+	return &ownerHeals{heals: make(map[string][]time.Time)}
+}
+
+// record notes a heal of one of the owner's Pods.
+func (o *ownerHeals) record(ownerKey string, at time.Time) {
+	o.mu.Lock()
+	o.heals[ownerKey] = append(o.heals[ownerKey], at)
+	o.mu.Unlock()
+}
+
+// since counts the owner's heals after the given time.
+func (o *ownerHeals) since(ownerKey string, since time.Time) int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	n := 0
+	for _, at := range o.heals[ownerKey] {
+		if at.After(since) {
+			n++
+		}
+	}
+	return n
+}
+
+// over lists the owners with at least threshold heals after the given time, with their counts.
+func (o *ownerHeals) over(threshold int, since time.Time) map[string]int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	out := make(map[string]int)
+	for key, heals := range o.heals {
+		n := 0
+		for _, at := range heals {
+			if at.After(since) {
+				n++
+			}
+		}
+		if n >= threshold {
+			out[key] = n
+		}
+	}
+	return out
+}
+
+// forget drops the owner's heals.
+func (o *ownerHeals) forget(ownerKey string) {
+	o.mu.Lock()
+	delete(o.heals, ownerKey)
+	o.mu.Unlock()
+}
+
+// prune forgets heals older than the retention.
+func (o *ownerHeals) prune(now time.Time, retain time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for key, heals := range o.heals {
+		kept := heals[:0]
+		for _, at := range heals {
+			if now.Sub(at) <= retain {
+				kept = append(kept, at)
+			}
+		}
+		if len(kept) == 0 {
+			delete(o.heals, key)
+		} else {
+			o.heals[key] = kept
+		}
+	}
+}
+
+// ownerBudgetExceeded returns why healing another of the owner's Pods would exceed OwnerHealBudget,
+// or "" if it wouldn't. The budget is the fraction of the owner's Pods, counted in the Pod cache,
+// that may be healed per OwnerHealBudgetWindow, and always allows at least one heal.
+func (h *Healer) ownerBudgetExceeded(pod *v1.Pod, owner *OwnerInfo) string {
+	if h.OwnerHealBudget <= 0 || owner == nil {
+		return ""
+	}
+	healed := h.ownerHeals.since(owner.Namespace+"/"+owner.String(), time.Now().Add(-h.OwnerHealBudgetWindow))
+	if healed == 0 {
+		return ""
+	}
+	siblings := h.countSiblings(pod, owner)
+	budget := int(math.Floor(h.OwnerHealBudget * float64(siblings)))
+	if budget < 1 {
+		budget = 1
+	}
+	if healed < budget {
+		return ""
+	}
+	return fmt.Sprintf("%s already had %d of its %d pod(s) healed in the last %s (budget %.0f%%)",
+		owner, healed, siblings, h.OwnerHealBudgetWindow, h.OwnerHealBudget*100)
+}
+
+// countSiblings counts the cached Pods resolving to the same owner, the Pod itself included.
+func (h *Healer) countSiblings(pod *v1.Pod, owner *OwnerInfo) int {
+	n := 0
+	for _, lister := range h.podListers() {
+		pods, err := lister.Pods(pod.Namespace).List(labels.Everything())
+		if err != nil {
+			continue
+		}
+		for _, p := range pods {
+			if p.DeletionTimestamp != nil && p.UID != pod.UID {
+				continue
+			}
+			if o := h.owners.Resolve(p); o != nil && o.Kind == owner.Kind && o.Name == owner.Name {
+				n++
+			}
+		}
+	}
+	if n == 0 {
+		n = 1
+	}
+	return n
+}
@@ -0,0 +1,157 @@
+package healer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+)
+
+// Defaults for checkpointing the heal state.
+const (
+	DefaultStateConfigMapName = "k8s-healer-state"
+	DefaultCheckpointInterval = time.Minute
+)
+
+// cooldownsKey is the state ConfigMap key holding HealedPods.
+const cooldownsKey = "cooldowns"
+
+// maxCheckpointBytes keeps the checkpoint below the 1MiB ConfigMap limit.
+const maxCheckpointBytes = 900 << 10
+
+// restoreCooldowns loads the cooldowns and backoff streaks checkpointed by a previous run, so a
+// restart doesn't re-heal every workload that was cooling down. Entries that would have been
+// pruned by now are dropped.
+func (h *Healer) restoreCooldowns() {
+	if h.StateConfigMap == "" {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cm, err := h.ClientSet.CoreV1().ConfigMaps(h.StateNamespace).Get(ctx, h.StateConfigMap, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return
+	}
+	if err != nil {
+		h.Log.Warn("Failed to restore cooldowns", "configMap", h.StateNamespace+"/"+h.StateConfigMap, "err", err)
+		return
+	}
+	var marks map[string]healMark
+	if raw := cm.Data[cooldownsKey]; raw != "" {
+		if err := json.Unmarshal([]byte(raw), &marks); err != nil {
+			h.Log.Warn("Ignoring unreadable cooldowns", "configMap", h.StateNamespace+"/"+h.StateConfigMap, "err", err)
+			return
+		}
+	}
+
+	restored := h.mergeCooldowns(marks)
+	h.Log.Info("Restored cooldowns", "configMap", h.StateNamespace+"/"+h.StateConfigMap, "cooldowns", restored)
+}
+
+// mergeCooldowns adds restored cooldowns the healer doesn't know yet, or holds an older heal for,
+// and returns how many were added. Entries that would have been pruned by now are dropped.
+func (h *Healer) mergeCooldowns(marks map[string]healMark) int {
+	now := time.Now()
+	restored := 0
+	h.healedMu.Lock()
+	defer h.healedMu.Unlock()
+	for key, mark := range marks {
+		if now.After(mark.Until.Add(h.CooldownReset)) {
+			continue
+		}
+		if known, ok := h.HealedPods.peek(key); !ok || mark.At.After(known.At) {
+			h.HealedPods.set(key, mark)
+			restored++
+		}
+	}
+	h.HealedPods.trim(h.HealCacheSize)
+	return restored
+}
+
+// checkpointCooldowns writes HealedPods to the state ConfigMap, unless it is unchanged since the
+// last checkpoint.
+func (h *Healer) checkpointCooldowns(ctx context.Context) error {
+	marks := h.cooldowns()
+	raw, err := json.Marshal(marks)
+	entries := len(marks)
+	if err != nil {
+		return err
+	}
+	if string(raw) == h.lastCheckpoint {
+		return nil
+	}
+	if len(raw) > maxCheckpointBytes {
+		return fmt.Errorf("%d cooldowns (%d bytes) don't fit in a ConfigMap", entries, len(raw))
+	}
+
+	configMaps := h.ClientSet.CoreV1().ConfigMaps(h.StateNamespace)
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cm, err := configMaps.Get(ctx, h.StateConfigMap, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			cm = &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      h.StateConfigMap,
+					Namespace: h.StateNamespace,
+					Labels:    map[string]string{"app.kubernetes.io/managed-by": "k8s-healer"},
+				},
+				Data: map[string]string{cooldownsKey: string(raw)},
+			}
+			_, err = configMaps.Create(ctx, cm, metav1.CreateOptions{})
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		if cm.Data == nil {
+			cm.Data = make(map[string]string)
+		}
+		cm.Data[cooldownsKey] = string(raw)
+		_, err = configMaps.Update(ctx, cm, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write ConfigMap %s/%s: %w", h.StateNamespace, h.StateConfigMap, err)
+	}
+	h.lastCheckpoint = string(raw)
+	return nil
+}
+
+// startCheckpointer checkpoints the cooldowns to StateConfigMap, and the state to the State
+// database, every CheckpointInterval and once more when the healer stops.
+func (h *Healer) startCheckpointer() {
+	if h.StateConfigMap == "" && h.State == nil {
+		return
+	}
+	checkpoint := func() {
+		if h.StateConfigMap != "" {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			if err := h.checkpointCooldowns(ctx); err != nil {
+				h.Log.Warn("Failed to checkpoint cooldowns", "err", err)
+			}
+		}
+		if h.State != nil {
+			if err := h.saveState(); err != nil {
+				h.Log.Warn("Failed to save state", "err", err)
+			}
+		}
+	}
+	go func() {
+		ticker := time.NewTicker(h.CheckpointInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				checkpoint()
+			case <-h.StopCh:
+				checkpoint()
+				return
+			}
+		}
+	}()
+}
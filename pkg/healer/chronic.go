@@ -0,0 +1,49 @@
+package healer
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// DefaultChronicWindow is the horizon ChronicThreshold heals are counted over.
+const DefaultChronicWindow = 24 * time.Hour
+
+// ChronicFailure is a workload healed so often that healing it was switched to notify-only.
+type ChronicFailure struct {
+	Owner string `json:"owner"` // namespace/Kind/Name
+	Heals int    `json:"heals"` // Heals within the chronic window
+}
+
+// chronicFailure returns why the workload is a chronic failure, or "" if it isn't. Workloads healed
+// ChronicThreshold times within ChronicWindow need a human rather than more heals; they stay
+// notify-only until enough of their heals age out of the window.
+func (h *Healer) chronicFailure(owner *OwnerInfo) string {
+	if h.ChronicThreshold <= 0 || owner == nil {
+		return ""
+	}
+	healed := h.ownerHeals.since(owner.Namespace+"/"+owner.String(), time.Now().Add(-h.ChronicWindow))
+	if healed < h.ChronicThreshold {
+		return ""
+	}
+	return fmt.Sprintf("chronic failure: %s was healed %d times in the last %s and needs human attention",
+		owner, healed, h.ChronicWindow)
+}
+
+// chronicFailures lists the workloads currently treated as chronic failures, most heals first.
+func (h *Healer) chronicFailures() []ChronicFailure {
+	if h.ChronicThreshold <= 0 {
+		return nil
+	}
+	var out []ChronicFailure
+	for owner, heals := range h.ownerHeals.over(h.ChronicThreshold, time.Now().Add(-h.ChronicWindow)) {
+		out = append(out, ChronicFailure{Owner: owner, Heals: heals})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Heals != out[j].Heals {
+			return out[i].Heals > out[j].Heals
+		}
+		return out[i].Owner < out[j].Owner
+	})
+	return out
+}
@@ -0,0 +1,39 @@
+package healer
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+)
+
+// Defaults for ClientOptions. client-go's own defaults (5 QPS, burst 10) throttle the startup
+// listing and heal storms on large clusters.
+const (
+	DefaultKubeAPIQPS   = 20
+	DefaultKubeAPIBurst = 30
+)
+
+// ClientOptions tune the API client of the healer. Zero values keep the client-go defaults.
+type ClientOptions struct {
+	// QPS and Burst bound the rate of requests to the API server, shared by all informers and heals.
+	QPS   float32
+	Burst int
+}
+
+// apply sets the options on the client configuration.
+func (o ClientOptions) apply(config *rest.Config) {
+	if o.QPS > 0 {
+		config.QPS = o.QPS
+	}
+	if o.Burst > 0 {
+		config.Burst = o.Burst
+	}
+}
+
+// useProtobuf makes the clientset talk protobuf to the API server: decoding the informers' list and
+// watch streams is considerably cheaper than JSON on large clusters. JSON stays acceptable as a
+// fallback, and the dynamic client (custom resources, which have no protobuf encoding) keeps using
+// JSON regardless.
+func useProtobuf(config *rest.Config) {
+	config.ContentType = runtime.ContentTypeProtobuf
+	config.AcceptContentTypes = runtime.ContentTypeProtobuf + "," + runtime.ContentTypeJSON
+}
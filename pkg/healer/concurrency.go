@@ -0,0 +1,63 @@
+package healer
+
+import (
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// DefaultHealConcurrency is how many heals may be carried out at once by default.
+const DefaultHealConcurrency = 4
+
+// healSlots coordinates the queue workers and manual heals. A workload is healed by one of them at
+// a time, so two Pods of a ReplicaSet checked by different workers can't both pass its cooldown
+// before either records its heal; and at most HealConcurrency heals are carried out at once, so
+// heals of different workloads run in parallel without flooding the API server.
+type healSlots struct {
+	mu        sync.Mutex
+	workloads map[string]bool // cooldownKeys of the workloads being healed
+
+	init  sync.Once
+	slots chan struct{} // Sized by the first acquire
+}
+
+func newHealSlots() *healSlots {
+	return &healSlots{workloads: make(map[string]bool)}
+}
+
+// claim reserves the Pod's workload. It returns false if the workload is already being healed.
+func (s *healSlots) claim(pod *v1.Pod) bool {
+	key := cooldownKey(pod)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.workloads[key] {
+		return false
+	}
+	s.workloads[key] = true
+	return true
+}
+
+// release gives up the reservation of the Pod's workload.
+func (s *healSlots) release(pod *v1.Pod) {
+	s.mu.Lock()
+	delete(s.workloads, cooldownKey(pod))
+	s.mu.Unlock()
+}
+
+// acquire blocks until fewer than concurrency heals are in progress. It returns false if the
+// healer stops first.
+func (s *healSlots) acquire(concurrency int, stopCh <-chan struct{}) bool {
+	s.init.Do(func() {
+		s.slots = make(chan struct{}, max(concurrency, 1))
+	})
+	select {
+	case s.slots <- struct{}{}:
+		return true
+	case <-stopCh:
+		return false
+	}
+}
+
+func (s *healSlots) done() {
+	<-s.slots
+}
@@ -0,0 +1,458 @@
+package healer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/daigoro86dev/k8s-healer/pkg/notify"
+	"github.com/daigoro86dev/k8s-healer/pkg/schedule"
+	"github.com/daigoro86dev/k8s-healer/pkg/util"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/yaml"
+)
+
+// Config is the YAML configuration file of the healer, an alternative to encoding everything in
+// command-line flags. Unset fields keep their defaults.
+type Config struct {
+	Namespaces        []string `json:"namespaces,omitempty"`        // Names or globs; all namespaces if empty
+	ExcludeNamespaces []string `json:"excludeNamespaces,omitempty"` // Names or globs never watched, like -N
+	NamespaceSelector string   `json:"namespaceSelector,omitempty"` // Label selector choosing namespaces
+
+	// DiscoverNamespaces watches namespaces annotated k8s-healer.io/watch=true, like
+	// --discover-namespaces.
+	DiscoverNamespaces *bool `json:"discoverNamespaces,omitempty"`
+
+	WatchStrategy    string `json:"watchStrategy,omitempty"`    // auto, per-namespace or cluster
+	ClusterThreshold *int   `json:"clusterThreshold,omitempty"` // Namespaces above which auto goes cluster-wide
+
+	// KubeAPIQPS and KubeAPIBurst rate-limit the API client, like --kube-api-qps/--kube-api-burst.
+	KubeAPIQPS   *float32 `json:"kubeAPIQPS,omitempty"`
+	KubeAPIBurst *int     `json:"kubeAPIBurst,omitempty"`
+
+	ClusterName string `json:"clusterName,omitempty"`
+	Mode        string `json:"mode,omitempty"` // opt-out or opt-in, like --mode
+
+	HealCooldown         *metav1.Duration `json:"healCooldown,omitempty"`
+	MaxHealCooldown      *metav1.Duration `json:"maxHealCooldown,omitempty"` // Cap of the cooldown backoff
+	CooldownReset        *metav1.Duration `json:"cooldownReset,omitempty"`   // Healthy time resetting the backoff
+	HealCacheSize        *int             `json:"healCacheSize,omitempty"`   // Workloads whose cooldowns are remembered
+	MinPodAge            *metav1.Duration `json:"minPodAge,omitempty"`
+	MinUnhealthyDuration *metav1.Duration `json:"minUnhealthyDuration,omitempty"`
+	RestartThreshold     *int32           `json:"restartThreshold,omitempty"`
+	MaxHealsPerMinute    *int             `json:"maxHealsPerMinute,omitempty"` // Cluster-wide; 0 is unlimited
+
+	// OwnerHealBudget is the fraction of an owner's Pods healed per OwnerBudgetWindow, like
+	// --owner-heal-budget.
+	OwnerHealBudget   *float64         `json:"ownerHealBudget,omitempty"`
+	OwnerBudgetWindow *metav1.Duration `json:"ownerBudgetWindow,omitempty"`
+
+	// BreakerThreshold trips the circuit breaker on heal storms, like --breaker-threshold.
+	BreakerThreshold *int             `json:"breakerThreshold,omitempty"`
+	BreakerInterval  *metav1.Duration `json:"breakerInterval,omitempty"`
+	BreakerCooloff   *metav1.Duration `json:"breakerCooloff,omitempty"`
+
+	// FlapThreshold quarantines workloads healed this often within FlapWindow, like --flap-threshold.
+	FlapThreshold *int             `json:"flapThreshold,omitempty"`
+	FlapWindow    *metav1.Duration `json:"flapWindow,omitempty"`
+
+	// ChronicThreshold makes workloads healed this often within ChronicWindow notify-only, like
+	// --chronic-threshold.
+	ChronicThreshold *int             `json:"chronicThreshold,omitempty"`
+	ChronicWindow    *metav1.Duration `json:"chronicWindow,omitempty"`
+
+	UnhealthyConditions []string `json:"unhealthyConditions,omitempty"` // CEL expressions
+	LabelSelector       string   `json:"labelSelector,omitempty"`
+	FieldSelector       string   `json:"fieldSelector,omitempty"`
+	OwnerKinds          []string `json:"ownerKinds,omitempty"`
+	EventDetection      *bool    `json:"eventDetection,omitempty"`
+	EventReasons        []string `json:"eventReasons,omitempty"`
+	EventThreshold      *int32   `json:"eventThreshold,omitempty"`
+
+	// Protected lists namespaces and Pods the healer never acts on, in addition to the system
+	// namespaces.
+	Protected ProtectedConfig `json:"protected,omitempty"`
+
+	Action          string            `json:"action,omitempty"`
+	ExitCodeActions map[string]string `json:"exitCodeActions,omitempty"`
+	CheckActions    map[string]string `json:"checkActions,omitempty"`
+	RequireApproval []string          `json:"requireApproval,omitempty"`
+
+	// FreezeCalendarURL lists change-freeze windows, like --freeze-calendar-url.
+	FreezeCalendarURL string `json:"freezeCalendarURL,omitempty"`
+
+	// NoHealWindows are recurring maintenance windows, like --no-heal-window.
+	NoHealWindows []NoHealWindowConfig `json:"noHealWindows,omitempty"`
+
+	// Overrides replace settings for matching namespaces, by name, glob or "re:" regex. The most
+	// specific match wins (an exact name, then the glob with the most literal characters, then
+	// regexes); settings it leaves unset fall through to less specific matches.
+	Overrides []NamespaceOverrideConfig `json:"overrides,omitempty"`
+
+	// Notifications routes notifications to sinks, like the --notify-routes file.
+	Notifications *notify.RoutingConfig `json:"notifications,omitempty"`
+}
+
+// NamespaceOverrideConfig is the configuration file form of a NamespaceOverride.
+type NamespaceOverrideConfig struct {
+	Namespace            string           `json:"namespace"`
+	HealCooldown         *metav1.Duration `json:"healCooldown,omitempty"`
+	MinPodAge            *metav1.Duration `json:"minPodAge,omitempty"`
+	MinUnhealthyDuration *metav1.Duration `json:"minUnhealthyDuration,omitempty"`
+	RestartThreshold     *int32           `json:"restartThreshold,omitempty"`
+	Checks               []string         `json:"checks,omitempty"` // Checks that may heal; all if empty
+	Action               string           `json:"action,omitempty"`
+}
+
+// ProtectedConfig is the configuration file form of the protection lists.
+type ProtectedConfig struct {
+	Namespaces []string `json:"namespaces,omitempty"` // Names or globs
+	Pods       []string `json:"pods,omitempty"`       // Name or namespace/name globs
+}
+
+// NoHealWindowConfig is the configuration file form of a maintenance window.
+type NoHealWindowConfig struct {
+	Schedule string           `json:"schedule"`           // [TZ=<zone>] <cron expression>
+	Duration *metav1.Duration `json:"duration,omitempty"` // Defaults to DefaultNoHealWindowDuration
+}
+
+func (w NoHealWindowConfig) parse() (*schedule.Window, error) {
+	duration := DefaultNoHealWindowDuration
+	if w.Duration != nil {
+		duration = w.Duration.Duration
+	}
+	return schedule.ParseWindow(w.Schedule, duration)
+}
+
+// LoadConfig reads and validates a YAML configuration file. Unknown fields are rejected, so typos
+// don't silently fall back to defaults.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	cfg := &Config{}
+	if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Validate checks the enumerated values and globs of the configuration.
+func (c *Config) Validate() error {
+	if c.Action != "" && !validActions[c.Action] {
+		return fmt.Errorf("invalid action %q", c.Action)
+	}
+	if _, err := util.CompileCELConditions(c.UnhealthyConditions); err != nil {
+		return err
+	}
+	switch c.WatchStrategy {
+	case "", WatchAuto, WatchPerNamespace, WatchCluster:
+	default:
+		return fmt.Errorf("invalid watchStrategy %q (auto, per-namespace or cluster)", c.WatchStrategy)
+	}
+	if c.ClusterThreshold != nil && *c.ClusterThreshold < 0 {
+		return fmt.Errorf("clusterThreshold must not be negative")
+	}
+	if c.MaxHealsPerMinute != nil && *c.MaxHealsPerMinute < 0 {
+		return fmt.Errorf("maxHealsPerMinute must not be negative")
+	}
+	if c.OwnerHealBudget != nil && (*c.OwnerHealBudget < 0 || *c.OwnerHealBudget > 1) {
+		return fmt.Errorf("ownerHealBudget must be between 0 and 1")
+	}
+	if c.MaxHealCooldown != nil && c.MaxHealCooldown.Duration <= 0 {
+		return fmt.Errorf("maxHealCooldown must be positive")
+	}
+	if c.CooldownReset != nil && c.CooldownReset.Duration < 0 {
+		return fmt.Errorf("cooldownReset must not be negative")
+	}
+	if c.HealCacheSize != nil && *c.HealCacheSize <= 0 {
+		return fmt.Errorf("healCacheSize must be positive")
+	}
+	if c.OwnerBudgetWindow != nil && c.OwnerBudgetWindow.Duration <= 0 {
+		return fmt.Errorf("ownerBudgetWindow must be positive")
+	}
+	if c.BreakerThreshold != nil && *c.BreakerThreshold < 0 {
+		return fmt.Errorf("breakerThreshold must not be negative")
+	}
+	if (c.BreakerInterval != nil && c.BreakerInterval.Duration <= 0) || (c.BreakerCooloff != nil && c.BreakerCooloff.Duration <= 0) {
+		return fmt.Errorf("breakerInterval and breakerCooloff must be positive")
+	}
+	if c.FlapThreshold != nil && *c.FlapThreshold < 0 {
+		return fmt.Errorf("flapThreshold must not be negative")
+	}
+	if c.FlapWindow != nil && c.FlapWindow.Duration <= 0 {
+		return fmt.Errorf("flapWindow must be positive")
+	}
+	if c.ChronicThreshold != nil && *c.ChronicThreshold < 0 {
+		return fmt.Errorf("chronicThreshold must not be negative")
+	}
+	if c.ChronicWindow != nil && c.ChronicWindow.Duration <= 0 {
+		return fmt.Errorf("chronicWindow must be positive")
+	}
+	if (c.KubeAPIQPS != nil && *c.KubeAPIQPS <= 0) || (c.KubeAPIBurst != nil && *c.KubeAPIBurst < 1) {
+		return fmt.Errorf("kubeAPIQPS and kubeAPIBurst must be positive")
+	}
+	if err := ValidateOwnerKinds(c.OwnerKinds); err != nil {
+		return fmt.Errorf("invalid ownerKinds: %w", err)
+	}
+	if c.Mode != "" && c.Mode != ModeOptOut && c.Mode != ModeOptIn {
+		return fmt.Errorf("invalid mode %q (opt-out or opt-in)", c.Mode)
+	}
+	if c.RestartThreshold != nil && *c.RestartThreshold < 1 {
+		return fmt.Errorf("restartThreshold must be at least 1")
+	}
+	for _, pattern := range c.Protected.Pods {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid protected pod glob %q: %w", pattern, err)
+		}
+	}
+	for _, pattern := range append(append(append([]string{}, c.Namespaces...), c.ExcludeNamespaces...), c.Protected.Namespaces...) {
+		if err := util.ValidateNamespacePattern(pattern); err != nil {
+			return err
+		}
+	}
+	for _, pattern := range c.RequireApproval {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid namespace glob %q: %w", pattern, err)
+		}
+	}
+	for i, w := range c.NoHealWindows {
+		if _, err := w.parse(); err != nil {
+			return fmt.Errorf("noHealWindow %d: %w", i+1, err)
+		}
+	}
+	for i, o := range c.Overrides {
+		if o.Namespace == "" {
+			return fmt.Errorf("override %d: namespace is required", i+1)
+		}
+		if err := util.ValidateNamespacePattern(o.Namespace); err != nil {
+			return fmt.Errorf("override %d: %w", i+1, err)
+		}
+		if o.Action != "" && !validActions[o.Action] {
+			return fmt.Errorf("override %d: invalid action %q", i+1, o.Action)
+		}
+		if o.RestartThreshold != nil && *o.RestartThreshold < 1 {
+			return fmt.Errorf("override %d: restartThreshold must be at least 1", i+1)
+		}
+		if _, err := parseChecks(o.Checks); err != nil {
+			return fmt.Errorf("override %d: %w", i+1, err)
+		}
+	}
+	if _, err := labels.Parse(c.NamespaceSelector); err != nil {
+		return fmt.Errorf("invalid namespaceSelector: %w", err)
+	}
+	if _, err := labels.Parse(c.LabelSelector); err != nil {
+		return fmt.Errorf("invalid labelSelector: %w", err)
+	}
+	if _, err := fields.ParseSelector(c.FieldSelector); err != nil {
+		return fmt.Errorf("invalid fieldSelector: %w", err)
+	}
+	if _, err := ParseExitCodeActions(c.ExitCodeActions); err != nil {
+		return err
+	}
+	if _, err := ParseCheckActions(c.CheckActions); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Apply sets the configured values on the healer. Namespaces are not applied: they are passed to
+// NewHealer, which watches literal names and matches globs and regexes live; see NewHealerFromConfig.
+func (c *Config) Apply(h *Healer) error {
+	if c.ClusterName != "" {
+		h.ClusterName = c.ClusterName
+	}
+	setDuration(&h.HealCooldown, c.HealCooldown)
+	setDuration(&h.MaxHealCooldown, c.MaxHealCooldown)
+	setDuration(&h.CooldownReset, c.CooldownReset)
+	if c.HealCacheSize != nil {
+		h.HealCacheSize = *c.HealCacheSize
+	}
+	setDuration(&h.MinPodAge, c.MinPodAge)
+	setDuration(&h.MinUnhealthyDuration, c.MinUnhealthyDuration)
+	if c.RestartThreshold != nil {
+		h.RestartThreshold = *c.RestartThreshold
+	}
+	if c.MaxHealsPerMinute != nil {
+		h.MaxHealsPerMinute = *c.MaxHealsPerMinute
+	}
+	if c.OwnerHealBudget != nil {
+		h.OwnerHealBudget = *c.OwnerHealBudget
+	}
+	setDuration(&h.OwnerHealBudgetWindow, c.OwnerBudgetWindow)
+	if c.BreakerThreshold != nil {
+		h.BreakerThreshold = *c.BreakerThreshold
+	}
+	setDuration(&h.BreakerInterval, c.BreakerInterval)
+	setDuration(&h.BreakerCooloff, c.BreakerCooloff)
+	if c.FlapThreshold != nil {
+		h.FlapThreshold = *c.FlapThreshold
+	}
+	setDuration(&h.FlapWindow, c.FlapWindow)
+	if c.ChronicThreshold != nil {
+		h.ChronicThreshold = *c.ChronicThreshold
+	}
+	setDuration(&h.ChronicWindow, c.ChronicWindow)
+
+	if len(c.UnhealthyConditions) > 0 {
+		conditions, err := util.CompileCELConditions(c.UnhealthyConditions)
+		if err != nil {
+			return fmt.Errorf("failed to compile unhealthy conditions: %w", err)
+		}
+		h.CustomConditions = conditions
+	}
+	if c.LabelSelector != "" {
+		h.PodLabelSelector = c.LabelSelector
+	}
+	if c.FieldSelector != "" {
+		h.PodFieldSelector = c.FieldSelector
+	}
+	if len(c.OwnerKinds) > 0 {
+		h.OwnerKinds = c.OwnerKinds
+	}
+	if len(c.Protected.Namespaces) > 0 {
+		h.ProtectedNamespaces = c.Protected.Namespaces
+	}
+	if len(c.Protected.Pods) > 0 {
+		h.ProtectedPods = c.Protected.Pods
+	}
+	if c.EventDetection != nil {
+		h.EventDetection = *c.EventDetection
+	}
+	if len(c.EventReasons) > 0 {
+		h.EventReasons = c.EventReasons
+	}
+	if c.EventThreshold != nil {
+		h.EventThreshold = *c.EventThreshold
+	}
+
+	if c.Action != "" {
+		h.DefaultAction = c.Action
+	}
+	if len(c.ExitCodeActions) > 0 {
+		actions, err := ParseExitCodeActions(c.ExitCodeActions)
+		if err != nil {
+			return err
+		}
+		h.ExitCodeActions = actions
+	}
+	if len(c.CheckActions) > 0 {
+		actions, err := ParseCheckActions(c.CheckActions)
+		if err != nil {
+			return err
+		}
+		h.CheckActions = actions
+	}
+	if len(c.RequireApproval) > 0 {
+		h.ApprovalNamespaces = c.RequireApproval
+	}
+
+	if c.FreezeCalendarURL != "" {
+		h.FreezeCalendarURL = c.FreezeCalendarURL
+	}
+	if c.Mode != "" {
+		h.Mode = c.Mode
+	}
+	if len(c.ExcludeNamespaces) > 0 {
+		h.ExcludedNamespaces = c.ExcludeNamespaces
+	}
+	if c.NamespaceSelector != "" {
+		h.NamespaceSelector = c.NamespaceSelector
+	}
+	if c.DiscoverNamespaces != nil {
+		h.WatchAnnotatedNamespaces = *c.DiscoverNamespaces
+	}
+	if c.WatchStrategy != "" {
+		h.WatchStrategy = c.WatchStrategy
+	}
+	if c.ClusterThreshold != nil {
+		h.ClusterWatchThreshold = *c.ClusterThreshold
+	}
+	if len(c.NoHealWindows) > 0 {
+		h.NoHealWindows = make([]*schedule.Window, 0, len(c.NoHealWindows))
+		for _, cw := range c.NoHealWindows {
+			w, err := cw.parse()
+			if err != nil {
+				return err
+			}
+			h.NoHealWindows = append(h.NoHealWindows, w)
+		}
+	}
+
+	if len(c.Overrides) > 0 {
+		h.NamespaceOverrides = make([]NamespaceOverride, 0, len(c.Overrides))
+		for _, o := range c.Overrides {
+			checks, err := parseChecks(o.Checks)
+			if err != nil {
+				return err
+			}
+			h.NamespaceOverrides = append(h.NamespaceOverrides, NamespaceOverride{
+				Namespace:            o.Namespace,
+				HealCooldown:         durationPtr(o.HealCooldown),
+				MinPodAge:            durationPtr(o.MinPodAge),
+				MinUnhealthyDuration: durationPtr(o.MinUnhealthyDuration),
+				RestartThreshold:     o.RestartThreshold,
+				Checks:               checks,
+				Action:               o.Action,
+			})
+		}
+	}
+
+	if c.Notifications != nil {
+		sinks, err := notify.BuildSinks(c.Notifications)
+		if err != nil {
+			return fmt.Errorf("invalid notifications: %w", err)
+		}
+		router, err := notify.NewRouter(c.Notifications, sinks...)
+		if err != nil {
+			return fmt.Errorf("invalid notifications: %w", err)
+		}
+		h.Notifier = router
+	}
+	return nil
+}
+
+// NewHealerFromConfig creates a healer for the configured namespaces, literal names, globs or "re:"
+// regexes that are matched as namespaces come and go, and applies the rest of the configuration.
+func NewHealerFromConfig(kubeconfigPath string, c *Config) (*Healer, error) {
+	h, err := NewHealer(kubeconfigPath, c.Namespaces, c.clientOptions())
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Apply(h); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// clientOptions returns the API client settings, with the defaults for unset ones.
+func (c *Config) clientOptions() ClientOptions {
+	opts := ClientOptions{QPS: DefaultKubeAPIQPS, Burst: DefaultKubeAPIBurst}
+	if c.KubeAPIQPS != nil {
+		opts.QPS = *c.KubeAPIQPS
+	}
+	if c.KubeAPIBurst != nil {
+		opts.Burst = *c.KubeAPIBurst
+	}
+	return opts
+}
+
+func setDuration(dst *time.Duration, d *metav1.Duration) {
+	if d != nil {
+		*dst = d.Duration
+	}
+}
+
+func durationPtr(d *metav1.Duration) *time.Duration {
+	if d == nil {
+		return nil
+	}
+	return &d.Duration
+}
@@ -0,0 +1,60 @@
+package healer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/daigoro86dev/k8s-healer/pkg/annotations"
+	v1 "k8s.io/api/core/v1"
+)
+
+// DefaultContainerRestartCommand terminates the container's main process so the kubelet restarts
+// only that container, leaving the rest of the Pod (and its sidecars) running.
+var DefaultContainerRestartCommand = []string{"kill", "-TERM", "1"}
+
+// containerRestartTarget returns the container to restart instead of deleting the whole Pod, or ""
+// if container-level healing does not apply. It only applies to multi-container Pods that opted in
+// with the container-restart annotation and whose failing container is running or waiting (e.g. in
+// CrashLoopBackOff).
+func containerRestartTarget(pod *v1.Pod, f *failure) string {
+	if !annotations.IsTrue(pod.Annotations, annotations.ContainerRestart) || len(pod.Spec.Containers) < 2 {
+		return ""
+	}
+	if f.Termination == nil {
+		return ""
+	}
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.Name == f.Termination.Container && (cs.State.Running != nil || cs.State.Waiting != nil) {
+			return cs.Name
+		}
+	}
+	return ""
+}
+
+// restartContainer restarts a single container by signalling its main process via exec. A waiting
+// container (e.g. in CrashLoopBackOff) can't be exec'ed into, and the kubelet restarting it has not
+// helped, so an error is returned and the caller deletes the Pod instead.
+func (h *Healer) restartContainer(ctx context.Context, pod *v1.Pod, container string) error {
+	if !runningContainer(pod, container) {
+		return fmt.Errorf("container %s is not running and can't be exec'ed into", container)
+	}
+
+	command := h.ContainerRestartCommand
+	if len(command) == 0 {
+		command = DefaultContainerRestartCommand
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	_, stderr, err := h.execInContainer(ctx, pod, container, command)
+	if err != nil {
+		h.Log.Error("Failed to restart container", "pod", pod.Namespace+"/"+pod.Name, "container", container,
+			"command", strings.Join(command, " "), "err", err, "stderr", strings.TrimSpace(stderr))
+		return err
+	}
+	h.Log.Info("Restarted container without disrupting the rest of the Pod", "pod", pod.Namespace+"/"+pod.Name, "container", container)
+	return nil
+}
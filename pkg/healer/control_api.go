@@ -0,0 +1,377 @@
+package healer
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/daigoro86dev/k8s-healer/pkg/control"
+	authnv1 "k8s.io/api/authentication/v1"
+	authzv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// checkManual is the check recorded for heals requested through the control API.
+const checkManual = "manual"
+
+// Control API headers. Retrying a request with the same Idempotency-Key replays the original
+// response instead of executing the operation again.
+const (
+	IdempotencyKeyHeader = "Idempotency-Key"
+	replayedHeader       = "Idempotent-Replayed"
+)
+
+// Callers need RBAC to the control resource of the healer's API group: "create" with the operation
+// as resource name to run an operation, "list" to read the operation log. Nothing serves this
+// resource; it only exists for authorization.
+const (
+	ControlAPIGroup    = "k8s-healer.io"
+	ControlAPIResource = "controloperations"
+)
+
+// operationTTL is how long idempotency keys are remembered.
+const operationTTL = 24 * time.Hour
+
+// maxOperations bounds the in-memory operation log.
+const maxOperations = 500
+
+// maxIdempotencyKeys bounds the remembered idempotency keys; the oldest are forgotten first.
+const maxIdempotencyKeys = 10000
+
+// Operation is one control API call, as recorded in the operation log.
+type Operation struct {
+	ID             string          `json:"id"`
+	IdempotencyKey string          `json:"idempotencyKey,omitempty"`
+	Time           time.Time       `json:"time"`
+	Actor          string          `json:"actor"`
+	Operation      string          `json:"operation"`
+	Request        json.RawMessage `json:"request,omitempty"`
+	Status         int             `json:"status"`
+	Response       json.RawMessage `json:"response,omitempty"`
+
+	fingerprint string
+	done        bool
+}
+
+// operationLog remembers recent control API operations by actor and idempotency key, so retries by
+// automated callers are answered with the original result.
+type operationLog struct {
+	mu     sync.Mutex
+	byKey  map[idempotencyKey]*Operation
+	keyed  []*Operation // operations in byKey, oldest first, bounded by maxIdempotencyKeys
+	recent []*Operation // oldest first, bounded by maxOperations
+}
+
+// idempotencyKey identifies an operation for retries. Keys are scoped to the actor, so one caller
+// can't replay another's operation by reusing its key.
+type idempotencyKey struct {
+	actor string
+	key   string
+}
+
+func newOperationLog() *operationLog {
+	return &operationLog{byKey: make(map[idempotencyKey]*Operation)}
+}
+
+// start registers an operation. If the actor already used the idempotency key it returns the
+// existing operation instead, and false.
+func (l *operationLog) start(op *Operation) (*Operation, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if op.IdempotencyKey != "" {
+		key := idempotencyKey{actor: op.Actor, key: op.IdempotencyKey}
+		if existing, ok := l.byKey[key]; ok {
+			return existing, false
+		}
+		l.byKey[key] = op
+		l.keyed = append(l.keyed, op)
+		for len(l.keyed) > maxIdempotencyKeys {
+			delete(l.byKey, idempotencyKey{actor: l.keyed[0].Actor, key: l.keyed[0].IdempotencyKey})
+			l.keyed = l.keyed[1:]
+		}
+	}
+	l.recent = append(l.recent, op)
+	if len(l.recent) > maxOperations {
+		l.recent = l.recent[len(l.recent)-maxOperations:]
+	}
+	return op, true
+}
+
+// finish stores the outcome of an operation.
+func (l *operationLog) finish(op *Operation, status int, response json.RawMessage) {
+	l.mu.Lock()
+	op.Status, op.Response, op.done = status, response, true
+	l.mu.Unlock()
+}
+
+// list returns the recorded operations, oldest first.
+func (l *operationLog) list() []Operation {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	out := make([]Operation, 0, len(l.recent))
+	for _, op := range l.recent {
+		out = append(out, *op)
+	}
+	return out
+}
+
+// prune forgets idempotency keys older than operationTTL.
+func (l *operationLog) prune(now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	kept := l.keyed[:0]
+	for _, op := range l.keyed {
+		if op.done && now.Sub(op.Time) > operationTTL {
+			delete(l.byKey, idempotencyKey{actor: op.Actor, key: op.IdempotencyKey})
+		} else {
+			kept = append(kept, op)
+		}
+	}
+	clear(l.keyed[len(kept):])
+	l.keyed = kept
+}
+
+// pauseState records a pause of all healing requested through the control API.
+type pauseState struct {
+	mu     sync.RWMutex
+	paused bool
+	by     string
+	reason string
+}
+
+func (p *pauseState) get() (by, reason string, paused bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.by, p.reason, p.paused
+}
+
+func (p *pauseState) set(paused bool, by, reason string) {
+	p.mu.Lock()
+	p.paused, p.by, p.reason = paused, by, reason
+	p.mu.Unlock()
+}
+
+// controlRequest is the body accepted by the control API operations.
+type controlRequest struct {
+	Namespace string `json:"namespace,omitempty"`
+	Pod       string `json:"pod,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+
+	// Manual heals may name a workload instead of a Pod, and a strategy instead of the default action
+	Kind     string `json:"kind,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Strategy string `json:"strategy,omitempty"`
+}
+
+// registerControlAPI adds the control operations to the mux:
+//
+//	POST /control/pause           {"reason": "..."}
+//	POST /control/resume
+//	POST /control/reset-breaker
+//	POST /control/clear-cooldown  {"namespace": "prod", "pod": "api-*"}
+//	POST /control/heal            {"namespace": "prod", "pod": "api-7d8f9", "reason": "..."}
+//	POST /control/heal            {"namespace": "prod", "kind": "Deployment", "name": "api", "strategy": "rollout-restart"}
+//	GET  /control/operations
+//
+// Every call must carry a Kubernetes bearer token, authenticated with a TokenReview and authorized
+// with a SubjectAccessReview. Operations are recorded under the authenticated user name in the
+// operation log and, if configured, the control ConfigMap's audit trail.
+func (h *Healer) registerControlAPI(mux *http.ServeMux) {
+	mux.HandleFunc("/control/pause", h.controlOperation("pause", h.pauseOp))
+	mux.HandleFunc("/control/resume", h.controlOperation("resume", h.resumeOp))
+	mux.HandleFunc("/control/reset-breaker", h.controlOperation("reset-breaker", h.resetBreakerOp))
+	mux.HandleFunc("/control/clear-cooldown", h.controlOperation("clear-cooldown", h.clearCooldownOp))
+	mux.HandleFunc("/control/heal", h.controlOperation("manual-heal", h.manualHealOp))
+	mux.HandleFunc("/control/operations", func(w http.ResponseWriter, r *http.Request) {
+		if _, status, err := h.authorizeControl(r, "list", ""); err != nil {
+			writeJSON(w, status, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, h.operations.list())
+	})
+}
+
+// authorizeControl authenticates the caller's bearer token and checks that it may perform verb on
+// the named control operation. It returns the authenticated user name, or the HTTP status to fail with.
+func (h *Healer) authorizeControl(r *http.Request, verb, operation string) (string, int, error) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || strings.TrimSpace(token) == "" {
+		return "", http.StatusUnauthorized, fmt.Errorf("a bearer token is required")
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	review, err := h.ClientSet.AuthenticationV1().TokenReviews().Create(ctx, &authnv1.TokenReview{
+		Spec: authnv1.TokenReviewSpec{Token: strings.TrimSpace(token)},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		h.Log.Warn("Failed to authenticate control API caller", "err", err)
+		return "", http.StatusServiceUnavailable, fmt.Errorf("failed to authenticate: %v", err)
+	}
+	if !review.Status.Authenticated {
+		return "", http.StatusUnauthorized, fmt.Errorf("invalid token")
+	}
+	user := review.Status.User
+
+	extra := make(map[string]authzv1.ExtraValue, len(user.Extra))
+	for k, v := range user.Extra {
+		extra[k] = authzv1.ExtraValue(v)
+	}
+	access, err := h.ClientSet.AuthorizationV1().SubjectAccessReviews().Create(ctx, &authzv1.SubjectAccessReview{
+		Spec: authzv1.SubjectAccessReviewSpec{
+			User:   user.Username,
+			UID:    user.UID,
+			Groups: user.Groups,
+			Extra:  extra,
+			ResourceAttributes: &authzv1.ResourceAttributes{
+				Group:    ControlAPIGroup,
+				Resource: ControlAPIResource,
+				Verb:     verb,
+				Name:     operation,
+			},
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		h.Log.Warn("Failed to authorize control API caller", "user", user.Username, "err", err)
+		return "", http.StatusServiceUnavailable, fmt.Errorf("failed to authorize: %v", err)
+	}
+	if !access.Status.Allowed {
+		return "", http.StatusForbidden, fmt.Errorf("%s may not %s %s.%s %q", user.Username, verb, ControlAPIResource, ControlAPIGroup, operation)
+	}
+	return user.Username, 0, nil
+}
+
+// controlOperation wraps an operation with caller authorization, idempotency and auditing.
+func (h *Healer) controlOperation(name string, fn func(req controlRequest, actor string) (int, interface{})) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "use POST"})
+			return
+		}
+		actor, status, err := h.authorizeControl(r, "create", name)
+		if err != nil {
+			writeJSON(w, status, map[string]string{"error": err.Error()})
+			return
+		}
+		body, err := io.ReadAll(io.LimitReader(r.Body, 64<<10))
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		var req controlRequest
+		if len(body) > 0 {
+			if err := json.Unmarshal(body, &req); err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("invalid request: %v", err)})
+				return
+			}
+		}
+
+		sum := sha256.Sum256(append([]byte(name+"\n"), body...))
+		op, isNew := h.operations.start(&Operation{
+			ID:             newOperationID(),
+			IdempotencyKey: r.Header.Get(IdempotencyKeyHeader),
+			Time:           time.Now().UTC(),
+			Actor:          actor,
+			Operation:      name,
+			Request:        json.RawMessage(body),
+			fingerprint:    hex.EncodeToString(sum[:]),
+		})
+		if !isNew {
+			h.replayOperation(w, op, hex.EncodeToString(sum[:]))
+			return
+		}
+
+		status, result := fn(req, actor)
+		response, _ := json.Marshal(result)
+		h.operations.finish(op, status, response)
+		h.auditOperation(op, string(body), status)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write(response)
+	}
+}
+
+// replayOperation answers a retried request with the result of the original operation.
+func (h *Healer) replayOperation(w http.ResponseWriter, op *Operation, fingerprint string) {
+	h.operations.mu.Lock()
+	done, status, response, original := op.done, op.Status, op.Response, op.fingerprint
+	h.operations.mu.Unlock()
+
+	switch {
+	case original != fingerprint:
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]string{
+			"error": fmt.Sprintf("idempotency key %s was already used for a different request", op.IdempotencyKey)})
+	case !done:
+		w.Header().Set("Retry-After", "1")
+		writeJSON(w, http.StatusConflict, map[string]string{
+			"error": fmt.Sprintf("operation %s with this idempotency key is still in progress", op.ID)})
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set(replayedHeader, "true")
+		w.WriteHeader(status)
+		_, _ = w.Write(response)
+	}
+}
+
+// auditOperation writes the operation to the control ConfigMap's audit trail, if configured.
+func (h *Healer) auditOperation(op *Operation, request string, status int) {
+	h.Log.Info("Control operation", "operation", op.Operation, "actor", op.Actor, "id", op.ID, "status", status, "request", request)
+	if h.Control == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err := h.Control.Update(ctx, func(s *control.State) error {
+		s.AddAudit(op.Actor, op.Operation, fmt.Sprintf("operation %s via %s: HTTP %d %s", op.ID, h.Identity(), status, request))
+		return nil
+	})
+	if err != nil {
+		h.Log.Warn("Failed to audit control operation", "id", op.ID, "err", err)
+	}
+}
+
+func (h *Healer) pauseOp(req controlRequest, actor string) (int, interface{}) {
+	if req.Reason == "" {
+		return http.StatusBadRequest, map[string]string{"error": "reason is required"}
+	}
+	h.paused.set(true, actor, req.Reason)
+	return http.StatusOK, map[string]interface{}{"paused": true, "by": actor, "reason": req.Reason}
+}
+
+func (h *Healer) resumeOp(_ controlRequest, _ string) (int, interface{}) {
+	h.paused.set(false, "", "")
+	return http.StatusOK, map[string]interface{}{"paused": false}
+}
+
+func (h *Healer) clearCooldownOp(req controlRequest, _ string) (int, interface{}) {
+	namespace, pod := req.Namespace, req.Pod
+	if namespace == "" {
+		namespace = "*"
+	}
+	if pod == "" {
+		pod = "*"
+	}
+	return http.StatusOK, map[string]int{"cleared": h.clearCooldowns(namespace, pod)}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// newOperationID returns a short random identifier for control operations.
+func newOperationID() string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
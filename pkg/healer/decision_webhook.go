@@ -0,0 +1,145 @@
+package healer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// DecisionRequest is POSTed to the decision webhook before the healer acts on a Pod.
+type DecisionRequest struct {
+	Healer    string `json:"healer"`
+	Cluster   string `json:"cluster,omitempty"`
+	Namespace string `json:"namespace"`
+	Pod       string `json:"pod"`
+	Owner     string `json:"owner,omitempty"`
+	Check     string `json:"check"`
+	Reason    string `json:"reason"`
+	Action    string `json:"action"`
+	ExitCode  *int32 `json:"exitCode,omitempty"`
+}
+
+// DecisionResponse is the decision webhook's verdict. The webhook may veto the heal, replace its
+// action, or extend the cooldown applied to the Pod.
+type DecisionResponse struct {
+	Allowed  bool   `json:"allowed"`
+	Action   string `json:"action,omitempty"`   // Replacement action (e.g. "notify")
+	Cooldown string `json:"cooldown,omitempty"` // Cooldown to apply instead of the default (e.g. "1h")
+	Reason   string `json:"reason,omitempty"`
+}
+
+// decisionVerdict is the healer-side interpretation of a decision webhook call.
+type decisionVerdict struct {
+	allowed  bool
+	action   string
+	cooldown time.Duration
+	reason   string
+}
+
+// consultDecisionWebhook asks the configured webhook whether the proposed heal may proceed.
+// Without a webhook, or when it fails and DecisionWebhookFailOpen is set, the heal proceeds unchanged.
+func (h *Healer) consultDecisionWebhook(ctx context.Context, pod *v1.Pod, owner *OwnerInfo, f *failure, action string) decisionVerdict {
+	verdict := decisionVerdict{allowed: true, action: action}
+	if h.DecisionWebhookURL == "" {
+		return verdict
+	}
+
+	req := DecisionRequest{
+		Healer:    h.Identity(),
+		Cluster:   h.ClusterName,
+		Namespace: pod.Namespace,
+		Pod:       pod.Name,
+		Owner:     owner.String(),
+		Check:     f.Check,
+		Reason:    f.Reason,
+		Action:    action,
+	}
+	if f.Termination != nil {
+		code := f.Termination.ExitCode
+		req.ExitCode = &code
+	}
+
+	resp, err := h.callDecisionWebhook(ctx, req)
+	if err != nil {
+		if h.DecisionWebhookFailOpen {
+			h.Log.Warn("Decision webhook failed; proceeding (fail-open)", "err", err)
+			return verdict
+		}
+		return decisionVerdict{allowed: false, reason: fmt.Sprintf("decision webhook unavailable: %v", err)}
+	}
+
+	verdict.allowed = resp.Allowed
+	verdict.reason = resp.Reason
+	if resp.Action != "" {
+		if !validActions[resp.Action] {
+			return decisionVerdict{allowed: false, reason: fmt.Sprintf("decision webhook returned unknown action %q", resp.Action)}
+		}
+		verdict.action = resp.Action
+	}
+	if resp.Cooldown != "" {
+		if d, err := time.ParseDuration(resp.Cooldown); err == nil {
+			verdict.cooldown = d
+		} else {
+			h.Log.Warn("Ignoring invalid cooldown from decision webhook", "cooldown", resp.Cooldown)
+		}
+	}
+	return verdict
+}
+
+// consultDecision consults the decision webhook about a heal and returns its verdict, along with
+// why the heal must not happen if the webhook vetoed it or changed its action to skip or notify. A
+// refused heal is held off for the webhook's cooldown; an allowed one gets it once it is carried out.
+func (h *Healer) consultDecision(ctx context.Context, pod *v1.Pod, owner *OwnerInfo, f *failure, action string) (decisionVerdict, string) {
+	verdict := h.consultDecisionWebhook(ctx, pod, owner, f, action)
+	var why string
+	switch {
+	case !verdict.allowed:
+		why = fmt.Sprintf("vetoed by decision webhook: %s", verdict.reason)
+	case verdict.action == ActionSkip || verdict.action == ActionNotify:
+		why = fmt.Sprintf("decision webhook changed the action to %s: %s", verdict.action, verdict.reason)
+	default:
+		return verdict, ""
+	}
+	if verdict.cooldown > 0 {
+		h.holdOff(pod, verdict.cooldown)
+	}
+	return verdict, why
+}
+
+func (h *Healer) callDecisionWebhook(ctx context.Context, req DecisionRequest) (*DecisionResponse, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, h.DecisionWebhookTimeout)
+	defer cancel()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, h.DecisionWebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	httpResp, err := webhookClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode < 200 || httpResp.StatusCode > 299 {
+		return nil, fmt.Errorf("unexpected status %s", httpResp.Status)
+	}
+
+	resp := &DecisionResponse{}
+	if err := json.NewDecoder(io.LimitReader(httpResp.Body, 1<<20)).Decode(resp); err != nil {
+		return nil, fmt.Errorf("malformed response: %w", err)
+	}
+	return resp, nil
+}
@@ -0,0 +1,146 @@
+package healer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/daigoro86dev/k8s-healer/pkg/annotations"
+	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// healDeleteOptions returns the delete options for heals, applying the configured grace period and
+// propagation policy.
+func (h *Healer) healDeleteOptions() metav1.DeleteOptions {
+	return metav1.DeleteOptions{
+		GracePeriodSeconds: h.HealGracePeriodSeconds,
+		PropagationPolicy:  h.HealPropagationPolicy,
+	}
+}
+
+// ParsePropagationPolicy validates a deletion propagation policy name. An empty name yields nil,
+// leaving the choice to the API server.
+func ParsePropagationPolicy(name string) (*metav1.DeletionPropagation, error) {
+	var policy metav1.DeletionPropagation
+	switch strings.ToLower(name) {
+	case "":
+		return nil, nil
+	case "background":
+		policy = metav1.DeletePropagationBackground
+	case "foreground":
+		policy = metav1.DeletePropagationForeground
+	case "orphan":
+		policy = metav1.DeletePropagationOrphan
+	default:
+		return nil, fmt.Errorf("invalid propagation policy %q (expected background, foreground or orphan)", name)
+	}
+	return &policy, nil
+}
+
+// preconditionUID makes the delete apply only to the observed Pod. A Pod recreated under the same
+// name between observation and action, as StatefulSet Pods are, has a new UID and is left alone.
+func preconditionUID(pod *v1.Pod, opts *metav1.DeleteOptions) {
+	if pod.UID != "" && opts.Preconditions == nil {
+		uid := pod.UID
+		opts.Preconditions = &metav1.Preconditions{UID: &uid}
+	}
+}
+
+// staleUID wraps the conflict returned when a UID precondition fails.
+func staleUID(pod *v1.Pod, err error) error {
+	if apierrors.IsConflict(err) {
+		return fmt.Errorf("pod was recreated since it was observed (UID %s), leaving the new one alone: %w", pod.UID, err)
+	}
+	return err
+}
+
+// deletePod deletes the Pod with the given options. Every delete the healer issues goes through here
+// so force deletions (grace period zero) are guarded against data-sensitive Pods, and only the
+// observed Pod, by UID, is deleted.
+func (h *Healer) deletePod(ctx context.Context, pod *v1.Pod, opts metav1.DeleteOptions) error {
+	preconditionUID(pod, &opts)
+	h.guardForceDelete(ctx, pod, &opts)
+	return staleUID(pod, h.ClientSet.CoreV1().Pods(pod.Namespace).Delete(ctx, pod.Name, opts))
+}
+
+// evictPod removes the Pod through the policy/v1 Eviction API, so PodDisruptionBudgets are honored.
+// An eviction refused because it would violate a budget is returned as an error. Like deletePod, it
+// only evicts the observed Pod and guards force evictions.
+func (h *Healer) evictPod(ctx context.Context, pod *v1.Pod, opts metav1.DeleteOptions) error {
+	preconditionUID(pod, &opts)
+	h.guardForceDelete(ctx, pod, &opts)
+	eviction := &policyv1.Eviction{
+		ObjectMeta:    metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace},
+		DeleteOptions: &opts,
+	}
+	err := h.ClientSet.PolicyV1().Evictions(pod.Namespace).Evict(ctx, eviction)
+	if apierrors.IsTooManyRequests(err) {
+		return fmt.Errorf("eviction blocked by a PodDisruptionBudget: %w", err)
+	}
+	return staleUID(pod, err)
+}
+
+// guardForceDelete drops a grace period of zero from opts if force-deleting the Pod is risky, so
+// the Pod's own grace period applies.
+func (h *Healer) guardForceDelete(ctx context.Context, pod *v1.Pod, opts *metav1.DeleteOptions) {
+	if opts.GracePeriodSeconds == nil || *opts.GracePeriodSeconds != 0 {
+		return
+	}
+	if why := h.forceDeleteRisk(ctx, pod); why != "" {
+		h.Log.Warn("Not force-deleting pod; using its grace period instead", "pod", pod.Namespace+"/"+pod.Name, "why", why,
+			"allowWith", annotations.AllowForceDelete+"=true")
+		opts.GracePeriodSeconds = nil
+	}
+}
+
+// forceDeleteRisk explains why force-deleting the Pod could corrupt data, or returns "" if it is
+// safe or the workload opted in. Force deletion removes the Pod from the API before its containers
+// have stopped, so a replacement can start while the old Pod still writes to the same volume or,
+// for StatefulSets, run with the same identity.
+func (h *Healer) forceDeleteRisk(ctx context.Context, pod *v1.Pod) string {
+	if annotations.IsTrue(pod.Annotations, annotations.AllowForceDelete) {
+		return ""
+	}
+	owner := h.owners.Resolve(pod)
+	if owner != nil {
+		var workload map[string]string
+		switch {
+		case owner.Deployment != nil:
+			workload = owner.Deployment.Annotations
+		case owner.StatefulSet != nil:
+			workload = owner.StatefulSet.Annotations
+		case owner.ReplicaSet != nil:
+			workload = owner.ReplicaSet.Annotations
+		}
+		if annotations.IsTrue(workload, annotations.AllowForceDelete) {
+			return ""
+		}
+	}
+
+	var risks []string
+	if owner != nil && owner.Kind == "StatefulSet" {
+		risks = append(risks, "owned by a StatefulSet")
+	}
+	for _, vol := range pod.Spec.Volumes {
+		if vol.PersistentVolumeClaim == nil {
+			continue
+		}
+		name := vol.PersistentVolumeClaim.ClaimName
+		pvc, err := h.ClientSet.CoreV1().PersistentVolumeClaims(pod.Namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			// Can't tell the access mode; err on the side of the data.
+			risks = append(risks, fmt.Sprintf("mounts PVC %s", name))
+			continue
+		}
+		for _, mode := range pvc.Spec.AccessModes {
+			if mode == v1.ReadWriteOnce || mode == v1.ReadWriteOncePod {
+				risks = append(risks, fmt.Sprintf("mounts %s PVC %s", mode, name))
+				break
+			}
+		}
+	}
+	return strings.Join(risks, ", ")
+}
@@ -0,0 +1,55 @@
+package healer
+
+import (
+	"context"
+	"time"
+
+	"github.com/daigoro86dev/k8s-healer/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// checkDisrupted is the check name for Pods terminated by preemption or node shutdown.
+const checkDisrupted = "disrupted"
+
+// ActionCleanup removes a Pod that is already dead and replaced; it is not a heal.
+const ActionCleanup = "cleanup"
+
+// cleanupDisruptedPod deletes a managed Pod left in Failed state by preemption or a node shutdown.
+// Its controller has already replaced it, so this is housekeeping: it is recorded with its own
+// reason and never counted as a crash loop or subject to the heal cooldown, but like heals it waits
+// while healing is halted or the heal rate limit is reached. It returns true if the Pod was
+// identified as disrupted.
+func (h *Healer) cleanupDisruptedPod(pod *v1.Pod) bool {
+	reason, disrupted := util.DisruptionReason(pod)
+	if !disrupted {
+		return false
+	}
+	if !h.CleanupDisruptedPods || len(pod.OwnerReferences) == 0 || pod.DeletionTimestamp != nil {
+		return true
+	}
+	if ok, _ := h.apiAllows(actionHousekeeping); !ok {
+		return true
+	}
+	if cause, why := h.halted(pod.Namespace); cause != "" {
+		h.Log.Debug("Not deleting disrupted pod: healing is halted", "pod", pod.Namespace+"/"+pod.Name, "why", why)
+		return true
+	}
+	if !h.healAllowed() {
+		h.Log.Debug("Deferring deletion of disrupted pod: heal rate limit reached", "pod", pod.Namespace+"/"+pod.Name,
+			"maxHealsPerMinute", h.MaxHealsPerMinute)
+		return true
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	err := h.deletePod(ctx, pod, metav1.DeleteOptions{})
+	if err != nil {
+		h.Log.Error("Failed to delete disrupted pod", "pod", pod.Namespace+"/"+pod.Name, "err", err)
+	} else {
+		h.Log.Info("Deleted disrupted pod", "pod", pod.Namespace+"/"+pod.Name, "reason", reason)
+	}
+	h.recordHeal(pod, &failure{Check: checkDisrupted, Reason: reason}, ActionCleanup, err)
+	return true
+}
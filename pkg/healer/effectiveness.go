@@ -0,0 +1,150 @@
+package healer
+
+import (
+	"sync"
+	"time"
+
+	"github.com/daigoro86dev/k8s-healer/pkg/history"
+	v1 "k8s.io/api/core/v1"
+)
+
+// DefaultEffectivenessWindow is how long a healed workload must stay healthy for the heal to count as effective.
+const DefaultEffectivenessWindow = 15 * time.Minute
+
+// pendingHeal is a successful heal whose outcome is not known yet.
+type pendingHeal struct {
+	at        time.Time
+	namespace string
+	pod       string
+	check     string
+}
+
+// effectivenessTracker follows successful heals until their outcome is known: a heal relapses when
+// the same owner fails again within the window, and is effective once the window passes without.
+type effectivenessTracker struct {
+	mu      sync.Mutex
+	pending map[string][]pendingHeal // keyed by namespace/owner
+}
+
+func newEffectivenessTracker() *effectivenessTracker {
+	return &effectivenessTracker{pending: make(map[string][]pendingHeal)}
+}
+
+// track starts following a heal.
+func (t *effectivenessTracker) track(ownerKey string, heal pendingHeal) {
+	t.mu.Lock()
+	t.pending[ownerKey] = append(t.pending[ownerKey], heal)
+	t.mu.Unlock()
+}
+
+// relapse takes the owner's pending heals that the failing Pod was created after. A Pod that
+// predates a heal, such as a sibling that was already failing, says nothing about the heal.
+func (t *effectivenessTracker) relapse(ownerKey string, pod *v1.Pod) []pendingHeal {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var relapsed, kept []pendingHeal
+	for _, heal := range t.pending[ownerKey] {
+		if heal.pod != pod.Name && createdSince(pod, heal.at) {
+			relapsed = append(relapsed, heal)
+		} else {
+			kept = append(kept, heal)
+		}
+	}
+	if len(kept) == 0 {
+		delete(t.pending, ownerKey)
+	} else {
+		t.pending[ownerKey] = kept
+	}
+	return relapsed
+}
+
+// forget drops the owner's heal at the given time, whose outcome is already known.
+func (t *effectivenessTracker) forget(ownerKey string, at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	kept := t.pending[ownerKey][:0]
+	for _, heal := range t.pending[ownerKey] {
+		if !heal.at.Equal(at) {
+			kept = append(kept, heal)
+		}
+	}
+	if len(kept) == 0 {
+		delete(t.pending, ownerKey)
+	} else {
+		t.pending[ownerKey] = kept
+	}
+}
+
+// settle takes the heals whose window passed without a relapse.
+func (t *effectivenessTracker) settle(now time.Time, window time.Duration) []pendingHeal {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var settled []pendingHeal
+	for key, heals := range t.pending {
+		kept := heals[:0]
+		for _, heal := range heals {
+			if now.Sub(heal.at) >= window {
+				settled = append(settled, heal)
+			} else {
+				kept = append(kept, heal)
+			}
+		}
+		if len(kept) == 0 {
+			delete(t.pending, key)
+		} else {
+			t.pending[key] = kept
+		}
+	}
+	return settled
+}
+
+// trackEffectiveness starts following a successful heal of an owned Pod.
+func (h *Healer) trackEffectiveness(owner *OwnerInfo, rec history.Record) {
+	if owner == nil || rec.Result != "success" {
+		return
+	}
+	h.effectiveness.track(owner.Namespace+"/"+owner.String(), pendingHeal{
+		at: rec.Time, namespace: rec.Namespace, pod: rec.Pod, check: rec.Check,
+	})
+}
+
+// observeRelapse marks the owner's recent heals as relapsed because a Pod created after them
+// failed again.
+func (h *Healer) observeRelapse(pod *v1.Pod, owner *OwnerInfo, f *failure) {
+	if owner == nil {
+		return
+	}
+	key := owner.Namespace + "/" + owner.String()
+	relapsed := h.effectiveness.relapse(key, pod)
+	if len(relapsed) == 0 {
+		return
+	}
+	// A relapsing replacement settles the heal; it no longer needs to become Ready
+	h.recoveries.forget(key)
+	for _, heal := range relapsed {
+		h.setOutcome(heal.at, heal.namespace, heal.pod, history.OutcomeRelapsed)
+		h.Log.Info("Heal relapsed: workload failed again", "pod", heal.namespace+"/"+heal.pod, "check", heal.check,
+			"owner", owner.String(), "reason", f.Reason)
+	}
+}
+
+// startEffectivenessTracker settles heals whose window passed without a relapse.
+func (h *Healer) startEffectivenessTracker() {
+	ticker := time.NewTicker(time.Minute)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C:
+				for _, heal := range h.effectiveness.settle(now, h.EffectivenessWindow) {
+					h.setOutcome(heal.at, heal.namespace, heal.pod, history.OutcomeEffective)
+				}
+			case <-h.StopCh:
+				return
+			}
+		}
+	}()
+}
@@ -0,0 +1,115 @@
+package healer
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// DefaultEventReasons are the Warning event reasons that feed the healing decision by default.
+var DefaultEventReasons = []string{"BackOff", "Unhealthy", "FailedScheduling", "FailedMount"}
+
+// eventSignalTTL is how long an event signal stays relevant after it was last seen.
+const eventSignalTTL = 15 * time.Minute
+
+// eventSignal aggregates the Warning events with one reason observed for one Pod.
+type eventSignal struct {
+	UID      types.UID
+	Reason   string
+	Message  string
+	Count    int32
+	LastSeen time.Time
+}
+
+// eventSignals stores the Warning events seen per Pod, so failure classes that never show up in
+// container status (scheduling, volume mounts, probe failures) can contribute to the decision.
+type eventSignals struct {
+	mu    sync.Mutex
+	byPod map[string]map[string]*eventSignal // pod key -> reason -> signal
+}
+
+func newEventSignals() *eventSignals {
+	return &eventSignals{byPod: make(map[string]map[string]*eventSignal)}
+}
+
+// record stores the event and returns the key of the Pod it refers to.
+func (s *eventSignals) record(ev *v1.Event) string {
+	podKey := fmt.Sprintf("%s/%s", ev.InvolvedObject.Namespace, ev.InvolvedObject.Name)
+
+	count := ev.Count
+	if ev.Series != nil && ev.Series.Count > count {
+		count = ev.Series.Count
+	}
+	if count < 1 {
+		count = 1
+	}
+
+	lastSeen := ev.LastTimestamp.Time
+	if ev.Series != nil && ev.Series.LastObservedTime.After(lastSeen) {
+		lastSeen = ev.Series.LastObservedTime.Time
+	}
+	if lastSeen.IsZero() {
+		lastSeen = time.Now()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reasons, ok := s.byPod[podKey]
+	if !ok {
+		reasons = make(map[string]*eventSignal)
+		s.byPod[podKey] = reasons
+	}
+	reasons[ev.Reason] = &eventSignal{
+		UID:      ev.InvolvedObject.UID,
+		Reason:   ev.Reason,
+		Message:  ev.Message,
+		Count:    count,
+		LastSeen: lastSeen,
+	}
+	return podKey
+}
+
+// strongest returns the recent signal with the highest count among the given reasons for the Pod.
+func (s *eventSignals) strongest(pod *v1.Pod, reasons []string) *eventSignal {
+	podKey := fmt.Sprintf("%s/%s", pod.Namespace, pod.Name)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var best *eventSignal
+	for _, reason := range reasons {
+		sig, ok := s.byPod[podKey][reason]
+		if !ok || time.Since(sig.LastSeen) > eventSignalTTL {
+			continue
+		}
+		// Ignore events about a previous Pod that happened to have the same name
+		if sig.UID != "" && sig.UID != pod.UID {
+			continue
+		}
+		if best == nil || sig.Count > best.Count {
+			best = sig
+		}
+	}
+	return best
+}
+
+// prune drops signals that have not been refreshed within the TTL.
+func (s *eventSignals) prune(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for podKey, reasons := range s.byPod {
+		for reason, sig := range reasons {
+			if now.Sub(sig.LastSeen) > eventSignalTTL {
+				delete(reasons, reason)
+			}
+		}
+		if len(reasons) == 0 {
+			delete(s.byPod, podKey)
+		}
+	}
+}
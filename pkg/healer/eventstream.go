@@ -0,0 +1,77 @@
+package healer
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	v1 "k8s.io/api/core/v1"
+)
+
+// Decisions reported on the event stream.
+const (
+	DecisionHeal = "heal" // A healing action was carried out
+	DecisionSkip = "skip" // An unhealthy Pod was left alone
+)
+
+// StreamEvent is one heal decision, written to the event stream as a line of JSON.
+type StreamEvent struct {
+	Time      time.Time `json:"time"`
+	Decision  string    `json:"decision"`
+	Namespace string    `json:"namespace"`
+	Pod       string    `json:"pod"`
+	Owner     string    `json:"owner,omitempty"`
+	Check     string    `json:"check,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+	Action    string    `json:"action,omitempty"`
+	Result    string    `json:"result,omitempty"` // success or failure, for heals
+	Error     string    `json:"error,omitempty"`
+	Cause     string    `json:"cause,omitempty"` // Why the heal was skipped, as in k8s_healer_skips_total
+}
+
+// EventStream writes heal decisions as newline-delimited JSON, for tools to consume apart from the
+// logs.
+type EventStream struct {
+	mu     sync.Mutex
+	enc    *json.Encoder
+	failed bool // A write failed; reported once
+}
+
+// NewEventStream returns an event stream writing to w.
+func NewEventStream(w io.Writer) *EventStream {
+	return &EventStream{enc: json.NewEncoder(w)}
+}
+
+// Write writes one event. Errors are logged once rather than failing the heal.
+func (s *EventStream) Write(ev StreamEvent) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.enc.Encode(ev); err != nil && !s.failed {
+		s.failed = true
+		slog.Warn("Failed to write to the event stream; further errors are not reported", "err", err)
+	}
+}
+
+// recordSkip counts an unhealthy Pod that wasn't healed, notes the cause on the heal's span and
+// reports it on the event stream.
+func (h *Healer) recordSkip(ctx context.Context, pod *v1.Pod, f *failure, cause string) {
+	h.countSkip(cause)
+	trace.SpanFromContext(ctx).SetAttributes(attrSkipCause.String(cause))
+	h.EventStream.Write(StreamEvent{
+		Time:      time.Now(),
+		Decision:  DecisionSkip,
+		Namespace: pod.Namespace,
+		Pod:       pod.Name,
+		Owner:     h.owners.Resolve(pod).String(),
+		Check:     f.Check,
+		Reason:    f.Reason,
+		Cause:     cause,
+	})
+}
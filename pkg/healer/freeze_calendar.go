@@ -0,0 +1,90 @@
+package healer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/daigoro86dev/k8s-healer/pkg/freeze"
+)
+
+// DefaultFreezeCalendarRefresh is how often the freeze calendar is reloaded.
+const DefaultFreezeCalendarRefresh = 5 * time.Minute
+
+// freezeCache holds the windows of the last successfully loaded freeze calendar.
+type freezeCache struct {
+	mu      sync.RWMutex
+	windows []freeze.Window
+	skipped string // Events skipped in the last load, to report them only when they change
+}
+
+func (c *freezeCache) get() []freeze.Window {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.windows
+}
+
+func (c *freezeCache) set(windows []freeze.Window) {
+	c.mu.Lock()
+	c.windows = windows
+	c.mu.Unlock()
+}
+
+// startFreezeCalendar periodically reloads the freeze calendar. When a reload fails the windows
+// loaded last stay in effect, so an unreachable calendar doesn't lift a freeze. Events that can't be
+// used are skipped with a warning.
+func (h *Healer) startFreezeCalendar() {
+	if h.FreezeCalendarURL == "" {
+		return
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	load := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		windows, skipped, err := freeze.Fetch(ctx, client, h.FreezeCalendarURL)
+		if err != nil {
+			h.Log.Warn("Failed to load freeze calendar; keeping the windows loaded before", "err", err, "windows", len(h.freezes.get()))
+			return
+		}
+		if report := fmt.Sprint(skipped); report != h.freezes.skipped {
+			h.freezes.skipped = report
+			for _, err := range skipped {
+				h.Log.Warn("Skipping freeze calendar event", "err", err)
+			}
+		}
+		if len(windows) != len(h.freezes.get()) {
+			h.Log.Info("Loaded freeze calendar", "windows", len(windows))
+		}
+		h.freezes.set(windows)
+	}
+
+	load()
+	go func() {
+		ticker := time.NewTicker(h.FreezeCalendarRefresh)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				load()
+			case <-h.StopCh:
+				return
+			}
+		}
+	}()
+}
+
+// activeFreeze returns the calendar freeze window currently covering the namespace, or nil.
+func (h *Healer) activeFreeze(namespace string) *freeze.Window {
+	return freeze.ActiveWindow(h.freezes.get(), namespace, time.Now())
+}
+
+// describeFreeze formats a freeze window for suppression reasons.
+func describeFreeze(w *freeze.Window) string {
+	summary := w.Summary
+	if summary == "" {
+		summary = "change freeze"
+	}
+	return fmt.Sprintf("calendar freeze %q until %s", summary, w.End.Format(time.RFC3339))
+}
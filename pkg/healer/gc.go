@@ -0,0 +1,88 @@
+package healer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/daigoro86dev/k8s-healer/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// completedPodTTL returns the garbage collection TTL for completed Pods in the namespace.
+// Per-namespace entries (exact names or globs) override the global CompletedPodTTL; 0 disables GC.
+// The most specific matching entry wins, as for namespace overrides; equally specific globs are
+// tried in lexical order.
+func (h *Healer) completedPodTTL(namespace string) time.Duration {
+	if pattern, ok := util.MostSpecificGlob(h.CompletedPodTTLOverrides, namespace); ok {
+		return h.CompletedPodTTLOverrides[pattern]
+	}
+	return h.CompletedPodTTL
+}
+
+// completionTime returns when the Pod finished: the latest container termination, falling back
+// to its start or creation time.
+func completionTime(pod *v1.Pod) time.Time {
+	var finished time.Time
+	for _, cs := range pod.Status.ContainerStatuses {
+		if t := cs.State.Terminated; t != nil && t.FinishedAt.After(finished) {
+			finished = t.FinishedAt.Time
+		}
+	}
+	if !finished.IsZero() {
+		return finished
+	}
+	if pod.Status.StartTime != nil {
+		return pod.Status.StartTime.Time
+	}
+	return pod.CreationTimestamp.Time
+}
+
+// sweepCompletedPod deletes a Succeeded/Failed Pod once it is older than the namespace's TTL. Like
+// heals, deletes wait while healing is halted or the heal rate limit is reached. It returns true if
+// the Pod is completed, in which case no further health evaluation applies.
+func (h *Healer) sweepCompletedPod(pod *v1.Pod) bool {
+	if pod.Status.Phase != v1.PodSucceeded && pod.Status.Phase != v1.PodFailed {
+		return false
+	}
+
+	ttl := h.completedPodTTL(pod.Namespace)
+	if ttl <= 0 || pod.DeletionTimestamp != nil {
+		return true
+	}
+	// Job Pods are cleaned up together with their Job (ttlSecondsAfterFinished)
+	if ref := metav1.GetControllerOf(pod); ref != nil && ref.Kind == "Job" {
+		return true
+	}
+
+	age := time.Since(completionTime(pod))
+	if age < ttl {
+		return true
+	}
+	if ok, _ := h.apiAllows(actionHousekeeping); !ok {
+		return true
+	}
+	if cause, why := h.halted(pod.Namespace); cause != "" {
+		h.Log.Debug("Not deleting completed pod: healing is halted", "pod", pod.Namespace+"/"+pod.Name, "why", why)
+		return true
+	}
+	if !h.healAllowed() {
+		h.Log.Debug("Deferring deletion of completed pod: heal rate limit reached", "pod", pod.Namespace+"/"+pod.Name,
+			"maxHealsPerMinute", h.MaxHealsPerMinute)
+		return true
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	err := h.deletePod(ctx, pod, metav1.DeleteOptions{})
+	h.auditAction(pod, "", fmt.Sprintf("%s for %s (TTL %s)", pod.Status.Phase, age.Round(time.Second), ttl), ActionDelete, err)
+	if err != nil {
+		h.Log.Error("Failed to delete completed pod", "pod", pod.Namespace+"/"+pod.Name, "err", err)
+	} else {
+		h.Log.Info("Deleted completed pod", "pod", pod.Namespace+"/"+pod.Name, "phase", string(pod.Status.Phase),
+			"completedAgo", age.Round(time.Second), "ttl", ttl)
+	}
+	return true
+}
@@ -0,0 +1,95 @@
+package healer
+
+import "container/list"
+
+// DefaultHealCacheSize is how many workloads' cooldowns are remembered by default.
+const DefaultHealCacheSize = 10000
+
+// healCache holds the last heal of each workload, keyed by cooldownKey. It is bounded: trim drops
+// the least recently healed or looked up workloads beyond the limit, so a namespace churning
+// through thousands of failing Pods can't grow it without limit. It is not safe for concurrent
+// use; the Healer guards it with healedMu.
+type healCache struct {
+	order *list.List               // Keys, most recently used first
+	items map[string]*list.Element // Elements of order by key, holding *healCacheEntry
+}
+
+type healCacheEntry struct {
+	key  string
+	mark healMark
+}
+
+func newHealCache() *healCache {
+	return &healCache{order: list.New(), items: make(map[string]*list.Element)}
+}
+
+// get returns the mark of the key and marks it as recently used.
+func (c *healCache) get(key string) (healMark, bool) {
+	e, ok := c.items[key]
+	if !ok {
+		return healMark{}, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*healCacheEntry).mark, true
+}
+
+// peek returns the mark of the key without marking it as used.
+func (c *healCache) peek(key string) (healMark, bool) {
+	e, ok := c.items[key]
+	if !ok {
+		return healMark{}, false
+	}
+	return e.Value.(*healCacheEntry).mark, true
+}
+
+// set stores the mark of the key and marks it as recently used.
+func (c *healCache) set(key string, mark healMark) {
+	if e, ok := c.items[key]; ok {
+		e.Value.(*healCacheEntry).mark = mark
+		c.order.MoveToFront(e)
+		return
+	}
+	c.items[key] = c.order.PushFront(&healCacheEntry{key: key, mark: mark})
+}
+
+func (c *healCache) delete(key string) {
+	if e, ok := c.items[key]; ok {
+		c.order.Remove(e)
+		delete(c.items, key)
+	}
+}
+
+func (c *healCache) len() int {
+	return len(c.items)
+}
+
+// trim drops the least recently used entries beyond limit and returns how many were dropped.
+func (c *healCache) trim(limit int) int {
+	dropped := 0
+	for limit > 0 && len(c.items) > limit {
+		e := c.order.Back()
+		c.order.Remove(e)
+		delete(c.items, e.Value.(*healCacheEntry).key)
+		dropped++
+	}
+	return dropped
+}
+
+// each calls fn for every entry, most recently used first. fn may delete the entry it is given.
+func (c *healCache) each(fn func(key string, mark healMark)) {
+	for e := c.order.Front(); e != nil; {
+		next := e.Next()
+		entry := e.Value.(*healCacheEntry)
+		fn(entry.key, entry.mark)
+		e = next
+	}
+}
+
+// snapshot returns a copy of the entries.
+func (c *healCache) snapshot() map[string]healMark {
+	out := make(map[string]healMark, len(c.items))
+	for key, e := range c.items {
+		out[key] = e.Value.(*healCacheEntry).mark
+	}
+	return out
+}
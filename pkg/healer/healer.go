@@ -3,14 +3,31 @@ package healer
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/daigoro86dev/k8s-healer/pkg/annotations"
+	"github.com/daigoro86dev/k8s-healer/pkg/audit"
+	"github.com/daigoro86dev/k8s-healer/pkg/control"
+	"github.com/daigoro86dev/k8s-healer/pkg/history"
+	"github.com/daigoro86dev/k8s-healer/pkg/notify"
+	"github.com/daigoro86dev/k8s-healer/pkg/schedule"
+	"github.com/daigoro86dev/k8s-healer/pkg/state"
 	"github.com/daigoro86dev/k8s-healer/pkg/util"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/time/rate"
+	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/clientcmd"
@@ -21,12 +38,351 @@ type Healer struct {
 	ClientSet    *kubernetes.Clientset
 	Namespaces   []string
 	StopCh       chan struct{}
-	HealedPods   map[string]time.Time // Tracks recently healed pods
+	HealedPods   *healCache // Recent heals by cooldownKey; guarded by healedMu
 	HealCooldown time.Duration
+
+	// MaxHealCooldown caps the cooldown of a workload, which doubles with each heal that follows the
+	// previous cooldown within CooldownReset: a workload that keeps failing is healed after 10m, 20m,
+	// 40m, ... rather than every 10m. A workload that stays healthy for CooldownReset after its
+	// cooldown starts over at HealCooldown.
+	MaxHealCooldown time.Duration
+	CooldownReset   time.Duration
+
+	// HealCacheSize bounds how many workloads' cooldowns are remembered. Beyond it the least recently
+	// healed workloads are forgotten, so a namespace churning through failing Pods can't grow memory
+	// without limit.
+	HealCacheSize int
+
+	// MinPodAge protects young Pods: Pods created less than MinPodAge ago are never healed, so the
+	// healer doesn't fight a rollout that is still converging.
+	MinPodAge time.Duration
+
+	// MinUnhealthyDuration is how long a Pod must stay unhealthy continuously before it is healed.
+	MinUnhealthyDuration time.Duration
+
+	// RestartThreshold is how often a container must restart before the crashloop and startup-probe
+	// checks fail. Defaults to util.DefaultRestartThreshold.
+	RestartThreshold int32
+
+	// NamespaceOverrides replace the cooldown, age, duration and restart thresholds, the enabled
+	// checks and the default action for matching namespaces. The most specific matching override
+	// wins; settings it leaves unset fall through to less specific ones.
+	NamespaceOverrides []NamespaceOverride
+
+	// HealPolicies enables operator mode: HealPolicy custom resources in all namespaces are
+	// reconciled and govern the Pods they select.
+	HealPolicies bool
+
+	// CustomConditions are user-defined CEL expressions evaluated alongside the built-in checks.
+	CustomConditions []*util.CELCondition
+
+	// Server-side selectors applied to the Pod informers (e.g. "status.phase!=Succeeded"). Pods
+	// outside PodLabelSelector are neither watched nor healed, manually or automatically.
+	PodLabelSelector string
+	PodFieldSelector string
+
+	// OwnerKinds restricts healing to Pods controlled by these kinds (e.g. "ReplicaSet",
+	// "StatefulSet"); a Deployment matches the Pods of its ReplicaSets. Any owner if empty.
+	OwnerKinds []string
+
+	// NamespacePatterns are globs or "re:" regexes of namespaces to watch, matched live so
+	// namespaces created later are watched too. NewHealer takes them from its namespaces.
+	NamespacePatterns []string
+
+	// WatchStrategy is WatchAuto (default), WatchPerNamespace or WatchCluster; with WatchAuto,
+	// more than ClusterWatchThreshold namespaces are watched through one cluster-wide informer.
+	WatchStrategy         string
+	ClusterWatchThreshold int
+
+	// NamespaceSelector selects the watched namespaces by label (e.g. "environment=prod"), kept
+	// current as labels change. Namespaces and NamespacePatterns, if set, further restrict the
+	// selection.
+	NamespaceSelector string
+
+	// WatchAnnotatedNamespaces selects the namespaces annotated with annotations.Watch set to
+	// "true", kept current as the annotation is added and removed. Other namespace settings
+	// further restrict the selection.
+	WatchAnnotatedNamespaces bool
+
+	// ExcludedNamespaces are namespace names or globs never watched or healed, also when all
+	// namespaces are watched.
+	ExcludedNamespaces []string
+
+	// ProtectedNamespaces and ProtectedPods are globs of namespaces and Pods (names, or
+	// namespace/name) the healer never acts on, whatever namespaces it watches.
+	// ProtectSystemNamespaces adds SystemNamespaces to them (default).
+	ProtectedNamespaces     []string
+	ProtectedPods           []string
+	ProtectSystemNamespaces bool
+
+	// Event-based detection: Warning events with these reasons seen at least EventThreshold
+	// times for a Pod mark it unhealthy.
+	EventDetection bool
+	EventReasons   []string
+	EventThreshold int32
+
+	// History records every heal; it is compacted every HistoryCompactInterval.
+	History                *history.Store
+	HistoryCompactInterval time.Duration
+
+	// EffectivenessWindow is how long a healed workload must stay healthy for the heal to count
+	// as effective; a failure of the same owner within the window counts as a relapse.
+	EffectivenessWindow time.Duration
+
+	// RecoveryTimeout is how long a replacement of a deleted or evicted Pod has to become Ready. A
+	// heal without one is recorded as unrecovered, notified and backs the workload's cooldown off
+	// further. 0 disables the verification.
+	RecoveryTimeout time.Duration
+
+	// HealRecords creates a HealRecord custom resource for every heal in the healed Pod's
+	// namespace; records older than HealRecordTTL are deleted. Needs the HealRecord CRD.
+	HealRecords   bool
+	HealRecordTTL time.Duration
+
+	// EventStream receives every heal and every skipped heal of an unhealthy Pod. Nil disables it.
+	EventStream *EventStream
+	// Audit receives every destructive action taken. Nil disables it.
+	Audit *audit.Log
+
+	// ClusterName identifies this cluster in notifications and routing rules.
+	ClusterName string
+	// Notifier routes events to the configured notification sinks. Nil disables notifications.
+	Notifier *notify.Router
+
+	// API server health throttling: heals are slowed while the control plane is degraded
+	// and paused while it is unavailable.
+	APIHealthThrottle     bool
+	APILatencyThreshold   time.Duration
+	APIErrorRateThreshold float64
+	DegradedHealInterval  time.Duration
+
+	// MaxHealsPerMinute caps automatic heals across the whole cluster, so a widespread outage (bad
+	// node, registry outage) doesn't turn into mass deletions; 0 means no limit.
+	MaxHealsPerMinute int
+
+	// OwnerHealBudget is the fraction of an owner's Pods (e.g. 0.25) that may be healed per
+	// OwnerHealBudgetWindow; further heals of its Pods are deferred. 0 disables the budget.
+	OwnerHealBudget       float64
+	OwnerHealBudgetWindow time.Duration
+
+	// BreakerThreshold trips the circuit breaker when more heals than this happen within
+	// BreakerInterval: healing halts cluster-wide until BreakerCooloff passes or it is reset through
+	// the control API. 0 disables the breaker.
+	BreakerThreshold int
+	BreakerInterval  time.Duration
+	BreakerCooloff   time.Duration
+
+	// FlapThreshold quarantines a workload healed this many times within FlapWindow: it is labeled
+	// k8s-healer.io/quarantined=true and no longer healed until the label is removed. 0 disables it.
+	FlapThreshold int
+	FlapWindow    time.Duration
+
+	// ChronicThreshold switches a workload healed this many times within ChronicWindow to
+	// notify-only and lists it as a chronic failure in the status. 0 disables it.
+	ChronicThreshold int
+	ChronicWindow    time.Duration
+
+	// Garbage collection of Succeeded/Failed Pods older than the TTL (0 disables it).
+	// Overrides are keyed by namespace name or glob.
+	CompletedPodTTL          time.Duration
+	CompletedPodTTLOverrides map[string]time.Duration
+
+	// CleanupDisruptedPods deletes managed Pods left Failed by preemption or node shutdown.
+	CleanupDisruptedPods bool
+
+	// Mode is ModeOptOut (default), healing every Pod that doesn't opt out through the enabled
+	// annotation, or ModeOptIn, healing only Pods that opt in.
+	Mode string
+
+	// PausedDeploymentBehavior controls Pods of paused Deployments: PausedNotify (default),
+	// PausedSkip or PausedHeal.
+	PausedDeploymentBehavior string
+
+	// Timezones is the time zone schedule-based features are evaluated in, per namespace.
+	// Defaults to UTC everywhere.
+	Timezones *schedule.Zones
+
+	// NoHealWindows are recurring maintenance windows during which unhealthy Pods are only
+	// reported, like during a blackout.
+	NoHealWindows []*schedule.Window
+
+	// HealGracePeriodSeconds and HealPropagationPolicy are set on the delete (or eviction) of healed
+	// Pods. Nil uses the Pod's own grace period and the API server's default policy. A grace period
+	// of zero force-deletes, except for data-sensitive Pods that did not opt in.
+	HealGracePeriodSeconds *int64
+	HealPropagationPolicy  *metav1.DeletionPropagation
+
+	// AllowFinalizerRemoval lets the healer patch away the finalizers of Pods stuck Terminating for
+	// StuckTerminatingAfter past their grace period. Dangerous: it skips the cleanup they guard.
+	AllowFinalizerRemoval bool
+	StuckTerminatingAfter time.Duration
+
+	// RemediationJobTemplate is the Job created by ActionJob; with RemediationJobThenDelete the
+	// Pod is deleted after the Job was created.
+	RemediationJobTemplate   *batchv1.Job
+	RemediationJobThenDelete bool
+
+	// StartupReport logs every unhealthy Pod and the intended action once the caches synced;
+	// StartupReportNotify also sends it as a notification.
+	StartupReport       bool
+	StartupReportNotify bool
+
+	// PreDeleteCommand is exec'ed in a running container (PreDeleteContainer, or the first running
+	// one) before a Pod is deleted, bounded by PreDeleteTimeout. Pods may override both through
+	// annotations. Empty disables the hook.
+	PreDeleteCommand   []string
+	PreDeleteContainer string
+	PreDeleteTimeout   time.Duration
+
+	// QueueSize bounds the Pod updates waiting to be checked by the QueueWorkers; updates beyond it
+	// are dropped and picked up again on the next informer resync or reconciliation pass.
+	QueueSize    int
+	QueueWorkers int
+
+	// HealRetries is how often a heal that failed on a transient API error (throttling, timeouts,
+	// an unavailable API server) is retried with exponential backoff before it counts as failed.
+	HealRetries int
+
+	// HealConcurrency bounds the heals carried out at once by the QueueWorkers and manual heals.
+	// Heals of different workloads proceed in parallel; a workload is only healed by one at a time.
+	HealConcurrency int
+
+	// ResyncPeriod is the resync period of the Pod, owner and event informers. ReconcileInterval
+	// is how often every cached Pod is re-checked regardless of informer resyncs; 0 disables it.
+	ResyncPeriod      time.Duration
+	ReconcileInterval time.Duration
+
+	// ApprovalNamespaces lists namespaces (or globs) whose heals need a human approval through the
+	// approved-by annotation; the approval request carries a dry-run preview of the action.
+	ApprovalNamespaces []string
+
+	// RemediationWebhookURL receives the heal decision for ActionWebhook; the Pod is deleted only
+	// if it answers 2xx.
+	RemediationWebhookURL     string
+	RemediationWebhookTimeout time.Duration
+
+	// ReplacementAnnotationPrefix is the prefix of the healed-from and heal-reason annotations set
+	// on the Pods replacing deleted ones. Empty disables them.
+	ReplacementAnnotationPrefix string
+
+	// UseEviction removes Pods through the policy/v1 Eviction API instead of deleting them,
+	// so heals never violate a PodDisruptionBudget.
+	UseEviction bool
+
+	// RespectPDBs defers plain deletes of Ready Pods whose PodDisruptionBudget allows no more
+	// disruptions. Defaults to true.
+	RespectPDBs bool
+
+	// CheckResourceQuota makes deletes notify-only while the namespace's ResourceQuota has no room
+	// for the replacement Pod. Defaults to true.
+	CheckResourceQuota bool
+
+	// DefaultAction is the healing action taken when no more specific policy applies.
+	// Defaults to ActionDelete.
+	DefaultAction string
+
+	// ExitCodeActions selects the action for failures by the failing container's last exit code
+	// (e.g. 1 -> ActionNotify, 137 -> ActionRolloutRestart). Unlisted codes use CheckActions.
+	ExitCodeActions map[int32]string
+
+	// CheckActions selects the action by the check that detected the failure
+	// (e.g. "crashloop" -> ActionScaleCycle). Unlisted checks use DefaultAction.
+	CheckActions map[string]string
+
+	// MemoryBumpPercent and MemoryBumpMax size the limit increases made by ActionMemoryBump.
+	MemoryBumpPercent int
+	MemoryBumpMax     resource.Quantity
+
+	// ScaleCyclePause is how long a workload stays at zero replicas during ActionScaleCycle.
+	ScaleCyclePause time.Duration
+
+	// ContainerRestartCommand is exec'ed in the failing container of opted-in multi-container Pods
+	// to restart just that container. Defaults to DefaultContainerRestartCommand.
+	ContainerRestartCommand []string
+
+	// DecisionWebhookURL, if set, is consulted synchronously before every heal and may veto it,
+	// change its action or extend the cooldown. Failures block the heal unless DecisionWebhookFailOpen.
+	DecisionWebhookURL      string
+	DecisionWebhookTimeout  time.Duration
+	DecisionWebhookFailOpen bool
+
+	// Control is the ConfigMap-backed runtime control state (blackouts, audit trail), reloaded every
+	// ControlPollInterval. Nil disables it.
+	Control             *control.Store
+	ControlPollInterval time.Duration
+	// StateConfigMap names the ConfigMap in StateNamespace the cooldowns and backoff streaks are
+	// checkpointed to every CheckpointInterval and restored from on startup, so a restart doesn't
+	// re-heal every workload at once. Empty disables it.
+	StateNamespace     string
+	StateConfigMap     string
+	CheckpointInterval time.Duration
+	// State is the store the heal history, cooldowns and quarantines are synced with every
+	// CheckpointInterval and restored from on startup: an embedded database, or Redis shared by
+	// several healers. Nil disables it.
+	State state.Store
+	// FreezeCalendarURL is an iCalendar feed or JSON endpoint listing change-freeze windows, reloaded
+	// every FreezeCalendarRefresh. Healing in the namespaces a window covers is notify-only while it
+	// lasts. Empty disables it.
+	FreezeCalendarURL     string
+	FreezeCalendarRefresh time.Duration
+
+	// InstanceName identifies this healer in audit trails; defaults to k8s-healer/<hostname>.
+	InstanceName string
+
+	// Log receives the healer's decisions and actions; defaults to slog.Default().
+	Log *slog.Logger
+
+	restConfig    *rest.Config      // Used for subresources that need a raw connection (exec)
+	dynamicClient dynamic.Interface // Used for custom resources (HealPolicy, HealRecord)
+	policies      *policyStore      // Valid HealPolicies per namespace
+
+	owners           *ownerCache       // Read-through cache of Pod owners used to enrich decisions
+	namespaceOptOuts *namespaceOptOuts // Namespaces opted out of healing by annotation
+	clusterWatch     bool              // Pods are watched by one cluster-wide informer (WatchStrategy)
+
+	annotatedCooldowns cooldownHighWater // Longest cooldown set through annotations
+	invalidAnnotations sync.Map          // invalidAnnotation -> true, for those already reported
+	celErrors          sync.Map          // celError -> true, for those already reported
+	events             *eventSignals     // Warning events observed per Pod
+
+	suppressed *suppressions     // Pods recently reported as notify-only
+	approvals  *suppressions     // Pods whose heal approval was recently requested
+	unhealthy  *unhealthyTracker // Start of each Pod's current unhealthy streak
+	control    controlCache      // Last loaded control state
+	freezes    freezeCache       // Last loaded freeze calendar windows
+
+	healedMu       sync.Mutex
+	lastCheckpoint string // HealedPods as last written to StateConfigMap
+	startedAt      time.Time
+
+	savedMu    sync.Mutex                   // Guards savedState; taken before healedMu
+	savedState map[string]map[string]uint64 // Checksums of the entries last written to or read from State, per bucket
+
+	paused     pauseState    // Set through the control API
+	operations *operationLog // Control API operations, keyed by actor and idempotency key
+
+	effectiveness *effectivenessTracker // Successful heals awaiting their outcome
+	recoveries    *recoveryTracker      // Heals awaiting a Ready replacement
+	recoveryStats recoveryStats         // Time from heal to Ready replacement
+	ownerHeals    *ownerHeals           // Recent heals per owner, for OwnerHealBudget
+	breaker       circuitBreaker        // Trips on heal storms, see BreakerThreshold
+	quarantines   *quarantines          // Workloads quarantined for flapping
+	incidents     *incidents            // Workloads notified about, awaiting recovery
+	replacements  *replacementTracker   // Deleted Pods awaiting their replacement
+	inFlight      *inFlightHeals        // Heals in progress until the replacement is seen
+	heals         *healSlots            // Workloads being healed and heals carried out at once
+	watches       namespaceWatches      // Running per-namespace watches
+	queue         *podQueue             // Pod updates waiting to be checked
+	metrics       *healerMetrics        // Counters served on /metrics
+
+	healLimiter      *rate.Limiter // Cluster-wide MaxHealsPerMinute token bucket; nil if unlimited
+	apiHealth        *apiHealthMonitor
+	apiThrottleMu    sync.Mutex
+	lastDegradedHeal time.Time
 }
 
 // NewHealer initializes the Kubernetes client configuration using kubeconfig or in-cluster settings.
-func NewHealer(kubeconfigPath string, namespaces []string) (*Healer, error) {
+func NewHealer(kubeconfigPath string, namespaces []string, client ClientOptions) (*Healer, error) {
 	var config *rest.Config
 	var err error
 
@@ -43,99 +399,850 @@ func NewHealer(kubeconfigPath string, namespaces []string) (*Healer, error) {
 		return nil, fmt.Errorf("failed to build Kubernetes config: %w", err)
 	}
 
+	// Observe every API request so healing can back off when the control plane is struggling
+	apiHealth := newAPIHealthMonitor()
+	config.Wrap(apiHealth.wrap)
+	config.Wrap(traceTransport)
+	useProtobuf(config)
+	client.apply(config)
+
 	// Create the clientset used for making API calls
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Kubernetes clientset: %w", err)
 	}
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+
+	// Literal namespaces are watched as given; globs and regexes are matched live
+	var literal, patterns []string
+	for _, ns := range namespaces {
+		if util.IsLiteralNamespace(ns) {
+			literal = append(literal, ns)
+		} else {
+			patterns = append(patterns, ns)
+		}
+	}
 
 	return &Healer{
-		ClientSet:    clientset,
-		Namespaces:   namespaces,
-		StopCh:       make(chan struct{}),
-		HealedPods:   make(map[string]time.Time),
-		HealCooldown: 10 * time.Minute, // default cooldown
+		ClientSet:              clientset,
+		restConfig:             config,
+		dynamicClient:          dynamicClient,
+		policies:               newPolicyStore(),
+		Namespaces:             literal,
+		NamespacePatterns:      patterns,
+		StopCh:                 make(chan struct{}),
+		Log:                    slog.Default(),
+		HealedPods:             newHealCache(),
+		HealCooldown:           10 * time.Minute, // default cooldown
+		RestartThreshold:       util.DefaultRestartThreshold,
+		EventReasons:           DefaultEventReasons,
+		EventThreshold:         5,
+		History:                history.NewStore(history.DefaultRetention),
+		HistoryCompactInterval: time.Hour,
+		owners:                 newOwnerCache(clientset),
+		namespaceOptOuts:       newNamespaceOptOuts(clientset),
+		events:                 newEventSignals(),
+		suppressed:             newSuppressions(),
+		unhealthy:              newUnhealthyTracker(),
+		approvals:              newSuppressions(),
+		operations:             newOperationLog(),
+		effectiveness:          newEffectivenessTracker(),
+		recoveries:             newRecoveryTracker(),
+		RecoveryTimeout:        DefaultRecoveryTimeout,
+		ownerHeals:             newOwnerHeals(),
+		quarantines:            newQuarantines(),
+		incidents:              newIncidents(),
+		replacements:           newReplacementTracker(),
+		inFlight:               newInFlightHeals(),
+		heals:                  newHealSlots(),
+		metrics:                newHealerMetrics(),
+		EffectivenessWindow:    DefaultEffectivenessWindow,
+		HealRecordTTL:          DefaultHealRecordTTL,
+
+		DefaultAction:               ActionDelete,
+		ReplacementAnnotationPrefix: annotations.Prefix,
+		Timezones:                   &schedule.Zones{Default: time.UTC},
+		ScaleCyclePause:             DefaultScaleCyclePause,
+		MemoryBumpPercent:           DefaultMemoryBumpPercent,
+		MemoryBumpMax:               DefaultMemoryBumpMax,
+		StuckTerminatingAfter:       DefaultStuckTerminatingAfter,
+		StartupReport:               true,
+		PreDeleteTimeout:            30 * time.Second,
+		RemediationWebhookTimeout:   10 * time.Second,
+		QueueSize:                   DefaultQueueSize,
+		QueueWorkers:                DefaultQueueWorkers,
+		queue:                       newPodQueue(DefaultQueueSize),
+		HealConcurrency:             DefaultHealConcurrency,
+		HealRetries:                 DefaultHealRetries,
+		ResyncPeriod:                DefaultResyncPeriod,
+		ReconcileInterval:           DefaultReconcileInterval,
+		PausedDeploymentBehavior:    PausedNotify,
+		Mode:                        ModeOptOut,
+		ProtectSystemNamespaces:     true,
+		RespectPDBs:                 true,
+		CheckResourceQuota:          true,
+		WatchStrategy:               WatchAuto,
+		ClusterWatchThreshold:       DefaultClusterWatchThreshold,
+		CleanupDisruptedPods:        true,
+		ControlPollInterval:         30 * time.Second,
+		CheckpointInterval:          DefaultCheckpointInterval,
+		DecisionWebhookTimeout:      5 * time.Second,
+
+		APIHealthThrottle:     true,
+		APILatencyThreshold:   apiHealth.LatencyThreshold,
+		APIErrorRateThreshold: apiHealth.ErrorRateThreshold,
+		DegradedHealInterval:  time.Minute,
+		OwnerHealBudgetWindow: DefaultOwnerHealBudgetWindow,
+		BreakerInterval:       DefaultBreakerInterval,
+		BreakerCooloff:        DefaultBreakerCooloff,
+		FlapWindow:            DefaultFlapWindow,
+		MaxHealCooldown:       DefaultMaxHealCooldown,
+		CooldownReset:         DefaultCooldownReset,
+		HealCacheSize:         DefaultHealCacheSize,
+		ChronicWindow:         DefaultChronicWindow,
+		apiHealth:             apiHealth,
 	}, nil
 }
 
 // Watch starts the informer loop for all configured namespaces concurrently.
 func (h *Healer) Watch() {
-	// If no namespaces are provided, default to watching all namespaces
-	if len(h.Namespaces) == 0 {
-		fmt.Println("No namespaces specified. Watching all namespaces (using NamespaceAll).")
+	// Select namespaces by pattern or label, or default to watching all namespaces if none are provided
+	configured := h.Namespaces
+	if h.discoversNamespaces() {
+		selected, err := h.startNamespaceDiscovery()
+		if err != nil {
+			h.Log.Error("Failed to select namespaces; exiting watch", "err", err)
+			return
+		}
+		h.Log.Info("Following the namespaces matching the selection as they come and go",
+			"selection", h.describeNamespaceSelection(), "matching", len(selected))
+		h.Namespaces = selected
+	} else if len(h.Namespaces) == 0 {
+		h.Log.Info("No namespaces specified; watching all namespaces")
 		h.Namespaces = []string{metav1.NamespaceAll}
 	}
+	if !h.discoversNamespaces() {
+		h.clusterWatch = h.useClusterWatch(h.Namespaces)
+	}
 
-	fmt.Printf("Starting healer to watch namespaces: [%s]\n", strings.Join(h.Namespaces, ", "))
+	h.Log.Info("Starting healer", "namespaces", strings.Join(h.Namespaces, ","))
+	if h.Mode == ModeOptIn {
+		h.Log.Info("Opt-in mode: only pods annotated to be healed are healed", "annotation", annotations.Enabled+"=true")
+	}
+	for _, w := range h.NoHealWindows {
+		h.Log.Info("Unhealthy pods are only reported while the maintenance window is open", "window", w.String())
+	}
+	h.startedAt = time.Now()
 
+	h.apiHealth.LatencyThreshold = h.APILatencyThreshold
+	h.apiHealth.ErrorRateThreshold = h.APIErrorRateThreshold
+	h.apiHealth.log = h.Log
+
+	h.healLimiter = newHealLimiter(h.MaxHealsPerMinute)
+	h.restoreCooldowns()
+	h.restoreState()
+	h.queue.setCapacity(h.QueueSize)
+	h.startQueueWorkers(h.checkAndHealPod)
+	go h.resumeScaleCycles()
+	h.startReconciler()
 	h.startHealCacheCleaner()
+	h.startCheckpointer()
+	h.startControlPoller()
+	h.startFreezeCalendar()
+	h.startEffectivenessTracker()
+	h.startRecoveryVerifier()
+	h.startHealRecordCollector()
+	if h.HealPolicies {
+		h.startPolicyController()
+	}
+	go h.History.RunCompactor(h.HistoryCompactInterval, h.StopCh)
 
-	// Start a separate goroutine for the informer watch in each namespace
+	// Start a separate goroutine for the informer watch in each namespace, or a single cluster-wide
+	// one that only lets the watched namespaces through
+	h.Log.Info("Watch strategy selected", "strategy", h.describeWatchStrategy())
+	var synced sync.WaitGroup
+	var listersMu sync.Mutex
+	var listers []corelisters.PodLister
+	onSynced := func(l corelisters.PodLister) {
+		if l != nil {
+			listersMu.Lock()
+			listers = append(listers, l)
+			listersMu.Unlock()
+		}
+		synced.Done()
+	}
 	for _, ns := range h.Namespaces {
-		go h.watchSingleNamespace(ns)
+		synced.Add(1)
+		h.startNamespaceWatch(ns, onSynced)
+	}
+	if h.clusterWatch {
+		synced.Add(1)
+		go h.watchSingleNamespace(metav1.NamespaceAll, h.StopCh, onSynced)
+	}
+	if h.StartupReport {
+		go h.reconcileOnStartup(&synced, &listersMu, &listers)
+	}
+	if !h.discoversNamespaces() {
+		h.watchNamespaceLifecycle(configured)
 	}
 
 	// Block the main goroutine until the StopCh channel is closed (on SIGINT/SIGTERM)
 	<-h.StopCh
 }
 
-// watchSingleNamespace sets up a Pod Informer for one namespace.
-func (h *Healer) watchSingleNamespace(namespace string) {
-	// Create a SharedInformerFactory scoped to the namespace, with a 30s resync period
-	factory := informers.NewSharedInformerFactoryWithOptions(h.ClientSet, time.Second*30, informers.WithNamespace(namespace))
+// watchSingleNamespace sets up a Pod Informer for one namespace, running until stopCh is closed.
+func (h *Healer) watchSingleNamespace(namespace string, stopCh <-chan struct{}, onSynced func(corelisters.PodLister)) {
+	// Create a SharedInformerFactory scoped to the namespace, resyncing every ResyncPeriod.
+	// The configured label/field selectors are applied server-side to cut watch traffic and memory.
+	factory := informers.NewSharedInformerFactoryWithOptions(h.ClientSet, h.ResyncPeriod,
+		informers.WithNamespace(namespace), informers.WithTweakListOptions(h.tweakPodListOptions))
 
-	// Get the Pod Informer
+	// Get the Pod Informer, caching Pods without the fields health evaluation never reads
 	podInformer := factory.Core().V1().Pods().Informer()
+	if err := podInformer.SetTransform(h.slimPod); err != nil {
+		h.Log.Warn("Can't slim the Pod cache", "namespace", namespace, "err", err)
+	}
+	h.countWatchErrors("pods", podInformer)
 
 	// Register event handlers
 	podInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		// New Pods may replace healed ones
+		AddFunc: func(obj interface{}) {
+			if pod := obj.(*v1.Pod); h.watchesNamespace(pod.Namespace) {
+				h.inFlight.replaced(pod)
+				h.queueReplacement(pod)
+				h.observeRecovery(pod)
+				h.observeWorkloadRecovery(pod)
+			}
+		},
 		// We use UpdateFunc because a Pod becomes unhealthy (e.g., CrashLoopBackOff) after its initial creation
 		UpdateFunc: func(oldObj, newObj interface{}) {
 			newPod := newObj.(*v1.Pod)
-			h.checkAndHealPod(newPod)
+			h.observeRecovery(newPod)
+			h.observeWorkloadRecovery(newPod)
+			h.enqueuePod(newPod)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if pod, ok := obj.(*v1.Pod); ok {
+				h.forgetInvalidAnnotations(pod)
+			}
 		},
 	})
 
-	// Start the informer and wait for the cache to be synced
-	factory.Start(h.StopCh)
-	if !cache.WaitForCacheSync(h.StopCh, podInformer.HasSynced) {
-		fmt.Printf("Error syncing cache for namespace %s. Exiting watch.\n", namespace)
+	// Owners live in a separate, unfiltered factory: the Pod selectors must not apply to them.
+	ownerFactory := informers.NewSharedInformerFactoryWithOptions(h.ClientSet, h.ResyncPeriod, informers.WithNamespace(namespace))
+
+	// Register the owner listers so decision enrichment is served from cache
+	synced := append([]cache.InformerSynced{podInformer.HasSynced}, h.owners.register(namespace, ownerFactory)...)
+
+	// Optionally watch Warning events about Pods and feed them into the decision
+	var eventFactory informers.SharedInformerFactory
+	if h.EventDetection {
+		eventFactory = informers.NewSharedInformerFactoryWithOptions(h.ClientSet, h.ResyncPeriod,
+			informers.WithNamespace(namespace),
+			informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+				opts.FieldSelector = "involvedObject.kind=Pod,type=Warning"
+			}))
+		podLister := factory.Core().V1().Pods().Lister()
+		eventInformer := eventFactory.Core().V1().Events().Informer()
+		h.countWatchErrors("events", eventInformer)
+		onEvent := func(obj interface{}) {
+			ev, ok := obj.(*v1.Event)
+			if !ok || !h.watchesNamespace(ev.InvolvedObject.Namespace) {
+				return
+			}
+			h.events.record(ev)
+			// Re-evaluate the Pod straight away instead of waiting for its next status update
+			pod, err := podLister.Pods(ev.InvolvedObject.Namespace).Get(ev.InvolvedObject.Name)
+			if err == nil {
+				h.enqueuePod(pod)
+			}
+		}
+		eventInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc:    onEvent,
+			UpdateFunc: func(oldObj, newObj interface{}) { onEvent(newObj) },
+		})
+		synced = append(synced, eventInformer.HasSynced)
+	}
+
+	// Start the informers and wait for the caches to be synced
+	factory.Start(stopCh)
+	ownerFactory.Start(stopCh)
+	if eventFactory != nil {
+		eventFactory.Start(stopCh)
+	}
+	if !cache.WaitForCacheSync(stopCh, synced...) {
+		if onSynced != nil {
+			onSynced(nil)
+		}
+		select {
+		case <-stopCh: // Stopped before the caches synced (shutdown or namespace deleted)
+		default:
+			h.Log.Error("Failed to sync cache; exiting watch", "namespace", namespace)
+		}
 		return
 	}
 
-	fmt.Printf("✅ Successfully synced cache and started watching namespace: %s\n", namespace)
+	h.Log.Info("Synced cache and started watching", "namespace", namespace)
+	h.setPodLister(namespace, stopCh, factory.Core().V1().Pods().Lister())
+	if onSynced != nil {
+		onSynced(factory.Core().V1().Pods().Lister())
+	}
+}
+
+// ExcludesNamespace reports whether the namespace matches one of the ExcludedNamespaces.
+func (h *Healer) ExcludesNamespace(namespace string) bool {
+	return matchesAnyNamespace(h.ExcludedNamespaces, namespace)
+}
+
+// OwnerKindsSupported lists the controller kinds OwnerKinds may name.
+var OwnerKindsSupported = []string{"Deployment", "ReplicaSet", "StatefulSet", "DaemonSet", "Job", "ReplicationController"}
+
+// ValidateOwnerKinds checks that the kinds are supported, ignoring case as eligibleOwner does.
+func ValidateOwnerKinds(kinds []string) error {
+	for _, kind := range kinds {
+		supported := false
+		for _, s := range OwnerKindsSupported {
+			supported = supported || strings.EqualFold(kind, s)
+		}
+		if !supported {
+			return fmt.Errorf("unsupported owner kind %q (expected one of %s)", kind, strings.Join(OwnerKindsSupported, ", "))
+		}
+	}
+	return nil
+}
+
+// eligibleOwner reports whether the Pod is managed by an owner whose Pods may be healed: any owner,
+// or one of OwnerKinds, matched against the Pod's controller and its top-level owner.
+func (h *Healer) eligibleOwner(pod *v1.Pod) bool {
+	if len(pod.OwnerReferences) == 0 {
+		return false
+	}
+	if len(h.OwnerKinds) == 0 {
+		return true
+	}
+	ref := metav1.GetControllerOf(pod)
+	if ref == nil {
+		return false
+	}
+	kinds := []string{ref.Kind}
+	if owner := h.owners.Resolve(pod); owner != nil && owner.Kind != ref.Kind {
+		kinds = append(kinds, owner.Kind)
+	}
+	for _, allowed := range h.OwnerKinds {
+		for _, kind := range kinds {
+			if strings.EqualFold(allowed, kind) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// selectsPod reports whether the Pod matches PodLabelSelector. The informers only see matching Pods;
+// this guards Pods fetched directly, such as manual heal targets.
+func (h *Healer) selectsPod(pod *v1.Pod) bool {
+	if h.PodLabelSelector == "" {
+		return true
+	}
+	selector, err := labels.Parse(h.PodLabelSelector)
+	return err == nil && selector.Matches(labels.Set(pod.Labels))
+}
+
+// tweakPodListOptions applies the configured selectors to the Pod list/watch calls.
+func (h *Healer) tweakPodListOptions(opts *metav1.ListOptions) {
+	if h.PodLabelSelector != "" {
+		opts.LabelSelector = h.PodLabelSelector
+	}
+	if h.PodFieldSelector != "" {
+		opts.FieldSelector = h.PodFieldSelector
+	}
 }
 
 // checkAndHealPod checks a Pod's health and executes deletion if necessary.
 func (h *Healer) checkAndHealPod(pod *v1.Pod) {
-	// Skip unmanaged pods
-	if len(pod.OwnerReferences) == 0 {
+	h.annotateReplacement(pod)
+
+	// Protected namespaces and Pods are never acted on
+	if h.protection(pod) != "" {
 		return
 	}
 
-	// Skip if recently healed
+	// Pods that opted out are left alone once terminating or completed, too
+	finished := pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed
+	if (pod.DeletionTimestamp != nil || finished) && h.optedOut(pod, h.owners.Resolve(pod)) != "" {
+		return
+	}
+
+	// Terminating pods are only released when wedged on finalizers, preempted/shut-down pods are
+	// cleaned up with their own reason, and other completed pods are only ever garbage collected
+	if h.handleWedgedPod(pod) || h.cleanupDisruptedPod(pod) || h.sweepCompletedPod(pod) {
+		return
+	}
+
+	// Updates racing the termination of a Pod we just removed must not remove it again
+	if h.inFlight.active(pod.UID) {
+		return
+	}
+
+	start := time.Now()
+	f := h.evaluatePod(pod)
+	if f == nil {
+		return
+	}
 	podKey := fmt.Sprintf("%s/%s", pod.Namespace, pod.Name)
-	if lastHeal, ok := h.HealedPods[podKey]; ok {
-		if time.Since(lastHeal) < h.HealCooldown {
-			fmt.Printf("   [SKIP] ⏳ Pod %s was healed %.0f seconds ago — skipping re-heal.\n",
-				podKey, time.Since(lastHeal).Seconds())
+
+	// Trace the heal from detection on; healthy Pods aren't traced
+	ctx, span := tracer.Start(context.Background(), "heal", trace.WithTimestamp(start),
+		trace.WithAttributes(podAttributes(pod)...), trace.WithAttributes(attrCheck.String(f.Check), attrReason.String(f.Reason)))
+	defer span.End()
+	_, detect := tracer.Start(ctx, "detect", trace.WithTimestamp(start))
+	detect.End()
+	decideCtx, decide := tracer.Start(ctx, "decide")
+	defer decide.End()
+
+	// Heal a workload from one worker at a time; its cooldown may have started while this one waited
+	if !h.claimWorkload(pod) {
+		h.recordSkip(decideCtx, pod, f, skipWorkloadBusy)
+		h.Log.Info("Not healing: another Pod of the workload is being healed", "pod", podKey, "reason", f.Reason)
+		return
+	}
+	defer h.releaseWorkload(pod)
+	h.refreshCooldown(pod)
+	if _, ok := h.coolingDown(pod); ok {
+		return
+	}
+
+	// Honor the enabled annotation on the Pod, its workload or its namespace
+	owner := h.owners.Resolve(pod)
+	span.SetAttributes(attrOwner.String(owner.String()))
+	if why := h.optedOut(pod, owner); why != "" {
+		h.recordSkip(decideCtx, pod, f, skipOptedOut)
+		h.Log.Info("Not healing: opted out", "pod", podKey, "reason", f.Reason, "why", why)
+		return
+	}
+
+	// A failing replacement means the previous heal of this owner did not fix it
+	h.observeRelapse(pod, owner, f)
+
+	// Stop healing workloads that keep failing right after being healed
+	if h.quarantined(owner) || h.quarantineIfFlapping(pod, owner, f) {
+		h.recordSkip(decideCtx, pod, f, skipQuarantined)
+		h.Log.Info("Not healing: workload is quarantined", "pod", podKey, "reason", f.Reason, "owner", owner.String())
+		return
+	}
+
+	// Workloads healed over and over need a human more than another heal
+	if why := h.chronicFailure(owner); why != "" {
+		h.recordSkip(decideCtx, pod, f, skipChronic)
+		h.suppressHeal(pod, f, why)
+		return
+	}
+
+	// Honor pauses, the circuit breaker, blackouts, freezes and maintenance windows
+	if cause, why := h.halted(pod.Namespace); cause != "" {
+		h.recordSkip(decideCtx, pod, f, cause)
+		h.suppressHeal(pod, f, why)
+		return
+	}
+
+	if h.handlePausedDeployment(pod, owner, f) {
+		h.recordSkip(decideCtx, pod, f, skipPausedDeployment)
+		return
+	}
+
+	// Only heal Pods whose controller is around to replace them
+	if why := h.controllerPreflight(decideCtx, owner); why != "" {
+		h.recordSkip(decideCtx, pod, f, skipNoController)
+		h.Log.Info("Not healing: no controller to replace the Pod", "pod", podKey, "reason", f.Reason, "why", why)
+		return
+	}
+
+	action := h.actionFor(pod, f)
+	switch action {
+	case ActionSkip:
+		h.recordSkip(decideCtx, pod, f, skipPolicy)
+		h.Log.Info("Not healing: policy action is skip", "pod", podKey, "reason", f.Reason)
+		return
+	case ActionNotify:
+		h.recordSkip(decideCtx, pod, f, skipPolicy)
+		h.suppressHeal(pod, f, "policy action is notify")
+		return
+	case ActionMemoryBump:
+		if oomKilled(f) {
+			if restarts, threshold := h.oomRestarts(pod, f); restarts < threshold {
+				h.recordSkip(decideCtx, pod, f, skipPolicy)
+				h.Log.Info("Not raising the memory limit yet: container not OOM-killed repeatedly", "pod", podKey,
+					"container", f.Termination.Container, "restarts", restarts, "threshold", threshold)
+				return
+			}
+		}
+	}
+
+	if ok, why := h.apiAllows(actionHeal); !ok {
+		h.recordSkip(decideCtx, pod, f, skipAPIDegraded)
+		h.Log.Info("Deferring heal: API server is struggling", "pod", podKey, "why", why)
+		return
+	}
+
+	// Let the external decision webhook veto or adjust the heal
+	verdict, why := h.consultDecision(decideCtx, pod, owner, f, action)
+	if why != "" {
+		h.recordSkip(decideCtx, pod, f, skipDecisionWebhook)
+		if verdict.allowed && verdict.action == ActionSkip {
+			h.Log.Info("Not healing: decision webhook changed the action to skip", "pod", podKey)
+		} else {
+			h.suppressHeal(pod, f, why)
+		}
+		return
+	}
+	action = verdict.action
+
+	// Heals in approval-required namespaces wait for a human, who sees a dry-run preview
+	if h.requiresApproval(pod.Namespace) {
+		approver := approvedBy(pod)
+		if approver == "" {
+			h.recordSkip(decideCtx, pod, f, skipApproval)
+			h.requestApproval(pod, owner, f, action)
+			return
+		}
+		f.Reason = fmt.Sprintf("%s (approved by %s)", f.Reason, approver)
+	}
+
+	if why := h.ownerBudgetExceeded(pod, owner); why != "" {
+		h.recordSkip(decideCtx, pod, f, skipOwnerBudget)
+		h.Log.Info("Deferring heal: owner heal budget exhausted", "pod", podKey, "why", why)
+		return
+	}
+
+	// Plain deletes bypass PodDisruptionBudgets; don't heal a quorum into an outage. And a
+	// replacement the quota doesn't admit turns a crash-looping Pod into a missing one.
+	if action == ActionDelete {
+		if why := h.pdbBlocksDelete(decideCtx, pod); why != "" {
+			h.recordSkip(decideCtx, pod, f, skipPDB)
+			h.Log.Info("Deferring heal: PodDisruptionBudget allows no disruption", "pod", podKey, "why", why)
+			return
+		}
+		if why := h.quotaShortfall(decideCtx, pod); why != "" {
+			h.recordSkip(decideCtx, pod, f, skipQuota)
+			h.suppressHeal(pod, f, why)
 			return
 		}
 	}
 
-	if util.IsUnhealthy(pod) {
-		reason := util.GetHealReason(pod)
-		fmt.Printf("\n!!! HEALING ACTION REQUIRED !!!\n")
-		fmt.Printf("    Pod: %s\n", podKey)
-		fmt.Printf("    Reason: %s\n", reason)
+	// Spread heals out during widespread outages instead of amplifying them. Checked last, so a
+	// heal another guard defers doesn't use up a token.
+	if !h.healAllowed() {
+		h.recordSkip(decideCtx, pod, f, skipRateLimit)
+		h.Log.Info("Deferring heal: heal rate limit reached", "pod", podKey, "maxHealsPerMinute", h.MaxHealsPerMinute)
+		return
+	}
+
+	decide.End()
+
+	// Bound the heals carried out at once
+	if !h.heals.acquire(h.HealConcurrency, h.StopCh) {
+		return
+	}
+	log := h.Log.With("pod", podKey)
+	attrs := []any{"reason", f.Reason, "owner", owner.Summary(), "action", action}
+	if hints := pod.Annotations[annotations.Hints]; hints != "" {
+		attrs = append(attrs, "hints", hints)
+	}
+	log.Warn("Healing pod", attrs...)
+
+	actCtx, act := tracer.Start(ctx, "act", trace.WithAttributes(attrAction.String(action)))
+	taken, err := h.performAction(actCtx, action, pod, owner, f)
+	h.heals.done()
+	act.SetAttributes(attrAction.String(taken))
+	endSpan(act, err)
+
+	// Retry transient API failures with backoff before the heal counts as failed
+	if transientError(err) {
+		if delay, ok := h.queue.retry(pod, h.HealRetries); ok {
+			log.Warn("Heal failed transiently; retrying", "action", taken, "err", err, "delay", delay)
+			return
+		}
+	}
+	if owner != nil {
+		h.ownerHeals.record(owner.Namespace+"/"+owner.String(), time.Now())
+	}
+	h.recordBreakerHeal()
+
+	// Record the healing timestamp; a successful heal cools down for at least the decision webhook's cooldown
+	if err == nil {
+		h.recordCooldown(pod, time.Now(), verdict.cooldown)
+	} else {
+		h.markHealed(pod, time.Now())
+	}
+	rec := h.recordHeal(pod, f, taken, err)
+	h.trackEffectiveness(owner, rec)
+	h.expectRecovery(pod, owner, f, rec)
+	if err != nil {
+		h.notify(pod, notify.EventHealFailed, notify.SeverityCritical, f, taken, err.Error())
+	} else {
+		h.notify(pod, notify.EventHeal, notify.SeverityWarning, f, taken, actionMessage(taken))
+	}
+
+	log.Info("Healing complete", "action", taken, "success", err == nil)
+}
+
+// halted returns the skip cause and a description if healing in the namespace is currently halted
+// by a pause, the circuit breaker, a blackout, a freeze or a maintenance window, or "" otherwise.
+func (h *Healer) halted(namespace string) (cause, why string) {
+	// Honor a pause requested through the control API
+	if by, reason, ok := h.paused.get(); ok {
+		return skipPaused, fmt.Sprintf("healing paused by %s (%s)", by, reason)
+	}
+
+	// Keep observing, but don't act, while a heal storm has the circuit breaker open
+	if why := h.breakerOpen(); why != "" {
+		return skipBreaker, why
+	}
+
+	// Honor blackouts recorded through the control ConfigMap
+	if b := h.activeBlackout(namespace); b != nil {
+		return skipBlackout, fmt.Sprintf("blackout %s until %s (%s)", b.ID, b.ExpiresAt.Format(time.RFC3339), b.Reason)
+	}
+
+	// Honor change freezes published in the freeze calendar
+	if w := h.activeFreeze(namespace); w != nil {
+		return skipFreeze, describeFreeze(w)
+	}
+
+	// Honor recurring maintenance windows
+	if w, until := h.activeNoHealWindow(namespace); w != nil {
+		return skipMaintenanceWindow, describeNoHealWindow(w, until)
+	}
+	return "", ""
+}
+
+// failure describes why a Pod was judged unhealthy and which check detected it.
+type failure struct {
+	Check       string
+	Reason      string
+	Termination *util.Termination // Last termination of the failing container, if any
+}
+
+// actionFor returns the configured action for the Pod's failure. The HealPolicy selecting the Pod
+// takes precedence over the global exit code and check actions.
+func (h *Healer) actionFor(pod *v1.Pod, f *failure) string {
+	if p := h.policies.match(pod); p != nil {
+		if action := p.actionFor(f); action != "" {
+			return action
+		}
+	}
+	if f.Termination != nil {
+		if action, ok := h.ExitCodeActions[f.Termination.ExitCode]; ok {
+			return action
+		}
+	}
+	if action, ok := h.CheckActions[f.Check]; ok {
+		return action
+	}
+	return h.defaultActionFor(pod)
+}
+
+// Names of the checks that can report a failure.
+const (
+	checkCrashLoop    = "crashloop"
+	checkStartupProbe = "startup-probe"
+	checkEvents       = "events"
+	checkCustom       = "custom"
+)
+
+// evaluatePod runs the part of the pipeline that needs no API calls: it returns the Pod's failure
+// if the Pod is due for a heal, or nil if it is healthy, unmanaged, cooling down or not unhealthy
+// for long enough.
+func (h *Healer) evaluatePod(pod *v1.Pod) *failure {
+	// Skip unmanaged pods and pods of owner kinds that aren't healed
+	if !h.eligibleOwner(pod) {
+		return nil
+	}
+
+	// Skip if the Pod, or another Pod of its controller, was recently healed
+	podKey := fmt.Sprintf("%s/%s", pod.Namespace, pod.Name)
+	if mark, ok := h.coolingDown(pod); ok {
+		healed := "Pod " + podKey
+		if mark.Pod != pod.Name {
+			healed = fmt.Sprintf("Pod %s of %s", mark.Pod, mark.Owner)
+		}
+		h.countSkip(skipCooldown)
+		h.Log.Debug("Not healing: cooling down", "pod", podKey, "healed", healed,
+			"ago", time.Since(mark.At).Round(time.Second), "streak", mark.Streak, "cooldown", mark.Until.Sub(mark.At).Round(time.Second))
+		return nil
+	}
+
+	f := h.detectFailure(pod)
+	if f == nil {
+		h.unhealthy.clear(pod.UID)
+		return nil
+	}
+
+	// A HealPolicy may switch healing off for the Pods it selects
+	if p := h.policies.match(pod); p != nil && p.disabled {
+		h.recordSkip(context.Background(), pod, f, skipPolicy)
+		h.Log.Debug("Not healing: disabled by HealPolicy", "pod", podKey, "policy", p.name, "reason", f.Reason)
+		return nil
+	}
+
+	if minAge := h.minPodAgeFor(pod); time.Since(pod.CreationTimestamp.Time) < minAge {
+		h.recordSkip(context.Background(), pod, f, skipMinAge)
+		h.Log.Debug("Not healing: pod too young", "pod", podKey,
+			"age", time.Since(pod.CreationTimestamp.Time).Round(time.Second), "minAge", minAge)
+		return nil
+	}
+
+	// Require the Pod to stay unhealthy for a while, so slow-starting apps get a chance to recover
+	minUnhealthy := h.minUnhealthyDurationFor(pod)
+	if unhealthyFor := h.unhealthy.observe(pod.UID, time.Now()); unhealthyFor < minUnhealthy {
+		h.Log.Debug("Not healing yet: pod not unhealthy for long enough", "pod", podKey,
+			"unhealthyFor", unhealthyFor.Round(time.Second), "minUnhealthy", minUnhealthy)
+		return nil
+	}
+	return f
+}
+
+// knownChecks lists the checks that policy can select actions for.
+var knownChecks = map[string]bool{
+	checkCrashLoop:    true,
+	checkStartupProbe: true,
+	checkEvents:       true,
+	checkCustom:       true,
+}
+
+// detectFailure runs the built-in checks followed by the user-defined CEL conditions
+// and returns the first failure found, or nil if the Pod is healthy. The failure is
+// annotated with the failing container's last exit code.
+func (h *Healer) detectFailure(pod *v1.Pod) *failure {
+	f := h.runChecks(pod)
+	if f == nil {
+		return nil
+	}
+	h.Log.Debug("Pod failed check", "pod", pod.Namespace+"/"+pod.Name, "check", f.Check, "reason", f.Reason)
+	if t := util.LastTermination(pod); t != nil {
+		f.Termination = t
+		f.Reason = fmt.Sprintf("%s, last %s", f.Reason, t)
+	}
+	return f
+}
+
+// runChecks returns the first failing check for the Pod.
+func (h *Healer) runChecks(pod *v1.Pod) *failure {
+	threshold := h.restartThresholdFor(pod)
+	if h.checkAllowedFor(pod, checkCrashLoop) {
+		if reason, failed := util.CrashLoopFailure(pod, threshold); failed {
+			return &failure{Check: checkCrashLoop, Reason: reason}
+		}
+	}
+
+	if h.checkAllowedFor(pod, checkStartupProbe) {
+		if reason, failed := util.StartupProbeFailure(pod, threshold); failed {
+			return &failure{Check: checkStartupProbe, Reason: reason}
+		}
+	}
+
+	if h.EventDetection && h.checkAllowedFor(pod, checkEvents) {
+		if sig := h.events.strongest(pod, h.EventReasons); sig != nil && sig.Count >= h.EventThreshold {
+			return &failure{
+				Check:  checkEvents,
+				Reason: fmt.Sprintf("Repeated %s events (Count: %d): %s", sig.Reason, sig.Count, sig.Message),
+			}
+		}
+	}
+
+	if !h.checkAllowedFor(pod, checkCustom) {
+		return nil
+	}
+	if c := util.MatchingCELCondition(pod, h.CustomConditions, func(c *util.CELCondition, err error) {
+		h.celError(pod, c, err)
+	}); c != nil {
+		return &failure{Check: checkCustom, Reason: fmt.Sprintf("Custom condition matched: %s", c.Expression)}
+	}
+
+	return nil
+}
 
-		h.triggerPodDeletion(pod)
+// healMark is a heal remembered for cooldowns.
+type healMark struct {
+	At     time.Time `json:"at"`
+	Until  time.Time `json:"until"`           // End of the cooldown
+	Streak int       `json:"streak"`          // Heals in a row without the workload staying healthy, see CooldownReset
+	Pod    string    `json:"pod"`             // Name of the healed Pod
+	Owner  string    `json:"owner,omitempty"` // Kind/Name of its controller, if any
+}
 
-		// Record the healing timestamp
-		h.HealedPods[podKey] = time.Now()
+// cooldownKey returns the HealedPods key of the Pod: its namespace and the UID of its controller,
+// or the Pod's own UID if it has none. Replacement Pods get new names, so a cooldown keyed on the
+// Pod name would never apply to them; keyed on the controller it covers all of the workload's Pods.
+func cooldownKey(pod *v1.Pod) string {
+	uid := pod.UID
+	if ref := metav1.GetControllerOf(pod); ref != nil && ref.UID != "" {
+		uid = ref.UID
+	}
+	return pod.Namespace + "/" + string(uid)
+}
 
-		fmt.Printf("!!! HEALING ACTION COMPLETE !!!\n\n")
+// lastHealed returns the last heal of the Pod or another Pod of its controller.
+func (h *Healer) lastHealed(pod *v1.Pod) (healMark, bool) {
+	h.healedMu.Lock()
+	defer h.healedMu.Unlock()
+	key := cooldownKey(pod)
+	mark, ok := h.HealedPods.get(key)
+	// The streak would be reset anyway
+	if ok && time.Now().After(mark.Until.Add(h.CooldownReset)) {
+		h.HealedPods.delete(key)
+		return healMark{}, false
 	}
+	return mark, ok
+}
+
+// cooldowns returns the heals whose streak hasn't been reset yet, forgetting the others.
+func (h *Healer) cooldowns() map[string]healMark {
+	now := time.Now()
+	h.healedMu.Lock()
+	defer h.healedMu.Unlock()
+	marks := h.HealedPods.snapshot()
+	for key, mark := range marks {
+		if now.After(mark.Until.Add(h.CooldownReset)) {
+			h.HealedPods.delete(key)
+			delete(marks, key)
+		}
+	}
+	return marks
+}
+
+// markHealed records a heal for the cooldown of the Pod's controller.
+func (h *Healer) markHealed(pod *v1.Pod, t time.Time) {
+	h.recordCooldown(pod, t, 0)
+}
+
+// clearCooldowns forgets the heals matching the namespace and a name (either may be a glob) and
+// returns how many were cleared. The name is matched against the healed Pod and its controller.
+func (h *Healer) clearCooldowns(namespace, name string) int {
+	h.healedMu.Lock()
+	var cleared []string
+	h.HealedPods.each(func(key string, mark healMark) {
+		ns, _, _ := strings.Cut(key, "/")
+		nsOK, _ := filepath.Match(namespace, ns)
+		podOK, _ := filepath.Match(name, mark.Pod)
+		if _, owner, ok := strings.Cut(mark.Owner, "/"); ok && !podOK {
+			podOK, _ = filepath.Match(name, owner)
+		}
+		if nsOK && podOK {
+			h.HealedPods.delete(key)
+			cleared = append(cleared, key)
+		}
+	})
+	h.healedMu.Unlock()
+	h.deleteCooldowns(cleared)
+	return len(cleared)
 }
 
 func (h *Healer) startHealCacheCleaner() {
@@ -145,11 +1252,15 @@ func (h *Healer) startHealCacheCleaner() {
 			select {
 			case <-ticker.C:
 				now := time.Now()
-				for key, t := range h.HealedPods {
-					if now.Sub(t) > 2*h.HealCooldown {
-						delete(h.HealedPods, key)
-					}
-				}
+				retain := 2 * h.maxCooldown()
+				h.operations.prune(now)
+				h.events.prune(now)
+				h.suppressed.prune(now, retain)
+				h.approvals.prune(now, retain)
+				h.unhealthy.prune(now, time.Hour)
+				h.inFlight.prune(now)
+				h.replacements.prune(now)
+				h.ownerHeals.prune(now, max(h.OwnerHealBudgetWindow, h.FlapWindow, h.ChronicWindow))
 			case <-h.StopCh:
 				ticker.Stop()
 				return
@@ -158,18 +1269,147 @@ func (h *Healer) startHealCacheCleaner() {
 	}()
 }
 
+// recordHeal appends the outcome of a healing action to the heal history and returns the record.
+func (h *Healer) recordHeal(pod *v1.Pod, f *failure, action string, err error) history.Record {
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	rec := history.Record{
+		Time:      time.Now(),
+		Namespace: pod.Namespace,
+		Pod:       pod.Name,
+		Owner:     h.owners.Resolve(pod).String(),
+		Check:     f.Check,
+		Reason:    f.Reason,
+		Action:    action,
+		Result:    result,
+	}
+	h.History.Add(rec)
+	h.countHeal(pod.Namespace, f.Check, result)
+	h.auditAction(pod, f.Check, f.Reason, action, err)
+	h.createHealRecord(rec, err)
+	ev := StreamEvent{
+		Time:      rec.Time,
+		Decision:  DecisionHeal,
+		Namespace: rec.Namespace,
+		Pod:       rec.Pod,
+		Owner:     rec.Owner,
+		Check:     rec.Check,
+		Reason:    rec.Reason,
+		Action:    rec.Action,
+		Result:    rec.Result,
+	}
+	if err != nil {
+		ev.Error = err.Error()
+	}
+	h.EventStream.Write(ev)
+	return rec
+}
+
+// notify sends an event about the Pod through the notification router without blocking the caller.
+func (h *Healer) notify(pod *v1.Pod, typ notify.EventType, severity notify.Severity, f *failure, action, message string) {
+	if h.Notifier == nil {
+		return
+	}
+	owner := h.owners.Resolve(pod)
+	ev := notify.Event{
+		Type:         typ,
+		Severity:     severity,
+		Cluster:      h.ClusterName,
+		Namespace:    pod.Namespace,
+		Pod:          pod.Name,
+		Owner:        owner.String(),
+		RestartCount: maxRestartCount(pod),
+		Action:       action,
+		Message:      message,
+		Labels:       pod.Labels,
+		Time:         time.Now(),
+	}
+	if f != nil {
+		ev.Reason, ev.Check = f.Reason, f.Check
+	}
+	if owner != nil && opensIncident(typ) {
+		h.incidents.open(owner.Namespace+"/"+owner.String(), ev.Time)
+	}
+	go h.Notifier.Dispatch(ev)
+}
+
+// actionMessage describes the outcome of a successful action for notifications.
+func actionMessage(action string) string {
+	switch action {
+	case ActionRestartContainer:
+		return "Failing container restarted; the rest of the Pod was left running."
+	case ActionJob:
+		return "Remediation job created."
+	case ActionMemoryBump:
+		return "Memory limit of the OOM-killed container raised in the owning workload; new Pods are rolled out."
+	case ActionWebhook:
+		return "Remediation handed to the external remediation webhook."
+	case ActionScaleCycle:
+		return "Owning workload scaled to zero for a cold restart; it is scaled back up after a short pause."
+	case ActionRollback:
+		return "Deployment rolled back to its previous revision; every Pod of the newest one was crash-looping."
+	case ActionRolloutRestart:
+		return "Owning workload rollout-restarted; its controller replaces the Pods within its rollout budget."
+	case StrategyEvict:
+		return "Pod evicted; its controller will recreate it."
+	default:
+		return "Pod deleted; its controller will recreate it."
+	}
+}
+
+// maxRestartCount returns the highest restart count among the Pod's containers.
+func maxRestartCount(pod *v1.Pod) int32 {
+	var max int32
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.RestartCount > max {
+			max = cs.RestartCount
+		}
+	}
+	return max
+}
+
 // triggerPodDeletion deletes the Pod, relying on the managing controller to recreate a fresh one.
-func (h *Healer) triggerPodDeletion(pod *v1.Pod) {
+func (h *Healer) triggerPodDeletion(ctx context.Context, pod *v1.Pod, f *failure) error {
+	return h.removePod(ctx, pod, h.UseEviction, f)
+}
+
+// removePod deletes or evicts the Pod after running its pre-delete hook.
+func (h *Healer) removePod(ctx context.Context, pod *v1.Pod, evict bool, f *failure) error {
+	// Give the Pod a chance to drain or dump diagnostics first
+	h.runPreDeleteHook(ctx, pod)
+
 	// Use a context with timeout for the API call to prevent indefinite hangs
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
 	defer cancel()
 
-	// Perform the API Delete call
-	err := h.ClientSet.CoreV1().Pods(pod.Namespace).Delete(ctx, pod.Name, metav1.DeleteOptions{})
+	// The controller may create the replacement before the call returns, so expect it beforehand
+	h.inFlight.start(pod, time.Now())
+	cancelReplacement := h.expectReplacement(pod, f)
+	failed := func() {
+		h.inFlight.cancel(pod)
+		cancelReplacement()
+	}
+
+	// Evict through the Eviction API to honor PodDisruptionBudgets, or perform the API Delete call
+	if evict {
+		err := h.evictPod(ctx, pod, h.healDeleteOptions())
+		if err != nil {
+			failed()
+			h.Log.Error("Failed to evict pod", "pod", pod.Namespace+"/"+pod.Name, "err", err)
+		} else {
+			h.Log.Info("Evicted pod; its controller is expected to recreate it", "pod", pod.Namespace+"/"+pod.Name)
+		}
+		return err
+	}
+	err := h.deletePod(ctx, pod, h.healDeleteOptions())
 
 	if err != nil {
-		fmt.Printf("   [FAIL] ❌ Failed to delete pod %s/%s: %v\n", pod.Namespace, pod.Name, err)
+		failed()
+		h.Log.Error("Failed to delete pod", "pod", pod.Namespace+"/"+pod.Name, "err", err)
 	} else {
-		fmt.Printf("   [SUCCESS] ✅ Deleted pod %s/%s. Controller is expected to recreate the Pod immediately.\n", pod.Namespace, pod.Name)
+		h.Log.Info("Deleted pod; its controller is expected to recreate it", "pod", pod.Namespace+"/"+pod.Name)
 	}
+	return err
 }
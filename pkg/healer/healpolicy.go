@@ -0,0 +1,278 @@
+package healer
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+// HealPolicyGVR identifies the HealPolicy custom resource (see deploy/crds).
+var HealPolicyGVR = schema.GroupVersionResource{Group: "k8s-healer.io", Version: "v1alpha1", Resource: "healpolicies"}
+
+// HealPolicy lets the team owning a namespace declare how its Pods are healed. A Pod is governed by
+// the first policy of its namespace, in name order, whose selector matches it; the policy's
+// settings take precedence over the healer's namespace overrides and global settings.
+type HealPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   HealPolicySpec   `json:"spec"`
+	Status HealPolicyStatus `json:"status,omitempty"`
+}
+
+// HealPolicySpec is the desired healing behaviour for the selected Pods. Unset fields keep the
+// healer's settings.
+type HealPolicySpec struct {
+	// Selector selects the Pods of the namespace the policy applies to; all of them if empty.
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// FailureReasons lists the checks that may heal the selected Pods (crashloop, startup-probe,
+	// events, custom); failures detected by other checks are ignored. All checks if empty.
+	FailureReasons []string `json:"failureReasons,omitempty"`
+
+	// Enabled set to false stops healing the selected Pods; failures are still detected and reported.
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// RestartThreshold is how often a container must restart before the crashloop and startup-probe
+	// checks fail.
+	RestartThreshold *int32 `json:"restartThreshold,omitempty"`
+
+	MinPodAge            *metav1.Duration `json:"minPodAge,omitempty"`
+	MinUnhealthyDuration *metav1.Duration `json:"minUnhealthyDuration,omitempty"`
+	Cooldown             *metav1.Duration `json:"cooldown,omitempty"`
+
+	// Action heals the selected Pods unless ExitCodeActions selects another one.
+	Action          string            `json:"action,omitempty"`
+	ExitCodeActions map[string]string `json:"exitCodeActions,omitempty"`
+}
+
+// HealPolicyStatus reports whether the healer accepted the policy.
+type HealPolicyStatus struct {
+	ObservedGeneration int64  `json:"observedGeneration,omitempty"`
+	Valid              bool   `json:"valid"`
+	Message            string `json:"message,omitempty"`
+}
+
+// compiledPolicy is a validated HealPolicy ready for matching.
+type compiledPolicy struct {
+	name            string
+	selector        labels.Selector
+	settings        NamespaceOverride
+	action          string
+	exitCodeActions map[int32]string
+	disabled        bool
+}
+
+// compileHealPolicy validates the policy.
+func compileHealPolicy(p *HealPolicy) (*compiledPolicy, error) {
+	c := &compiledPolicy{name: p.Name, selector: labels.Everything(), action: p.Spec.Action}
+	if p.Spec.Selector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(p.Spec.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector: %w", err)
+		}
+		c.selector = selector
+	}
+	checks, err := parseChecks(p.Spec.FailureReasons)
+	if err != nil {
+		return nil, fmt.Errorf("invalid failureReasons: %w", err)
+	}
+	if c.action != "" && !validActions[c.action] {
+		return nil, fmt.Errorf("invalid action %q", c.action)
+	}
+	if p.Spec.RestartThreshold != nil && *p.Spec.RestartThreshold < 1 {
+		return nil, fmt.Errorf("invalid restartThreshold %d: must be at least 1", *p.Spec.RestartThreshold)
+	}
+	c.disabled = p.Spec.Enabled != nil && !*p.Spec.Enabled
+	exitCodeActions, err := ParseExitCodeActions(p.Spec.ExitCodeActions)
+	if err != nil {
+		return nil, err
+	}
+	c.exitCodeActions = exitCodeActions
+	c.settings = NamespaceOverride{
+		Namespace:            p.Namespace,
+		HealCooldown:         durationPtr(p.Spec.Cooldown),
+		MinPodAge:            durationPtr(p.Spec.MinPodAge),
+		MinUnhealthyDuration: durationPtr(p.Spec.MinUnhealthyDuration),
+		RestartThreshold:     p.Spec.RestartThreshold,
+		Checks:               checks,
+	}
+	return c, nil
+}
+
+// actionFor returns the policy's action for the failure, or "" if it sets none.
+func (c *compiledPolicy) actionFor(f *failure) string {
+	if f.Termination != nil {
+		if action, ok := c.exitCodeActions[f.Termination.ExitCode]; ok {
+			return action
+		}
+	}
+	return c.action
+}
+
+// policyStore holds the valid HealPolicies per namespace, sorted by name.
+type policyStore struct {
+	mu         sync.RWMutex
+	namespaces map[string][]*compiledPolicy
+}
+
+func newPolicyStore() *policyStore {
+	return &policyStore{namespaces: make(map[string][]*compiledPolicy)}
+}
+
+// match returns the policy governing the Pod, or nil.
+func (s *policyStore) match(pod *v1.Pod) *compiledPolicy {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, p := range s.namespaces[pod.Namespace] {
+		if p.selector.Matches(labels.Set(pod.Labels)) {
+			return p
+		}
+	}
+	return nil
+}
+
+// set stores or replaces the policy; a nil policy removes it.
+func (s *policyStore) set(namespace, name string, policy *compiledPolicy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var kept []*compiledPolicy
+	for _, p := range s.namespaces[namespace] {
+		if p.name != name {
+			kept = append(kept, p)
+		}
+	}
+	if policy != nil {
+		kept = append(kept, policy)
+		sort.Slice(kept, func(i, j int) bool { return kept[i].name < kept[j].name })
+	}
+	if len(kept) == 0 {
+		delete(s.namespaces, namespace)
+		return
+	}
+	s.namespaces[namespace] = kept
+}
+
+// count returns the number of valid policies.
+func (s *policyStore) count() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	n := 0
+	for _, policies := range s.namespaces {
+		n += len(policies)
+	}
+	return n
+}
+
+// maxCooldown returns the longest cooldown set by any policy.
+func (s *policyStore) maxCooldown() time.Duration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var longest time.Duration
+	for _, policies := range s.namespaces {
+		for _, p := range policies {
+			if p.settings.HealCooldown != nil && *p.settings.HealCooldown > longest {
+				longest = *p.settings.HealCooldown
+			}
+		}
+	}
+	return longest
+}
+
+// startPolicyController watches HealPolicies in all namespaces and reconciles them into the policy
+// store, reporting on each policy's status whether it was accepted.
+func (h *Healer) startPolicyController() {
+	if h.dynamicClient == nil {
+		h.Log.Warn("HealPolicies are not available without a dynamic client")
+		return
+	}
+	factory := dynamicinformer.NewDynamicSharedInformerFactory(h.dynamicClient, 10*time.Minute)
+	informer := factory.ForResource(HealPolicyGVR).Informer()
+	_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { h.reconcileHealPolicy(obj) },
+		UpdateFunc: func(oldObj, newObj interface{}) { h.reconcileHealPolicy(newObj) },
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if u, ok := obj.(*unstructured.Unstructured); ok {
+				h.policies.set(u.GetNamespace(), u.GetName(), nil)
+				h.Log.Info("HealPolicy removed", "policy", u.GetNamespace()+"/"+u.GetName())
+			}
+		},
+	})
+	if err != nil {
+		h.Log.Error("Failed to watch HealPolicies", "err", err)
+		return
+	}
+
+	factory.Start(h.StopCh)
+	go func() {
+		if !cache.WaitForCacheSync(h.StopCh, informer.HasSynced) {
+			return
+		}
+		h.Log.Info("Watching HealPolicies", "valid", h.policies.count())
+	}()
+}
+
+// reconcileHealPolicy stores a valid policy, or drops an invalid one, and records the outcome in
+// its status.
+func (h *Healer) reconcileHealPolicy(obj interface{}) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return
+	}
+	policy := &HealPolicy{}
+	err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, policy)
+	var compiled *compiledPolicy
+	if err == nil {
+		compiled, err = compileHealPolicy(policy)
+	}
+	h.policies.set(policy.Namespace, policy.Name, compiled)
+
+	status := HealPolicyStatus{ObservedGeneration: u.GetGeneration(), Valid: err == nil, Message: "Policy is in effect"}
+	if err != nil {
+		status.Message = err.Error()
+	}
+	// Resyncs and our own status updates redeliver policies that were already reconciled
+	if reflect.DeepEqual(status, policy.Status) {
+		return
+	}
+	if err != nil {
+		h.Log.Error("Ignoring invalid HealPolicy", "policy", u.GetNamespace()+"/"+u.GetName(), "err", err)
+	} else {
+		h.Log.Info("HealPolicy in effect", "policy", u.GetNamespace()+"/"+u.GetName())
+	}
+	h.updateHealPolicyStatus(u, status)
+}
+
+// updateHealPolicyStatus writes the policy status through the status subresource.
+func (h *Healer) updateHealPolicyStatus(u *unstructured.Unstructured, status HealPolicyStatus) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&status)
+	if err != nil {
+		return
+	}
+	updated := u.DeepCopy()
+	updated.Object["status"] = content
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err = h.dynamicClient.Resource(HealPolicyGVR).Namespace(u.GetNamespace()).UpdateStatus(ctx, updated, metav1.UpdateOptions{})
+	// A conflict means the policy changed meanwhile; its update is reconciled next
+	if err != nil && !apierrors.IsConflict(err) {
+		h.Log.Warn("Failed to update HealPolicy status", "policy", u.GetNamespace()+"/"+u.GetName(), "err", err)
+	}
+}
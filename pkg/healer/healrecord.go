@@ -0,0 +1,214 @@
+package healer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/daigoro86dev/k8s-healer/pkg/history"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/retry"
+)
+
+// HealRecordGVR identifies the HealRecord custom resource (see deploy/crds).
+var HealRecordGVR = schema.GroupVersionResource{Group: "k8s-healer.io", Version: "v1alpha1", Resource: "healrecords"}
+
+// DefaultHealRecordTTL is how long HealRecords are kept by default.
+const DefaultHealRecordTTL = 30 * 24 * time.Hour
+
+// HealRecord is the audit trail of one heal, created in the healed Pod's namespace. Unlike the
+// in-memory heal history it lives in the cluster, so heals can be listed with kubectl and outlive
+// the healer.
+type HealRecord struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   HealRecordSpec   `json:"spec"`
+	Status HealRecordStatus `json:"status,omitempty"`
+}
+
+// HealRecordSpec describes the heal.
+type HealRecordSpec struct {
+	Pod      string      `json:"pod"`
+	Owner    string      `json:"owner,omitempty"`
+	Check    string      `json:"check,omitempty"`
+	Reason   string      `json:"reason,omitempty"`
+	Action   string      `json:"action"`
+	Result   string      `json:"result"`
+	Error    string      `json:"error,omitempty"`
+	HealedAt metav1.Time `json:"healedAt"`
+	Healer   string      `json:"healer,omitempty"`
+}
+
+// HealRecordStatus reports what the heal achieved, once known.
+type HealRecordStatus struct {
+	Outcome         string       `json:"outcome,omitempty"`
+	OutcomeAt       *metav1.Time `json:"outcomeAt,omitempty"`
+	RecoverySeconds float64      `json:"recoverySeconds,omitempty"`
+}
+
+// Labels of HealRecords, for selecting them with kubectl.
+const (
+	healRecordCheckLabel  = "k8s-healer.io/check"
+	healRecordActionLabel = "k8s-healer.io/action"
+	healRecordResultLabel = "k8s-healer.io/result"
+)
+
+// healRecordName names the HealRecord of the heal of the Pod at the given time. Outcomes are
+// recorded by the same time and Pod, so the name doesn't need to be remembered.
+func healRecordName(at time.Time, pod string) string {
+	suffix := fmt.Sprintf("-%d", at.Unix())
+	if limit := 253 - len(suffix); len(pod) > limit {
+		pod = pod[:limit]
+	}
+	return pod + suffix
+}
+
+// createHealRecord creates the HealRecord of a heal. It is created before the heal's outcome can
+// be observed, so outcome updates always find it.
+func (h *Healer) createHealRecord(rec history.Record, err error) {
+	if !h.HealRecords || h.dynamicClient == nil {
+		return
+	}
+	record := &HealRecord{
+		TypeMeta: metav1.TypeMeta{APIVersion: HealRecordGVR.GroupVersion().String(), Kind: "HealRecord"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      healRecordName(rec.Time, rec.Pod),
+			Namespace: rec.Namespace,
+			Labels: map[string]string{
+				healRecordCheckLabel:  rec.Check,
+				healRecordActionLabel: rec.Action,
+				healRecordResultLabel: rec.Result,
+			},
+		},
+		Spec: HealRecordSpec{
+			Pod:      rec.Pod,
+			Owner:    rec.Owner,
+			Check:    rec.Check,
+			Reason:   rec.Reason,
+			Action:   rec.Action,
+			Result:   rec.Result,
+			HealedAt: metav1.NewTime(rec.Time),
+			Healer:   h.Identity(),
+		},
+	}
+	if err != nil {
+		record.Spec.Error = err.Error()
+	}
+	content, convErr := runtime.DefaultUnstructuredConverter.ToUnstructured(record)
+	if convErr != nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, createErr := h.dynamicClient.Resource(HealRecordGVR).Namespace(rec.Namespace).
+		Create(ctx, &unstructured.Unstructured{Object: content}, metav1.CreateOptions{})
+	if createErr != nil && !apierrors.IsAlreadyExists(createErr) {
+		h.Log.Warn("Failed to create HealRecord", "pod", rec.Namespace+"/"+rec.Pod, "err", createErr)
+	}
+}
+
+// updateHealRecord records the outcome of the heal of the Pod at the given time in its HealRecord,
+// without blocking the caller. A zero recovery keeps the recorded recovery time.
+func (h *Healer) updateHealRecord(at time.Time, namespace, pod, outcome string, recovery time.Duration) {
+	if !h.HealRecords || h.dynamicClient == nil {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		client := h.dynamicClient.Resource(HealRecordGVR).Namespace(namespace)
+		name := healRecordName(at, pod)
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			u, err := client.Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			now := metav1.Now()
+			fields := map[string]interface{}{"outcome": outcome, "outcomeAt": now.UTC().Format(time.RFC3339)}
+			if recovery > 0 {
+				fields["recoverySeconds"] = recovery.Seconds()
+			}
+			for field, value := range fields {
+				if err := unstructured.SetNestedField(u.Object, value, "status", field); err != nil {
+					return err
+				}
+			}
+			_, err = client.UpdateStatus(ctx, u, metav1.UpdateOptions{})
+			return err
+		})
+		// Records removed by hand or by the TTL have nothing to update
+		if err != nil && !apierrors.IsNotFound(err) {
+			h.Log.Warn("Failed to update HealRecord", "healRecord", namespace+"/"+name, "outcome", outcome, "err", err)
+		}
+	}()
+}
+
+// setOutcome records the outcome of the heal of the Pod at the given time.
+func (h *Healer) setOutcome(at time.Time, namespace, pod, outcome string) {
+	h.History.SetOutcome(at, namespace, pod, outcome)
+	h.updateHealRecord(at, namespace, pod, outcome, 0)
+}
+
+// setRecovered records that a replacement of the Pod healed at the given time became Ready.
+func (h *Healer) setRecovered(at time.Time, namespace, pod string, after time.Duration) {
+	h.History.SetRecovered(at, namespace, pod, after)
+	h.updateHealRecord(at, namespace, pod, history.OutcomeRecovered, after)
+}
+
+// startHealRecordCollector deletes HealRecords older than HealRecordTTL from the watched namespaces
+// every hour.
+func (h *Healer) startHealRecordCollector() {
+	if !h.HealRecords || h.dynamicClient == nil || h.HealRecordTTL <= 0 {
+		return
+	}
+	ticker := time.NewTicker(time.Hour)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C:
+				h.collectHealRecords(now)
+			case <-h.StopCh:
+				return
+			}
+		}
+	}()
+}
+
+func (h *Healer) collectHealRecords(now time.Time) {
+	namespaces := h.Namespaces
+	if h.discoversNamespaces() {
+		namespaces = h.watchedNamespaces()
+	}
+	deleted := 0
+	for _, namespace := range namespaces {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		client := h.dynamicClient.Resource(HealRecordGVR).Namespace(namespace)
+		list, err := client.List(ctx, metav1.ListOptions{})
+		if err != nil {
+			cancel()
+			h.Log.Warn("Failed to list HealRecords", "namespace", namespace, "err", err)
+			continue
+		}
+		for _, u := range list.Items {
+			record := &HealRecord{}
+			if runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, record) != nil ||
+				now.Sub(record.Spec.HealedAt.Time) < h.HealRecordTTL {
+				continue
+			}
+			if err := client.Delete(ctx, u.GetName(), metav1.DeleteOptions{}); err == nil || apierrors.IsNotFound(err) {
+				deleted++
+			}
+		}
+		cancel()
+	}
+	if deleted > 0 {
+		h.Log.Info("Deleted expired HealRecords", "deleted", deleted, "ttl", h.HealRecordTTL)
+	}
+}
@@ -0,0 +1,61 @@
+package healer
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/daigoro86dev/k8s-healer/pkg/notify"
+	v1 "k8s.io/api/core/v1"
+)
+
+// incidents remembers the workloads notified about a heal or an escalation, keyed by
+// namespace/Kind/Name, with the time of the latest such event.
+type incidents struct {
+	pending *pendingTracker[string, struct{}]
+}
+
+func newIncidents() *incidents {
+	return &incidents{pending: newPendingTracker[string, struct{}]()}
+}
+
+// opensIncident reports whether events of the type leave the workload awaiting recovery.
+func opensIncident(typ notify.EventType) bool {
+	switch typ {
+	case notify.EventHeal, notify.EventHealFailed, notify.EventHealUnrecovered, notify.EventQuarantined:
+		return true
+	}
+	return false
+}
+
+func (i *incidents) open(ownerKey string, at time.Time) {
+	i.pending.set(ownerKey, at, struct{}{})
+}
+
+// resolve takes the workload's incident if the Ready Pod was created after it opened.
+func (i *incidents) resolve(ownerKey string, pod *v1.Pod) (time.Time, bool) {
+	p, ok := i.pending.takeOldest(ownerKey, pod, nil)
+	return p.at, ok
+}
+
+func (i *incidents) empty() bool {
+	return i.pending.empty()
+}
+
+// observeWorkloadRecovery notifies that the Pod's workload recovered if the Pod is Ready and
+// replaces the ones the workload was last notified about.
+func (h *Healer) observeWorkloadRecovery(pod *v1.Pod) {
+	if h.Notifier == nil || h.incidents.empty() || !podReady(pod) {
+		return
+	}
+	owner := h.owners.Resolve(pod)
+	if owner == nil {
+		return
+	}
+	at, ok := h.incidents.resolve(owner.Namespace+"/"+owner.String(), pod)
+	if !ok {
+		return
+	}
+	h.notify(pod, notify.EventRecovered, notify.SeverityInfo, nil, "",
+		fmt.Sprintf("Workload recovered: Pod %s became Ready %s after the last heal or escalation.", pod.Name,
+			time.Since(at).Round(time.Second)))
+}
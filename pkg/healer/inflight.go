@@ -0,0 +1,72 @@
+package healer
+
+import (
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// inFlightHeals tracks heals in progress: Pods deleted or evicted by a heal whose replacement has
+// not been seen yet, by the UID of their controller. Until the controller creates a replacement,
+// updates to the terminating Pod are not checked again, so the burst of updates during termination
+// can't cause duplicate deletes. Heals whose replacement isn't seen within replacementWindow are
+// dropped.
+type inFlightHeals struct {
+	pods *pendingTracker[types.UID, inFlightPod] // Removed Pods by controller UID
+}
+
+// inFlightPod is a Pod removed by a heal.
+type inFlightPod struct {
+	namespace string
+	uid       types.UID
+}
+
+func newInFlightHeals() *inFlightHeals {
+	return &inFlightHeals{pods: newPendingTracker[types.UID, inFlightPod]()}
+}
+
+// start records that the Pod is being removed by a heal.
+func (t *inFlightHeals) start(pod *v1.Pod, at time.Time) {
+	var controller types.UID
+	if ref := metav1.GetControllerOf(pod); ref != nil {
+		controller = ref.UID
+	}
+	t.pods.add(controller, at, inFlightPod{namespace: pod.Namespace, uid: pod.UID})
+}
+
+// cancel forgets a heal whose Pod wasn't removed after all.
+func (t *inFlightHeals) cancel(pod *v1.Pod) {
+	t.pods.remove(func(p pendingEntry[inFlightPod]) bool { return p.value.uid == pod.UID })
+}
+
+// forgetNamespace drops the heals of Pods in the namespace.
+func (t *inFlightHeals) forgetNamespace(namespace string) {
+	t.pods.remove(func(p pendingEntry[inFlightPod]) bool { return p.value.namespace == namespace })
+}
+
+// active reports whether a heal of the Pod is still in progress.
+func (t *inFlightHeals) active(uid types.UID) bool {
+	return t.pods.find(func(p pendingEntry[inFlightPod]) bool {
+		return p.value.uid == uid && time.Since(p.at) <= replacementWindow
+	})
+}
+
+// replaced completes the oldest heal the new Pod may replace: one of the same controller that
+// happened before the Pod was created.
+func (t *inFlightHeals) replaced(pod *v1.Pod) {
+	if ref := metav1.GetControllerOf(pod); ref != nil {
+		t.pods.takeOldest(ref.UID, pod, nil)
+	}
+}
+
+// prune drops heals whose replacement never showed up.
+func (t *inFlightHeals) prune(now time.Time) {
+	t.pods.remove(func(p pendingEntry[inFlightPod]) bool { return now.Sub(p.at) > replacementWindow })
+}
+
+// count returns the number of heals in progress.
+func (t *inFlightHeals) count() int {
+	return t.pods.len()
+}
@@ -0,0 +1,89 @@
+package healer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/daigoro86dev/k8s-healer/pkg/annotations"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// defaultRemediationJobTTL is applied to remediation Jobs whose template sets no ttlSecondsAfterFinished.
+const defaultRemediationJobTTL = int32(24 * 60 * 60)
+
+// LoadJobTemplate reads a remediation Job template from a YAML or JSON file.
+func LoadJobTemplate(path string) (*batchv1.Job, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read job template: %w", err)
+	}
+	job := &batchv1.Job{}
+	if err := yaml.UnmarshalStrict(data, job); err != nil {
+		return nil, fmt.Errorf("failed to parse job template %s: %w", path, err)
+	}
+	if len(job.Spec.Template.Spec.Containers) == 0 {
+		return nil, fmt.Errorf("job template %s has no containers", path)
+	}
+	return job, nil
+}
+
+// runRemediationJob creates a Job from RemediationJobTemplate in the Pod's namespace. The failing
+// Pod is passed to every container through HEALER_* environment variables.
+func (h *Healer) runRemediationJob(ctx context.Context, pod *v1.Pod, owner *OwnerInfo, f *failure) error {
+	if h.RemediationJobTemplate == nil {
+		return fmt.Errorf("no remediation job template configured")
+	}
+
+	job := h.RemediationJobTemplate.DeepCopy()
+	job.Namespace = pod.Namespace
+	if job.Name != "" && job.GenerateName == "" {
+		job.GenerateName = job.Name + "-"
+	}
+	job.Name = ""
+	if job.GenerateName == "" {
+		job.GenerateName = "k8s-healer-remediation-"
+	}
+	if job.Labels == nil {
+		job.Labels = make(map[string]string)
+	}
+	job.Labels[annotations.ManagedByLabel] = annotations.ManagedBy
+	if job.Annotations == nil {
+		job.Annotations = make(map[string]string)
+	}
+	job.Annotations[annotations.RemediatedPod] = pod.Name
+	if job.Spec.TTLSecondsAfterFinished == nil {
+		ttl := defaultRemediationJobTTL
+		job.Spec.TTLSecondsAfterFinished = &ttl
+	}
+
+	env := []v1.EnvVar{
+		{Name: "HEALER_POD_NAME", Value: pod.Name},
+		{Name: "HEALER_POD_NAMESPACE", Value: pod.Namespace},
+		{Name: "HEALER_POD_UID", Value: string(pod.UID)},
+		{Name: "HEALER_OWNER", Value: owner.String()},
+		{Name: "HEALER_CHECK", Value: f.Check},
+		{Name: "HEALER_REASON", Value: f.Reason},
+	}
+	spec := &job.Spec.Template.Spec
+	for i := range spec.InitContainers {
+		spec.InitContainers[i].Env = append(spec.InitContainers[i].Env, env...)
+	}
+	for i := range spec.Containers {
+		spec.Containers[i].Env = append(spec.Containers[i].Env, env...)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	created, err := h.ClientSet.BatchV1().Jobs(pod.Namespace).Create(ctx, job, metav1.CreateOptions{})
+	if err != nil {
+		h.Log.Error("Failed to create remediation job", "pod", pod.Namespace+"/"+pod.Name, "err", err)
+		return err
+	}
+	h.Log.Info("Created remediation job", "job", created.Namespace+"/"+created.Name, "pod", pod.Namespace+"/"+pod.Name)
+	return nil
+}
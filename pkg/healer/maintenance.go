@@ -0,0 +1,32 @@
+package healer
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/daigoro86dev/k8s-healer/pkg/schedule"
+)
+
+// DefaultNoHealWindowDuration is how long a maintenance window stays open after its schedule fires.
+const DefaultNoHealWindowDuration = time.Hour
+
+// activeNoHealWindow returns the maintenance window open for the namespace and when it closes, or
+// nil. Windows without their own time zone are evaluated in the namespace's zone.
+func (h *Healer) activeNoHealWindow(namespace string) (*schedule.Window, time.Time) {
+	now := time.Now()
+	var (
+		active *schedule.Window
+		end    time.Time
+	)
+	for _, w := range h.NoHealWindows {
+		if open, until := w.ActiveAt(now, h.Timezones.For(namespace)); open && until.After(end) {
+			active, end = w, until
+		}
+	}
+	return active, end
+}
+
+// describeNoHealWindow formats a maintenance window for suppression reasons.
+func describeNoHealWindow(w *schedule.Window, until time.Time) string {
+	return fmt.Sprintf("maintenance window %q until %s", w.Cron, until.Format(time.RFC3339))
+}
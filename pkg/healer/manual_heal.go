@@ -0,0 +1,324 @@
+package healer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/daigoro86dev/k8s-healer/pkg/annotations"
+	"github.com/daigoro86dev/k8s-healer/pkg/notify"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// StrategyEvict removes Pods through the Eviction API regardless of UseEviction. It is only
+// available to manual heals.
+const StrategyEvict = "evict"
+
+// manualStrategies lists the strategies a manual heal may request.
+var manualStrategies = map[string]bool{
+	ActionDelete:         true,
+	StrategyEvict:        true,
+	ActionRolloutRestart: true,
+	ActionRollback:       true,
+}
+
+// ManualHealResult is the outcome of a manual heal for one Pod.
+type ManualHealResult struct {
+	Pod    string `json:"pod"`
+	Action string `json:"action"`
+	Error  string `json:"error,omitempty"`
+}
+
+// manualHealOp heals a Pod, or a workload's Pods, on request regardless of their health. Without a
+// strategy the default action is used. The heal passes the same guards as automatic heals (opt-outs,
+// cooldowns, pause, the circuit breaker, blackouts, freezes, maintenance windows, paused Deployments,
+// API health, the decision webhook, approvals, owner budgets, quotas and the heal rate limit).
+func (h *Healer) manualHealOp(req controlRequest, actor string) (int, interface{}) {
+	if req.Namespace == "" || (req.Pod == "") == (req.Name == "") {
+		return http.StatusBadRequest, map[string]string{"error": "namespace and either pod or kind and name are required"}
+	}
+	strategy := req.Strategy
+	switch {
+	case strategy == "" && req.Pod == "":
+		return http.StatusBadRequest, map[string]string{"error": "strategy is required to heal a workload"}
+	case strategy == "":
+		strategy = h.DefaultAction
+		if strategy == ActionNotify || strategy == ActionSkip {
+			strategy = ActionDelete
+		}
+	case !manualStrategies[strategy]:
+		return http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("invalid strategy %q (delete, evict, rollout-restart or rollback)", strategy)}
+	}
+
+	pods, target, status, err := h.manualHealTargets(req, strategy)
+	if err != nil {
+		return status, map[string]string{"error": err.Error()}
+	}
+
+	reason := fmt.Sprintf("manual heal requested by %s", actor)
+	if req.Reason != "" {
+		reason += ": " + req.Reason
+	}
+
+	// Guards are evaluated for the first Pod; pause, blackouts and the owner apply to all of them
+	owner := h.owners.Resolve(pods[0])
+	f := &failure{Check: checkManual, Reason: reason}
+	ctx, span := tracer.Start(context.Background(), "manual heal", trace.WithAttributes(
+		attribute.String("k8s.namespace.name", req.Namespace), attribute.String("k8s_healer.target", target),
+		attribute.String("k8s_healer.action", strategy), attribute.String("k8s_healer.reason", reason)))
+	defer span.End()
+	verdict, status, why := h.guardManualHeal(ctx, pods[0], owner, f, strategy)
+	if why != "" {
+		span.SetAttributes(attribute.String("k8s_healer.skip.cause", why))
+		return status, map[string]string{"target": target, "strategy": strategy, "error": why}
+	}
+	if verdict.action != strategy {
+		h.Log.Info("Decision webhook changed the action of the manual heal", "target", req.Namespace+"/"+target,
+			"strategy", strategy, "action", verdict.action, "reason", verdict.reason)
+		strategy = verdict.action
+		span.SetAttributes(attribute.String("k8s_healer.action", strategy))
+	}
+	if !h.claimWorkload(pods[0]) {
+		return http.StatusConflict, map[string]string{"target": target, "strategy": strategy, "error": "a heal of its workload is already in progress"}
+	}
+	defer h.releaseWorkload(pods[0])
+
+	// Workload-level strategies act once; the others act on every target Pod
+	switch strategy {
+	case ActionRolloutRestart, ActionRollback, ActionScaleCycle, ActionMemoryBump:
+		pods = pods[:1]
+	}
+	results := make([]ManualHealResult, 0, len(pods))
+	failed := false
+	cooled := make(map[string]bool)
+	for _, pod := range pods {
+		// The guards checked the first Pod's opt-out; the others may opt out (or not opt in) on their own
+		if h.optedOut(pod, owner) != "" {
+			results = append(results, ManualHealResult{Pod: pod.Name, Action: ActionSkip})
+			continue
+		}
+		f := &failure{Check: checkManual, Reason: f.Reason}
+		if !h.heals.acquire(h.HealConcurrency, h.StopCh) {
+			return http.StatusServiceUnavailable, map[string]string{"target": target, "strategy": strategy, "error": "the healer is stopping"}
+		}
+		taken, err := h.performManualHeal(ctx, strategy, pod, owner, f)
+		h.heals.done()
+		if owner != nil {
+			h.ownerHeals.record(owner.Namespace+"/"+owner.String(), time.Now())
+		}
+		h.recordBreakerHeal()
+		// Pods of one workload share its cooldown, so a heal of several continues its streak once
+		if key := cooldownKey(pod); !cooled[key] {
+			cooled[key] = true
+			if err == nil {
+				h.recordCooldown(pod, time.Now(), verdict.cooldown)
+			} else {
+				h.markHealed(pod, time.Now())
+			}
+		}
+		rec := h.recordHeal(pod, f, taken, err)
+		h.trackEffectiveness(owner, rec)
+		h.expectRecovery(pod, owner, f, rec)
+
+		result := ManualHealResult{Pod: pod.Name, Action: taken}
+		if err != nil {
+			failed = true
+			result.Error = err.Error()
+			h.notify(pod, notify.EventHealFailed, notify.SeverityCritical, f, taken, err.Error())
+		} else {
+			h.notify(pod, notify.EventHeal, notify.SeverityWarning, f, taken, actionMessage(taken))
+		}
+		results = append(results, result)
+	}
+
+	status = http.StatusOK
+	if failed {
+		status = http.StatusBadGateway
+	}
+	return status, map[string]interface{}{"target": target, "strategy": strategy, "results": results}
+}
+
+// manualHealTargets returns the Pods a manual heal acts on and a description of the target. For a
+// workload, delete and evict target its unready Pods; the other strategies need any one of its Pods,
+// preferring the newest, which belongs to the current revision.
+func (h *Healer) manualHealTargets(req controlRequest, strategy string) ([]*v1.Pod, string, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if req.Pod != "" {
+		pod, err := h.ClientSet.CoreV1().Pods(req.Namespace).Get(ctx, req.Pod, metav1.GetOptions{})
+		if err != nil {
+			return nil, "", http.StatusNotFound, err
+		}
+		return []*v1.Pod{pod}, fmt.Sprintf("Pod %s/%s", req.Namespace, req.Pod), 0, nil
+	}
+
+	var (
+		uid      types.UID
+		selector *metav1.LabelSelector
+		kind     string
+	)
+	switch strings.ToLower(req.Kind) {
+	case "deployment", "deploy":
+		d, err := h.ClientSet.AppsV1().Deployments(req.Namespace).Get(ctx, req.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, "", http.StatusNotFound, err
+		}
+		uid, selector, kind = d.UID, d.Spec.Selector, "Deployment"
+	case "statefulset", "sts":
+		if strategy == ActionRollback {
+			return nil, "", http.StatusBadRequest, fmt.Errorf("rollback is only supported for Deployments")
+		}
+		s, err := h.ClientSet.AppsV1().StatefulSets(req.Namespace).Get(ctx, req.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, "", http.StatusNotFound, err
+		}
+		uid, selector, kind = s.UID, s.Spec.Selector, "StatefulSet"
+	default:
+		return nil, "", http.StatusBadRequest, fmt.Errorf("unsupported kind %q (Deployment or StatefulSet)", req.Kind)
+	}
+	target := fmt.Sprintf("%s %s/%s", kind, req.Namespace, req.Name)
+
+	list, err := h.ClientSet.CoreV1().Pods(req.Namespace).List(ctx, metav1.ListOptions{LabelSelector: metav1.FormatLabelSelector(selector)})
+	if err != nil {
+		return nil, "", http.StatusBadGateway, fmt.Errorf("failed to list pods of %s: %w", target, err)
+	}
+	var pods []*v1.Pod
+	for i := range list.Items {
+		pod := &list.Items[i]
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		if owner := h.owners.Resolve(pod); owner == nil || owner.UID != uid {
+			continue
+		}
+		if (strategy == ActionDelete || strategy == StrategyEvict) && podReady(pod) {
+			continue
+		}
+		pods = append(pods, pod)
+	}
+	if len(pods) == 0 {
+		if strategy == ActionDelete || strategy == StrategyEvict {
+			return nil, "", http.StatusConflict, fmt.Errorf("%s has no unready pods; name a pod to heal a ready one", target)
+		}
+		return nil, "", http.StatusConflict, fmt.Errorf("%s has no pods", target)
+	}
+	sort.Slice(pods, func(i, j int) bool { return pods[j].CreationTimestamp.Before(&pods[i].CreationTimestamp) })
+	return pods, target, 0, nil
+}
+
+// guardManualHeal applies the guards of automatic heals to a manual one, in the same order. It
+// returns the decision webhook's verdict, whose action replaces the strategy and whose cooldown
+// applies once the heal is carried out, or the HTTP status and the reason the heal must not happen.
+func (h *Healer) guardManualHeal(ctx context.Context, pod *v1.Pod, owner *OwnerInfo, f *failure, strategy string) (decisionVerdict, int, string) {
+	refuse := func(status int, why string) (decisionVerdict, int, string) {
+		return decisionVerdict{}, status, why
+	}
+	if why := h.protection(pod); why != "" {
+		return refuse(http.StatusForbidden, why)
+	}
+	if h.ExcludesNamespace(pod.Namespace) {
+		return refuse(http.StatusConflict, fmt.Sprintf("namespace %s is excluded", pod.Namespace))
+	}
+	if !h.selectsPod(pod) {
+		return refuse(http.StatusConflict, fmt.Sprintf("pod doesn't match the label selector %q", h.PodLabelSelector))
+	}
+	if len(h.OwnerKinds) > 0 && !h.eligibleOwner(pod) {
+		return refuse(http.StatusConflict, fmt.Sprintf("%s isn't one of the healed owner kinds (%s)", owner, strings.Join(h.OwnerKinds, ", ")))
+	}
+	if h.inFlight.active(pod.UID) {
+		return refuse(http.StatusConflict, "a heal of this pod is already in progress")
+	}
+	h.refreshCooldown(pod)
+	if mark, ok := h.coolingDown(pod); ok {
+		return refuse(http.StatusConflict, fmt.Sprintf("cooling down until %s after a heal at %s; clear the cooldown first",
+			mark.Until.Format(time.RFC3339), mark.At.Format(time.RFC3339)))
+	}
+	if why := h.optedOut(pod, owner); why != "" {
+		return refuse(http.StatusConflict, why)
+	}
+	if h.quarantined(owner) {
+		return refuse(http.StatusConflict, fmt.Sprintf("%s is quarantined; remove the %s label first", owner, annotations.Quarantined))
+	}
+	if cause, why := h.halted(pod.Namespace); cause != "" {
+		return refuse(http.StatusConflict, why)
+	}
+	if owner != nil && owner.Deployment != nil && owner.Deployment.Spec.Paused && h.PausedDeploymentBehavior != PausedHeal {
+		return refuse(http.StatusConflict, fmt.Sprintf("%s is paused", owner))
+	}
+	if why := h.controllerPreflight(ctx, owner); why != "" {
+		return refuse(http.StatusConflict, why)
+	}
+	if ok, why := h.apiAllows(actionHeal); !ok {
+		return refuse(http.StatusServiceUnavailable, why)
+	}
+
+	verdict, why := h.consultDecision(ctx, pod, owner, f, strategy)
+	if why != "" {
+		return refuse(http.StatusConflict, why)
+	}
+
+	if h.requiresApproval(pod.Namespace) {
+		approver := approvedBy(pod)
+		if approver == "" {
+			h.requestApproval(pod, owner, f, verdict.action)
+			return refuse(http.StatusAccepted, "heal awaits approval")
+		}
+		f.Reason = fmt.Sprintf("%s (approved by %s)", f.Reason, approver)
+	}
+	if why := h.ownerBudgetExceeded(pod, owner); why != "" {
+		return refuse(http.StatusTooManyRequests, why)
+	}
+	if verdict.action == ActionDelete {
+		if why := h.pdbBlocksDelete(ctx, pod); why != "" {
+			return refuse(http.StatusConflict, fmt.Sprintf("pod %s: %s", pod.Name, why))
+		}
+		if why := h.quotaShortfall(ctx, pod); why != "" {
+			return refuse(http.StatusConflict, fmt.Sprintf("pod %s: %s", pod.Name, why))
+		}
+	}
+
+	// Last, so a heal refused by another guard doesn't use up the rate limit
+	if !h.healAllowed() {
+		return refuse(http.StatusTooManyRequests, fmt.Sprintf("heal rate limit reached (%d per minute)", h.MaxHealsPerMinute))
+	}
+	return verdict, 0, ""
+}
+
+// performManualHeal executes the strategy. Unlike automatic heals, a workload-level strategy that
+// can't be applied fails instead of falling back to deleting the Pod.
+func (h *Healer) performManualHeal(ctx context.Context, strategy string, pod *v1.Pod, owner *OwnerInfo, f *failure) (string, error) {
+	switch strategy {
+	case StrategyEvict:
+		return StrategyEvict, h.removePod(ctx, pod, true, f)
+	case ActionRolloutRestart:
+		if owner == nil || (owner.Deployment == nil && owner.StatefulSet == nil) {
+			return ActionRolloutRestart, fmt.Errorf("%s can't be rollout-restarted", owner)
+		}
+		return ActionRolloutRestart, h.rolloutRestart(ctx, pod, owner)
+	case ActionRollback:
+		previous, err := h.rollbackTarget(ctx, owner)
+		if err != nil {
+			return ActionRollback, fmt.Errorf("not rolling back: %w", err)
+		}
+		return ActionRollback, h.rollbackDeployment(ctx, pod, owner, previous)
+	}
+	return h.performAction(ctx, strategy, pod, owner, f)
+}
+
+// podReady reports whether the Pod's Ready condition is true.
+func podReady(pod *v1.Pod) bool {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == v1.PodReady {
+			return c.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
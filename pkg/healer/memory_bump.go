@@ -0,0 +1,126 @@
+package healer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// DefaultMemoryBumpPercent is how much ActionMemoryBump raises a container's memory limit by.
+const DefaultMemoryBumpPercent = 25
+
+// DefaultMemoryBumpMax caps the memory limits ActionMemoryBump sets.
+var DefaultMemoryBumpMax = resource.MustParse("4Gi")
+
+// oomKilled reports whether the failure is a container killed for exceeding its memory limit.
+func oomKilled(f *failure) bool {
+	return f != nil && f.Termination != nil && f.Termination.Reason == "OOMKilled"
+}
+
+// oomRestarts returns how often the OOM-killed container of the failure restarted, and the Pod's
+// restart threshold it must reach before its limit is raised: a single OOM kill, e.g. during a load
+// spike, is no reason to grow the workload.
+func (h *Healer) oomRestarts(pod *v1.Pod, f *failure) (restarts, threshold int32) {
+	for _, s := range pod.Status.ContainerStatuses {
+		if s.Name == f.Termination.Container {
+			restarts = s.RestartCount
+		}
+	}
+	return restarts, h.restartThresholdFor(pod)
+}
+
+// memoryBumpTarget returns the template of the owning workload whose limit can be raised for the
+// OOM-killed container, or an error explaining why the bump does not apply.
+func memoryBumpTarget(owner *OwnerInfo, f *failure) (*v1.PodTemplateSpec, error) {
+	if !oomKilled(f) {
+		return nil, fmt.Errorf("failure is not an OOM kill")
+	}
+	switch {
+	case owner == nil:
+		return nil, fmt.Errorf("pod has no owner")
+	case owner.Deployment != nil:
+		return &owner.Deployment.Spec.Template, nil
+	case owner.StatefulSet != nil:
+		return &owner.StatefulSet.Spec.Template, nil
+	}
+	return nil, fmt.Errorf("memory bump is not supported for %s", owner)
+}
+
+// bumpedMemoryLimit raises the limit by MemoryBumpPercent, capped at MemoryBumpMax.
+func (h *Healer) bumpedMemoryLimit(current resource.Quantity) (resource.Quantity, error) {
+	max := h.MemoryBumpMax
+	if current.Cmp(max) >= 0 {
+		return resource.Quantity{}, fmt.Errorf("memory limit %s is already at the %s cap", current.String(), max.String())
+	}
+	bumped := resource.NewQuantity(current.Value()+current.Value()*int64(h.MemoryBumpPercent)/100, resource.BinarySI)
+	if bumped.Cmp(max) > 0 {
+		return max, nil
+	}
+	return *bumped, nil
+}
+
+// bumpMemory raises the memory limit of the OOM-killed container in the Pod's owning Deployment or
+// StatefulSet, which rolls out new Pods. Deleting the Pod would only restart the same OOM loop.
+func (h *Healer) bumpMemory(ctx context.Context, pod *v1.Pod, owner *OwnerInfo, template *v1.PodTemplateSpec, container string) error {
+	var current *resource.Quantity
+	for i := range template.Spec.Containers {
+		if template.Spec.Containers[i].Name == container {
+			if limit, ok := template.Spec.Containers[i].Resources.Limits[v1.ResourceMemory]; ok {
+				current = &limit
+			}
+		}
+	}
+	if current == nil {
+		return fmt.Errorf("container %s of %s has no memory limit", container, owner)
+	}
+
+	// Pods of the old revision keep getting OOM-killed until they are replaced; bump once per revision.
+	for _, c := range pod.Spec.Containers {
+		if limit, ok := c.Resources.Limits[v1.ResourceMemory]; ok && c.Name == container && current.Cmp(limit) > 0 {
+			h.Log.Info("Memory limit already raised; not bumping again", "owner", owner.String(), "container", container,
+				"limit", current.String())
+			return nil
+		}
+	}
+
+	bumped, err := h.bumpedMemoryLimit(*current)
+	if err != nil {
+		return err
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []map[string]interface{}{{
+						"name": container,
+						"resources": map[string]interface{}{
+							"limits": map[string]string{string(v1.ResourceMemory): bumped.String()},
+						},
+					}},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to build memory patch: %w", err)
+	}
+
+	if owner.Deployment != nil {
+		_, err = h.ClientSet.AppsV1().Deployments(owner.Namespace).Patch(ctx, owner.Name, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+	} else {
+		_, err = h.ClientSet.AppsV1().StatefulSets(owner.Namespace).Patch(ctx, owner.Name, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+	}
+	if err != nil {
+		h.Log.Error("Failed to raise the memory limit", "owner", owner.String(), "pod", pod.Namespace+"/"+pod.Name, "err", err)
+		return err
+	}
+
+	h.Log.Info("Raised memory limit", "owner", owner.String(), "container", container, "from", current.String(), "to", bumped.String())
+	return nil
+}
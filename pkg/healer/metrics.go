@@ -0,0 +1,168 @@
+package healer
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/daigoro86dev/k8s-healer/pkg/metrics"
+	"github.com/daigoro86dev/k8s-healer/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Causes of skipped heals, the cause label of k8s_healer_skips_total.
+const (
+	skipCooldown          = "cooldown"
+	skipMinAge            = "min-age"
+	skipWorkloadBusy      = "workload-busy"
+	skipOptedOut          = "opted-out"
+	skipQuarantined       = "quarantined"
+	skipChronic           = "chronic"
+	skipPaused            = "paused"
+	skipBreaker           = "circuit-breaker"
+	skipBlackout          = "blackout"
+	skipFreeze            = "freeze"
+	skipMaintenanceWindow = "maintenance-window"
+	skipPausedDeployment  = "paused-deployment"
+	skipNoController      = "no-controller"
+	skipPolicy            = "policy"
+	skipAPIDegraded       = "api-degraded"
+	skipDecisionWebhook   = "decision-webhook"
+	skipApproval          = "approval"
+	skipRateLimit         = "rate-limit"
+	skipOwnerBudget       = "owner-budget"
+	skipPDB               = "pdb"
+	skipQuota             = "quota"
+)
+
+// healerMetrics are the counters served on /metrics; gauges are read from the healer when scraped.
+type healerMetrics struct {
+	heals       *metrics.CounterVec
+	skips       *metrics.CounterVec
+	watchErrors *metrics.CounterVec
+	celErrors   *metrics.CounterVec
+	namespaces  *metrics.CounterVec
+}
+
+func newHealerMetrics() *healerMetrics {
+	return &healerMetrics{
+		heals: metrics.NewCounterVec("k8s_healer_heals_total",
+			"Healing actions carried out, by namespace, check that detected the failure and result.",
+			"namespace", "reason", "result"),
+		skips: metrics.NewCounterVec("k8s_healer_skips_total",
+			"Unhealthy Pods that were not healed, by cause.", "cause"),
+		watchErrors: metrics.NewCounterVec("k8s_healer_watch_errors_total",
+			"Errors of the list and watch calls of the informers, by resource.", "resource"),
+		celErrors: metrics.NewCounterVec("k8s_healer_cel_errors_total",
+			"Evaluations of custom conditions that failed, by expression.", "expression"),
+		namespaces: metrics.NewCounterVec("k8s_healer_namespaces_forgotten_total",
+			"Namespaces no longer watched and forgotten, by cause.", "cause"),
+	}
+}
+
+// countHeal counts a healing action.
+func (h *Healer) countHeal(namespace, check, result string) {
+	if h.metrics != nil {
+		h.metrics.heals.Inc(namespace, check, result)
+	}
+}
+
+// countSkip counts an unhealthy Pod that wasn't healed.
+func (h *Healer) countSkip(cause string) {
+	if h.metrics != nil {
+		h.metrics.skips.Inc(cause)
+	}
+}
+
+// countNamespaceForgotten counts a namespace that is no longer watched.
+func (h *Healer) countNamespaceForgotten(cause string) {
+	if h.metrics != nil {
+		h.metrics.namespaces.Inc(cause)
+	}
+}
+
+// celError identifies a reported evaluation error of a custom condition.
+type celError struct {
+	expression string
+	err        string
+}
+
+// celError counts a custom condition that failed to evaluate for the Pod, and logs each distinct
+// error of an expression once; the same error for other Pods is logged at debug level.
+func (h *Healer) celError(pod *v1.Pod, c *util.CELCondition, err error) {
+	if h.metrics != nil {
+		h.metrics.celErrors.Inc(c.Expression)
+	}
+	key := celError{expression: c.Expression, err: err.Error()}
+	if _, warned := h.celErrors.LoadOrStore(key, true); !warned {
+		h.Log.Warn("Custom condition failed to evaluate; treating it as not matched", "pod", pod.Namespace+"/"+pod.Name,
+			"condition", c.Expression, "err", err)
+		return
+	}
+	h.Log.Debug("Custom condition failed to evaluate", "pod", pod.Namespace+"/"+pod.Name, "condition", c.Expression, "err", err)
+}
+
+// countWatchErrors counts the watch errors of an informer before handing them to the default
+// handler, which logs them.
+func (h *Healer) countWatchErrors(resource string, informer cache.SharedIndexInformer) {
+	if h.metrics == nil {
+		return
+	}
+	err := informer.SetWatchErrorHandlerWithContext(func(ctx context.Context, r *cache.Reflector, err error) {
+		h.metrics.watchErrors.Inc(resource)
+		cache.DefaultWatchErrorHandler(ctx, r, err)
+	})
+	if err != nil {
+		h.Log.Warn("Can't count watch errors", "resource", resource, "err", err)
+	}
+}
+
+// cooldownsActive counts the workloads and Pods cooling down.
+func (h *Healer) cooldownsActive() int {
+	now := time.Now()
+	active := 0
+	h.healedMu.Lock()
+	h.HealedPods.each(func(_ string, mark healMark) {
+		if mark.Until.After(now) {
+			active++
+		}
+	})
+	h.healedMu.Unlock()
+	return active
+}
+
+// MetricsHandler serves the healer's metrics in the Prometheus text format.
+func (h *Healer) MetricsHandler() http.Handler {
+	reg := metrics.NewRegistry()
+	if h.metrics != nil {
+		reg.Register(h.metrics.heals, h.metrics.skips, h.metrics.watchErrors, h.metrics.celErrors, h.metrics.namespaces)
+	}
+	reg.Register(metrics.CollectorFunc(func(w *metrics.Writer) {
+		w.Gauge("k8s_healer_cooldown_active", "Workloads and Pods whose heal cooldown hasn't expired.",
+			float64(h.cooldownsActive()))
+		w.Gauge("k8s_healer_heals_in_progress", "Removed Pods whose replacement isn't seen yet.",
+			float64(h.inFlight.count()))
+
+		q := h.queue.stats()
+		w.Gauge("k8s_healer_queue_depth", "Pod updates waiting to be checked.", float64(q.Depth))
+		w.Gauge("k8s_healer_queue_capacity", "Capacity of the Pod processing queue.", float64(q.Capacity))
+		w.Header("k8s_healer_queue_merged_total", "Pod updates folded into an already queued update.", "counter")
+		w.Sample("k8s_healer_queue_merged_total", float64(q.Merged))
+		w.Header("k8s_healer_queue_dropped_total", "Pod updates dropped because the queue was full.", "counter")
+		w.Sample("k8s_healer_queue_dropped_total", float64(q.Dropped))
+		w.Header("k8s_healer_queue_retries_total", "Heals requeued after a transient failure.", "counter")
+		w.Sample("k8s_healer_queue_retries_total", float64(q.Retried))
+
+		hist := h.recoveryStats.histogram()
+		counts := make([]int, len(hist.Buckets))
+		for i, b := range hist.Buckets {
+			counts[i] = b.Count
+		}
+		w.Histogram("k8s_healer_recovery_seconds", "Time from a heal until a replacement of the healed Pod was Ready.",
+			RecoveryBuckets, counts, hist.Count, hist.SumSeconds)
+		w.Header("k8s_healer_unrecovered_total", "Heals whose replacement didn't become Ready within the recovery timeout.", "counter")
+		w.Sample("k8s_healer_unrecovered_total", float64(hist.Unrecovered))
+	}))
+	return reg.Handler()
+}
@@ -0,0 +1,353 @@
+package healer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/daigoro86dev/k8s-healer/pkg/annotations"
+	"github.com/daigoro86dev/k8s-healer/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// namespaceWatches tracks the running per-namespace watches, their stop channels and, once synced,
+// their Pod listers.
+type namespaceWatches struct {
+	mu      sync.Mutex
+	stopCh  map[string]chan struct{}
+	listers map[string]corelisters.PodLister
+}
+
+// startNamespaceWatch starts watching the namespace unless it is already watched. If given,
+// onSynced is called once with the Pod lister after the caches synced, or nil if they never do.
+// With a cluster-wide watch the namespace is only let through its filter, and onSynced gets nil.
+func (h *Healer) startNamespaceWatch(namespace string, onSynced func(corelisters.PodLister)) {
+	h.watches.mu.Lock()
+	defer h.watches.mu.Unlock()
+	if h.watches.stopCh == nil {
+		h.watches.stopCh = make(map[string]chan struct{})
+	}
+	if _, ok := h.watches.stopCh[namespace]; ok {
+		if onSynced != nil {
+			onSynced(nil)
+		}
+		return
+	}
+	stopCh := make(chan struct{})
+	h.watches.stopCh[namespace] = stopCh
+	if h.clusterWatch {
+		if onSynced != nil {
+			onSynced(nil)
+		}
+		return
+	}
+
+	// Stop with the healer as well as on namespace deletion
+	go func() {
+		select {
+		case <-h.StopCh:
+			h.stopNamespaceWatch(namespace)
+		case <-stopCh:
+		}
+	}()
+	go h.watchSingleNamespace(namespace, stopCh, onSynced)
+}
+
+// stopNamespaceWatch stops the namespace's informers. It returns false if it was not watched.
+func (h *Healer) stopNamespaceWatch(namespace string) bool {
+	h.watches.mu.Lock()
+	defer h.watches.mu.Unlock()
+	stopCh, ok := h.watches.stopCh[namespace]
+	if !ok {
+		return false
+	}
+	close(stopCh)
+	delete(h.watches.stopCh, namespace)
+	delete(h.watches.listers, namespace)
+	return true
+}
+
+// matchesAnyNamespace reports whether the namespace matches one of the names, globs or regexes.
+func matchesAnyNamespace(patterns []string, namespace string) bool {
+	for _, pattern := range patterns {
+		if util.MatchNamespace(pattern, namespace) {
+			return true
+		}
+	}
+	return false
+}
+
+// isNamespaceWatched reports whether the namespace is currently watched.
+func (h *Healer) isNamespaceWatched(namespace string) bool {
+	h.watches.mu.Lock()
+	defer h.watches.mu.Unlock()
+	_, ok := h.watches.stopCh[namespace]
+	return ok
+}
+
+// watchedNamespaces returns the namespaces currently watched.
+func (h *Healer) watchedNamespaces() []string {
+	h.watches.mu.Lock()
+	defer h.watches.mu.Unlock()
+	out := make([]string, 0, len(h.watches.stopCh))
+	for ns := range h.watches.stopCh {
+		out = append(out, ns)
+	}
+	return out
+}
+
+// watchNamespaceLifecycle follows the configured namespaces: when one is deleted its watch is
+// stopped and the state kept for it is dropped, and when it is recreated it is watched again.
+// Watching all namespaces needs none of this, as Pods of a deleted namespace simply disappear.
+func (h *Healer) watchNamespaceLifecycle(configured []string) {
+	if len(configured) == 0 || (len(configured) == 1 && configured[0] == metav1.NamespaceAll) {
+		return
+	}
+
+	// Tracking namespaces needs cluster-wide list/watch on them; carry on without it otherwise.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	_, err := h.ClientSet.CoreV1().Namespaces().List(ctx, metav1.ListOptions{Limit: 1})
+	cancel()
+	if err != nil {
+		h.Log.Warn("Can't watch namespaces; deleted namespaces won't be detected", "err", err)
+		return
+	}
+
+	wanted := make(map[string]bool, len(configured))
+	for _, ns := range configured {
+		wanted[ns] = true
+	}
+
+	factory := informers.NewSharedInformerFactory(h.ClientSet, time.Minute)
+	informer := factory.Core().V1().Namespaces().Informer()
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			ns, ok := obj.(*v1.Namespace)
+			if !ok || !wanted[ns.Name] || ns.Status.Phase == v1.NamespaceTerminating {
+				return
+			}
+			h.startNamespaceWatch(ns.Name, nil)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			ns, ok := obj.(*v1.Namespace)
+			if !ok || !wanted[ns.Name] {
+				return
+			}
+			h.forgetNamespace(ns.Name, namespaceDeleted)
+		},
+	})
+	factory.Start(h.StopCh)
+}
+
+// Causes of forgotten namespaces, the cause label of k8s_healer_namespaces_forgotten_total.
+const (
+	namespaceDeleted    = "deleted"
+	namespaceUnselected = "unselected"
+)
+
+// forgetNamespace stops watching a namespace that was deleted or is no longer selected, and drops
+// everything remembered about its Pods and workloads: cooldowns, owner heal budgets, quarantines,
+// heals in progress and heals awaiting a replacement, recovery or incident resolution.
+func (h *Healer) forgetNamespace(namespace, cause string) {
+	if !h.stopNamespaceWatch(namespace) {
+		return
+	}
+	cooldowns := h.clearCooldowns(namespace, "*")
+	h.suppressed.forgetNamespace(namespace)
+	h.approvals.forgetNamespace(namespace)
+	h.events.forgetNamespace(namespace)
+	h.effectiveness.forgetNamespace(namespace)
+	h.ownerHeals.forgetNamespace(namespace)
+	h.quarantines.forgetNamespace(namespace)
+	h.inFlight.forgetNamespace(namespace)
+	h.replacements.forgetNamespace(namespace)
+	h.recoveries.forgetNamespace(namespace)
+	h.incidents.forgetNamespace(namespace)
+	h.owners.unregister(namespace)
+	h.countNamespaceForgotten(cause)
+	h.Log.Info("Stopped watching namespace; it is watched again if it returns", "namespace", namespace, "why", cause,
+		"clearedCooldowns", cooldowns)
+}
+
+// discoversNamespaces reports whether the watched namespaces are selected live, by pattern or label,
+// rather than from a fixed list.
+func (h *Healer) discoversNamespaces() bool {
+	return len(h.NamespacePatterns) > 0 || h.NamespaceSelector != "" || h.WatchAnnotatedNamespaces
+}
+
+// describeNamespaceSelection formats the namespace patterns and selector for logs.
+func (h *Healer) describeNamespaceSelection() string {
+	var parts []string
+	if patterns := append(append([]string{}, h.Namespaces...), h.NamespacePatterns...); len(patterns) > 0 {
+		parts = append(parts, "namespaces "+strings.Join(patterns, ","))
+	}
+	if h.NamespaceSelector != "" {
+		parts = append(parts, fmt.Sprintf("selector %q", h.NamespaceSelector))
+	}
+	if h.WatchAnnotatedNamespaces {
+		parts = append(parts, fmt.Sprintf("annotated %s=true", annotations.Watch))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// startNamespaceDiscovery selects the watched namespaces live instead of from a fixed list: those
+// named in Namespaces or matching NamespacePatterns, NamespaceSelector and, with
+// WatchAnnotatedNamespaces, the watch annotation. It returns the namespaces selected now and keeps
+// the selection current: namespaces that are created, labeled or annotated to match are watched,
+// and those that stop matching or are deleted are dropped.
+func (h *Healer) startNamespaceDiscovery() ([]string, error) {
+	selector, err := labels.Parse(h.NamespaceSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid namespace selector: %w", err)
+	}
+	var patterns []string
+	for _, ns := range h.Namespaces {
+		if ns != metav1.NamespaceAll {
+			patterns = append(patterns, ns)
+		}
+	}
+	patterns = append(patterns, h.NamespacePatterns...)
+	selected := func(ns *v1.Namespace) bool {
+		if ns.Status.Phase == v1.NamespaceTerminating || h.ExcludesNamespace(ns.Name) {
+			return false
+		}
+		if len(patterns) > 0 && !matchesAnyNamespace(patterns, ns.Name) {
+			return false
+		}
+		if h.WatchAnnotatedNamespaces && !annotations.IsTrue(ns.Annotations, annotations.Watch) {
+			return false
+		}
+		return selector.Matches(labels.Set(ns.Labels))
+	}
+
+	// Events are only acted on once the initial selection has been handed to the caller; anything
+	// missed in between is caught up by the next resync.
+	var ready atomic.Bool
+	reconcile := func(obj interface{}) {
+		ns, ok := obj.(*v1.Namespace)
+		if !ok || !ready.Load() {
+			return
+		}
+		if selected(ns) {
+			if h.isNamespaceWatched(ns.Name) {
+				return
+			}
+			h.Log.Info("Namespace now matches the namespace selection; watching it", "namespace", ns.Name)
+			h.startNamespaceWatch(ns.Name, nil)
+		} else {
+			h.forgetNamespace(ns.Name, namespaceUnselected)
+		}
+	}
+
+	factory := informers.NewSharedInformerFactory(h.ClientSet, time.Minute)
+	informer := factory.Core().V1().Namespaces().Informer()
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    reconcile,
+		UpdateFunc: func(oldObj, newObj interface{}) { reconcile(newObj) },
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if ns, ok := obj.(*v1.Namespace); ok && ready.Load() {
+				h.forgetNamespace(ns.Name, namespaceDeleted)
+			}
+		},
+	})
+	factory.Start(h.StopCh)
+	if !cache.WaitForCacheSync(h.StopCh, informer.HasSynced) {
+		return nil, fmt.Errorf("failed to list namespaces")
+	}
+
+	var initial []string
+	for _, obj := range informer.GetStore().List() {
+		if ns, ok := obj.(*v1.Namespace); ok && selected(ns) {
+			initial = append(initial, ns.Name)
+		}
+	}
+	sort.Strings(initial)
+	h.clusterWatch = h.useClusterWatch(initial)
+	ready.Store(true)
+	return initial, nil
+}
+
+// namespacePrefix is the prefix of namespace/name keys in the namespace.
+func namespacePrefix(namespace string) string {
+	return namespace + "/"
+}
+
+func (s *suppressions) forgetNamespace(namespace string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key := range s.lastAt {
+		if strings.HasPrefix(key, namespacePrefix(namespace)) {
+			delete(s.lastAt, key)
+		}
+	}
+}
+
+func (s *eventSignals) forgetNamespace(namespace string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key := range s.byPod {
+		if strings.HasPrefix(key, namespacePrefix(namespace)) {
+			delete(s.byPod, key)
+		}
+	}
+}
+
+func (o *ownerHeals) forgetNamespace(namespace string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for key := range o.heals {
+		if strings.HasPrefix(key, namespacePrefix(namespace)) {
+			delete(o.heals, key)
+		}
+	}
+}
+
+func (q *quarantines) forgetNamespace(namespace string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for key := range q.at {
+		if strings.HasPrefix(key, namespacePrefix(namespace)) {
+			delete(q.at, key)
+		}
+	}
+}
+
+func (t *recoveryTracker) forgetNamespace(namespace string) {
+	t.pending.remove(func(p pendingEntry[pendingRecovery]) bool { return p.value.pod.Namespace == namespace })
+}
+
+func (i *incidents) forgetNamespace(namespace string) {
+	i.pending.forget(func(ownerKey string) bool { return strings.HasPrefix(ownerKey, namespacePrefix(namespace)) })
+}
+
+func (t *effectivenessTracker) forgetNamespace(namespace string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for key := range t.pending {
+		if strings.HasPrefix(key, namespacePrefix(namespace)) {
+			delete(t.pending, key)
+		}
+	}
+}
+
+// unregister drops the listers of a namespace that is no longer watched.
+func (c *ownerCache) unregister(namespace string) {
+	c.mu.Lock()
+	delete(c.listers, namespace)
+	c.mu.Unlock()
+}
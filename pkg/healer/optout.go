@@ -0,0 +1,140 @@
+package healer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/daigoro86dev/k8s-healer/pkg/annotations"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Operating modes: in ModeOptOut (default) every Pod is healed unless it opted out, in ModeOptIn
+// only Pods that opted in are.
+const (
+	ModeOptOut = "opt-out"
+	ModeOptIn  = "opt-in"
+)
+
+// namespaceOptOutTTL is how long a namespace's enabled annotation is cached.
+const namespaceOptOutTTL = time.Minute
+
+// namespaceOptOutRetry is how soon a namespace that couldn't be read is read again.
+const namespaceOptOutRetry = 10 * time.Second
+
+// namespaceOptOuts caches whether namespaces opted out of (or in to) healing through the enabled
+// annotation.
+type namespaceOptOuts struct {
+	clientset kubernetes.Interface
+
+	mu      sync.Mutex
+	entries map[string]namespaceOptOut
+	warned  bool
+}
+
+type namespaceOptOut struct {
+	disabled  bool
+	optedIn   bool
+	err       error // The namespace couldn't be read
+	fetchedAt time.Time
+}
+
+func newNamespaceOptOuts(clientset kubernetes.Interface) *namespaceOptOuts {
+	return &namespaceOptOuts{clientset: clientset, entries: make(map[string]namespaceOptOut)}
+}
+
+// get returns the namespace's cached enabled annotation. A namespace that can't be read might have
+// opted out, so it is reported as disabled with the error, and read again after namespaceOptOutRetry;
+// the first such failure is logged to log.
+func (n *namespaceOptOuts) get(namespace string, log *slog.Logger) namespaceOptOut {
+	n.mu.Lock()
+	if e, ok := n.entries[namespace]; ok && time.Since(e.fetchedAt) < e.ttl() {
+		n.mu.Unlock()
+		return e
+	}
+	n.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ns, err := n.clientset.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	e := namespaceOptOut{fetchedAt: time.Now()}
+	if err == nil {
+		e.disabled = annotations.IsDisabled(ns.Annotations)
+		e.optedIn = annotations.IsOptedIn(ns.Annotations)
+	} else {
+		e.disabled, e.err = true, err
+		if !n.warned {
+			n.warned = true
+			log.Warn("Can't read namespace; not healing its pods until it can be read", "namespace", namespace, "err", err)
+		}
+	}
+	n.entries[namespace] = e
+	return e
+}
+
+// ttl returns how long the entry is cached.
+func (e namespaceOptOut) ttl() time.Duration {
+	if e.err != nil {
+		return namespaceOptOutRetry
+	}
+	return namespaceOptOutTTL
+}
+
+// optedOut returns why the enabled annotation excludes the Pod from healing, or "" if it may be
+// healed. "false" on the Pod, its workload or its namespace always excludes it; in opt-in mode, one
+// of them must also carry "true".
+func (h *Healer) optedOut(pod *v1.Pod, owner *OwnerInfo) string {
+	disabledOn := func(by string) string {
+		return fmt.Sprintf("healing is disabled on the %s (%s=false)", by, annotations.Enabled)
+	}
+	optedIn := annotations.IsOptedIn(pod.Annotations)
+	if annotations.IsDisabled(pod.Annotations) {
+		return disabledOn("pod")
+	}
+	if owner != nil {
+		if ann := workloadAnnotations(owner); annotations.IsDisabled(ann) {
+			return disabledOn(owner.String())
+		} else if annotations.IsOptedIn(ann) {
+			optedIn = true
+		}
+		if rs := owner.ReplicaSet; rs != nil {
+			if annotations.IsDisabled(rs.Annotations) {
+				return disabledOn(fmt.Sprintf("ReplicaSet/%s", rs.Name))
+			} else if annotations.IsOptedIn(rs.Annotations) {
+				optedIn = true
+			}
+		}
+	}
+	if h.namespaceOptOuts != nil {
+		ns := h.namespaceOptOuts.get(pod.Namespace, h.Log)
+		if ns.err != nil {
+			return fmt.Sprintf("namespace %s can't be read to check its %s annotation: %v", pod.Namespace, annotations.Enabled, ns.err)
+		}
+		if ns.disabled {
+			return disabledOn(fmt.Sprintf("namespace %s", pod.Namespace))
+		}
+		optedIn = optedIn || ns.optedIn
+	}
+	if h.Mode == ModeOptIn && !optedIn {
+		return fmt.Sprintf("it isn't opted in (%s=true on the pod, its workload or namespace)", annotations.Enabled)
+	}
+	return ""
+}
+
+// workloadAnnotations returns the annotations of the owning Deployment or StatefulSet.
+func workloadAnnotations(owner *OwnerInfo) map[string]string {
+	switch {
+	case owner.Deployment != nil:
+		return owner.Deployment.Annotations
+	case owner.StatefulSet != nil:
+		return owner.StatefulSet.Annotations
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+package healer
+
+import (
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/daigoro86dev/k8s-healer/pkg/annotations"
+	"github.com/daigoro86dev/k8s-healer/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// NamespaceOverride replaces healing settings for the namespaces matching a pattern. Unset fields
+// keep the setting of a less specific override, or the global setting.
+type NamespaceOverride struct {
+	Namespace            string // Namespace name, glob (e.g. "batch-*") or "re:" regex
+	HealCooldown         *time.Duration
+	MinPodAge            *time.Duration
+	MinUnhealthyDuration *time.Duration
+	RestartThreshold     *int32          // Restarts before the crashloop and startup-probe checks fail
+	Checks               map[string]bool // Checks that may heal the namespace's Pods; all if empty
+	Action               string          // Default action for the namespace; exit code and check actions still take precedence
+}
+
+// allowsCheck reports whether failures detected by the check may heal the Pod.
+func (o *NamespaceOverride) allowsCheck(check string) bool {
+	return len(o.Checks) == 0 || o.Checks[check]
+}
+
+// overridesFor returns the overrides matching the namespace, most specific first: an exact name
+// before globs, globs with more literal characters before broader ones, and regexes last. Equally
+// specific overrides keep their configured order.
+func (h *Healer) overridesFor(namespace string) []*NamespaceOverride {
+	var matched []*NamespaceOverride
+	for i := range h.NamespaceOverrides {
+		if util.MatchNamespace(h.NamespaceOverrides[i].Namespace, namespace) {
+			matched = append(matched, &h.NamespaceOverrides[i])
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		return util.GlobSpecificity(matched[i].Namespace) > util.GlobSpecificity(matched[j].Namespace)
+	})
+	return matched
+}
+
+// settingsFor returns the settings overriding the global ones for the Pod, most specific first:
+// the HealPolicy selecting the Pod, then the overrides of its namespace.
+func (h *Healer) settingsFor(pod *v1.Pod) []*NamespaceOverride {
+	var settings []*NamespaceOverride
+	if p := h.policies.match(pod); p != nil {
+		settings = append(settings, &p.settings)
+	}
+	return append(settings, h.overridesFor(pod.Namespace)...)
+}
+
+// cooldownFor returns the heal cooldown applying to the Pod.
+func (h *Healer) cooldownFor(pod *v1.Pod) time.Duration {
+	for _, ann := range h.overrideAnnotations(pod) {
+		d, ok, err := annotations.ParseCooldown(ann)
+		if err != nil {
+			h.warnInvalidAnnotation(pod, err)
+			continue
+		}
+		if ok {
+			h.annotatedCooldowns.observe(d)
+			return d
+		}
+	}
+	for _, s := range h.settingsFor(pod) {
+		if s.HealCooldown != nil {
+			return *s.HealCooldown
+		}
+	}
+	return h.HealCooldown
+}
+
+// minPodAgeFor returns the minimum age of the Pod before it is healed.
+func (h *Healer) minPodAgeFor(pod *v1.Pod) time.Duration {
+	for _, s := range h.settingsFor(pod) {
+		if s.MinPodAge != nil {
+			return *s.MinPodAge
+		}
+	}
+	return h.MinPodAge
+}
+
+// minUnhealthyDurationFor returns how long the Pod must stay unhealthy before it is healed.
+func (h *Healer) minUnhealthyDurationFor(pod *v1.Pod) time.Duration {
+	for _, s := range h.settingsFor(pod) {
+		if s.MinUnhealthyDuration != nil {
+			return *s.MinUnhealthyDuration
+		}
+	}
+	return h.MinUnhealthyDuration
+}
+
+// restartThresholdFor returns how often a container of the Pod must restart before it is healed.
+func (h *Healer) restartThresholdFor(pod *v1.Pod) int32 {
+	for _, ann := range h.overrideAnnotations(pod) {
+		n, ok, err := annotations.ParseRestartThreshold(ann)
+		if err != nil {
+			h.warnInvalidAnnotation(pod, err)
+			continue
+		}
+		if ok {
+			return n
+		}
+	}
+	for _, s := range h.settingsFor(pod) {
+		if s.RestartThreshold != nil {
+			return *s.RestartThreshold
+		}
+	}
+	return h.RestartThreshold
+}
+
+// checkAllowedFor reports whether failures detected by the check may heal the Pod. The most specific
+// settings restricting the checks decide.
+func (h *Healer) checkAllowedFor(pod *v1.Pod, check string) bool {
+	for _, s := range h.settingsFor(pod) {
+		if len(s.Checks) > 0 {
+			return s.allowsCheck(check)
+		}
+	}
+	return true
+}
+
+// defaultActionFor returns the action for failures of the Pod not selected by exit code or check.
+func (h *Healer) defaultActionFor(pod *v1.Pod) string {
+	for _, s := range h.settingsFor(pod) {
+		if s.Action != "" {
+			return s.Action
+		}
+	}
+	return h.DefaultAction
+}
+
+// overrideAnnotations returns the annotations settings may be overridden on for the Pod, most
+// specific first: the Pod's, its Deployment's or StatefulSet's, and its ReplicaSet's.
+func (h *Healer) overrideAnnotations(pod *v1.Pod) []map[string]string {
+	sources := []map[string]string{pod.Annotations}
+	if owner := h.owners.Resolve(pod); owner != nil {
+		switch {
+		case owner.Deployment != nil:
+			sources = append(sources, owner.Deployment.Annotations)
+		case owner.StatefulSet != nil:
+			sources = append(sources, owner.StatefulSet.Annotations)
+		}
+		if owner.ReplicaSet != nil {
+			sources = append(sources, owner.ReplicaSet.Annotations)
+		}
+	}
+	return sources
+}
+
+// invalidAnnotation identifies a reported invalid override annotation of a Pod.
+type invalidAnnotation struct {
+	pod types.UID
+	err string
+}
+
+// warnInvalidAnnotation reports an unparsable override annotation once per Pod; the setting it
+// overrides falls back to the next source.
+func (h *Healer) warnInvalidAnnotation(pod *v1.Pod, err error) {
+	key := invalidAnnotation{pod: pod.UID, err: err.Error()}
+	if _, warned := h.invalidAnnotations.LoadOrStore(key, true); !warned {
+		h.Log.Warn("Ignoring invalid annotation", "pod", pod.Namespace+"/"+pod.Name, "err", err)
+	}
+}
+
+// forgetInvalidAnnotations drops the reported invalid annotations of a deleted Pod.
+func (h *Healer) forgetInvalidAnnotations(pod *v1.Pod) {
+	h.invalidAnnotations.Range(func(key, _ any) bool {
+		if key.(invalidAnnotation).pod == pod.UID {
+			h.invalidAnnotations.Delete(key)
+		}
+		return true
+	})
+}
+
+// cooldownHighWater tracks the longest cooldown set through annotations, so heal times are kept
+// long enough for it.
+type cooldownHighWater struct {
+	longest atomic.Int64
+}
+
+func (c *cooldownHighWater) observe(d time.Duration) {
+	for {
+		cur := c.longest.Load()
+		if int64(d) <= cur || c.longest.CompareAndSwap(cur, int64(d)) {
+			return
+		}
+	}
+}
+
+// maxCooldown returns the longest cooldown of any namespace, policy or annotation, which bounds how
+// long heal times are kept.
+func (h *Healer) maxCooldown() time.Duration {
+	longest := h.HealCooldown
+	if d := time.Duration(h.annotatedCooldowns.longest.Load()); d > longest {
+		longest = d
+	}
+	for _, o := range h.NamespaceOverrides {
+		if o.HealCooldown != nil && *o.HealCooldown > longest {
+			longest = *o.HealCooldown
+		}
+	}
+	if d := h.policies.maxCooldown(); d > longest {
+		longest = d
+	}
+	return longest
+}
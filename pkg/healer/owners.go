@@ -0,0 +1,197 @@
+package healer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// OwnerInfo describes the top-level controller managing a Pod, resolved through the owner chain
+// (Pod -> ReplicaSet -> Deployment, or Pod -> StatefulSet).
+type OwnerInfo struct {
+	Kind      string
+	Name      string
+	Namespace string
+	UID       types.UID
+
+	// The resolved controller objects, when available. These point into the informer cache
+	// and must be treated as read-only.
+	ReplicaSet  *appsv1.ReplicaSet
+	Deployment  *appsv1.Deployment
+	StatefulSet *appsv1.StatefulSet
+}
+
+// String returns the owner in Kind/Name form (e.g. "Deployment/api").
+func (o *OwnerInfo) String() string {
+	if o == nil {
+		return "<none>"
+	}
+	return fmt.Sprintf("%s/%s", o.Kind, o.Name)
+}
+
+// Summary returns a short human-readable description of the owner's rollout state.
+func (o *OwnerInfo) Summary() string {
+	switch {
+	case o == nil:
+		return "<none>"
+	case o.Deployment != nil:
+		d := o.Deployment
+		return fmt.Sprintf("%s (replicas: %d desired, %d ready, %d updated)",
+			o, replicasOrDefault(d.Spec.Replicas), d.Status.ReadyReplicas, d.Status.UpdatedReplicas)
+	case o.StatefulSet != nil:
+		s := o.StatefulSet
+		return fmt.Sprintf("%s (replicas: %d desired, %d ready)",
+			o, replicasOrDefault(s.Spec.Replicas), s.Status.ReadyReplicas)
+	case o.ReplicaSet != nil:
+		rs := o.ReplicaSet
+		return fmt.Sprintf("%s (replicas: %d desired, %d ready)",
+			o, replicasOrDefault(rs.Spec.Replicas), rs.Status.ReadyReplicas)
+	}
+	return o.String()
+}
+
+// replicasOrDefault returns the desired replica count, applying the API default of 1 when unset.
+func replicasOrDefault(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
+
+// ownerListers groups the apps/v1 listers registered on one namespace-scoped informer factory.
+type ownerListers struct {
+	replicaSets  appslisters.ReplicaSetLister
+	deployments  appslisters.DeploymentLister
+	statefulSets appslisters.StatefulSetLister
+}
+
+// ownerCache is a read-through cache for Pod owners. Lookups are served from informer listers
+// and only fall back to a live GET when the object is not (yet) in the cache, so decision
+// enrichment does not issue an API call per Pod event.
+type ownerCache struct {
+	client  kubernetes.Interface
+	mu      sync.RWMutex
+	listers map[string]*ownerListers // keyed by watched namespace (metav1.NamespaceAll for cluster-wide)
+}
+
+func newOwnerCache(client kubernetes.Interface) *ownerCache {
+	return &ownerCache{
+		client:  client,
+		listers: make(map[string]*ownerListers),
+	}
+}
+
+// register wires the owner informers into the given factory and returns their HasSynced funcs.
+// The informers start together with the factory.
+func (c *ownerCache) register(namespace string, factory informers.SharedInformerFactory) []cache.InformerSynced {
+	apps := factory.Apps().V1()
+	l := &ownerListers{
+		replicaSets:  apps.ReplicaSets().Lister(),
+		deployments:  apps.Deployments().Lister(),
+		statefulSets: apps.StatefulSets().Lister(),
+	}
+
+	c.mu.Lock()
+	c.listers[namespace] = l
+	c.mu.Unlock()
+
+	return []cache.InformerSynced{
+		apps.ReplicaSets().Informer().HasSynced,
+		apps.Deployments().Informer().HasSynced,
+		apps.StatefulSets().Informer().HasSynced,
+	}
+}
+
+// listersFor returns the listers covering the given namespace, or nil if none are registered.
+func (c *ownerCache) listersFor(namespace string) *ownerListers {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if l, ok := c.listers[namespace]; ok {
+		return l
+	}
+	return c.listers[metav1.NamespaceAll]
+}
+
+// Resolve walks the controller chain of the Pod and returns its top-level owner.
+// It returns nil if the Pod has no controller reference.
+func (c *ownerCache) Resolve(pod *v1.Pod) *OwnerInfo {
+	ref := metav1.GetControllerOf(pod)
+	if ref == nil {
+		return nil
+	}
+
+	info := &OwnerInfo{Kind: ref.Kind, Name: ref.Name, Namespace: pod.Namespace, UID: ref.UID}
+
+	switch ref.Kind {
+	case "ReplicaSet":
+		rs, err := c.getReplicaSet(pod.Namespace, ref.Name)
+		if err != nil || rs == nil {
+			return info
+		}
+		info.ReplicaSet = rs
+
+		// Most ReplicaSets are managed by a Deployment; report the Deployment as the owner.
+		if dref := metav1.GetControllerOf(rs); dref != nil && dref.Kind == "Deployment" {
+			info.Kind, info.Name, info.UID = dref.Kind, dref.Name, dref.UID
+			if d, err := c.getDeployment(pod.Namespace, dref.Name); err == nil {
+				info.Deployment = d
+			}
+		}
+	case "StatefulSet":
+		if sts, err := c.getStatefulSet(pod.Namespace, ref.Name); err == nil {
+			info.StatefulSet = sts
+		}
+	}
+
+	return info
+}
+
+func (c *ownerCache) getReplicaSet(namespace, name string) (*appsv1.ReplicaSet, error) {
+	if l := c.listersFor(namespace); l != nil {
+		if rs, err := l.replicaSets.ReplicaSets(namespace).Get(name); err == nil {
+			return rs, nil
+		} else if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return c.client.AppsV1().ReplicaSets(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+func (c *ownerCache) getDeployment(namespace, name string) (*appsv1.Deployment, error) {
+	if l := c.listersFor(namespace); l != nil {
+		if d, err := l.deployments.Deployments(namespace).Get(name); err == nil {
+			return d, nil
+		} else if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return c.client.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+func (c *ownerCache) getStatefulSet(namespace, name string) (*appsv1.StatefulSet, error) {
+	if l := c.listersFor(namespace); l != nil {
+		if sts, err := l.statefulSets.StatefulSets(namespace).Get(name); err == nil {
+			return sts, nil
+		} else if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return c.client.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+}
@@ -0,0 +1,33 @@
+package healer
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// Behaviors for Pods whose Deployment is paused (spec.paused=true).
+const (
+	PausedSkip   = "skip"   // Leave the Pod alone and only log
+	PausedNotify = "notify" // Notify-only: report the failure but don't heal
+	PausedHeal   = "heal"   // Heal as usual
+)
+
+// handlePausedDeployment applies PausedDeploymentBehavior when the Pod belongs to a paused Deployment.
+// Operators pause a rollout to preserve its current state for debugging, so by default we don't touch it.
+// It returns true if the heal must not proceed.
+func (h *Healer) handlePausedDeployment(pod *v1.Pod, owner *OwnerInfo, f *failure) bool {
+	if owner == nil || owner.Deployment == nil || !owner.Deployment.Spec.Paused {
+		return false
+	}
+
+	switch h.PausedDeploymentBehavior {
+	case PausedHeal:
+		return false
+	case PausedSkip:
+		h.Log.Info("Not healing: workload is paused", "pod", pod.Namespace+"/"+pod.Name, "owner", owner.String())
+	default:
+		h.suppressHeal(pod, f, fmt.Sprintf("%s is paused", owner))
+	}
+	return true
+}
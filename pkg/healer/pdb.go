@@ -0,0 +1,48 @@
+package healer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// pdbBlocksDelete returns why deleting the Pod directly, bypassing the Eviction API, would take its
+// workload below a PodDisruptionBudget, or "" if it wouldn't. Only Ready Pods count toward a
+// budget, so an unready Pod can always be deleted; a Ready one needs a budget with disruptions
+// left. If the budgets can't be read the delete is deferred, unless listing them is forbidden.
+func (h *Healer) pdbBlocksDelete(ctx context.Context, pod *v1.Pod) string {
+	if !h.RespectPDBs || h.UseEviction || !podReady(pod) {
+		return ""
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	pdbs, err := h.ClientSet.PolicyV1().PodDisruptionBudgets(pod.Namespace).List(ctx, metav1.ListOptions{})
+	if apierrors.IsForbidden(err) {
+		h.Log.Warn("Can't list PodDisruptionBudgets; deleting without checking them", "pod", pod.Namespace+"/"+pod.Name, "err", err)
+		return ""
+	}
+	if err != nil {
+		return fmt.Sprintf("its PodDisruptionBudgets can't be checked (%v)", err)
+	}
+	var exhausted []string
+	for _, pdb := range pdbs.Items {
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil || selector.Empty() || !selector.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+		if pdb.Status.DisruptionsAllowed < 1 {
+			exhausted = append(exhausted, pdb.Name)
+		}
+	}
+	if len(exhausted) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("it is Ready and deleting it would violate PodDisruptionBudget %s (no disruptions allowed)",
+		strings.Join(exhausted, ", "))
+}
@@ -0,0 +1,177 @@
+package healer
+
+import (
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// pendingEntry is a heal awaiting a Pod that its controller creates afterwards.
+type pendingEntry[T comparable] struct {
+	at    time.Time
+	value T
+}
+
+// createdSince reports whether the Pod was created at or after t. Creation timestamps have second
+// precision, so a Pod created within the second of t counts.
+func createdSince(pod *v1.Pod, t time.Time) bool {
+	return !pod.CreationTimestamp.Time.Before(t.Truncate(time.Second))
+}
+
+// pendingTracker pairs heals with the Pods created afterwards by the healed Pod's controller, keyed
+// by the controller (its UID or owner key). It backs the replacement, recovery, in-flight and
+// incident trackers.
+type pendingTracker[K comparable, T comparable] struct {
+	mu      sync.Mutex
+	pending map[K][]pendingEntry[T] // Oldest first
+}
+
+func newPendingTracker[K comparable, T comparable]() *pendingTracker[K, T] {
+	return &pendingTracker[K, T]{pending: make(map[K][]pendingEntry[T])}
+}
+
+// add records a heal of the controller.
+func (t *pendingTracker[K, T]) add(key K, at time.Time, value T) {
+	t.mu.Lock()
+	t.pending[key] = append(t.pending[key], pendingEntry[T]{at: at, value: value})
+	t.mu.Unlock()
+}
+
+// set replaces the heals of the controller by one.
+func (t *pendingTracker[K, T]) set(key K, at time.Time, value T) {
+	t.mu.Lock()
+	t.pending[key] = []pendingEntry[T]{{at: at, value: value}}
+	t.mu.Unlock()
+}
+
+// has reports whether heals of the controller are pending.
+func (t *pendingTracker[K, T]) has(key K) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.pending[key]) > 0
+}
+
+// takeOldest takes the oldest heal of the controller the Pod was created after and match, if not
+// nil, accepts.
+func (t *pendingTracker[K, T]) takeOldest(key K, pod *v1.Pod, match func(pendingEntry[T]) bool) (pendingEntry[T], bool) {
+	taken := t.take(key, pod, match, false)
+	if len(taken) == 0 {
+		return pendingEntry[T]{}, false
+	}
+	return taken[0], true
+}
+
+// takeAll takes every heal of the controller the Pod was created after and match, if not nil,
+// accepts.
+func (t *pendingTracker[K, T]) takeAll(key K, pod *v1.Pod, match func(pendingEntry[T]) bool) []pendingEntry[T] {
+	return t.take(key, pod, match, true)
+}
+
+func (t *pendingTracker[K, T]) take(key K, pod *v1.Pod, match func(pendingEntry[T]) bool, all bool) []pendingEntry[T] {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var taken, kept []pendingEntry[T]
+	for _, p := range t.pending[key] {
+		if (all || len(taken) == 0) && createdSince(pod, p.at) && (match == nil || match(p)) {
+			taken = append(taken, p)
+		} else {
+			kept = append(kept, p)
+		}
+	}
+	t.store(key, kept)
+	return taken
+}
+
+// remove drops and returns the heals drop accepts.
+func (t *pendingTracker[K, T]) remove(drop func(pendingEntry[T]) bool) []pendingEntry[T] {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var removed []pendingEntry[T]
+	for key, pending := range t.pending {
+		var kept []pendingEntry[T]
+		for _, p := range pending {
+			if drop(p) {
+				removed = append(removed, p)
+			} else {
+				kept = append(kept, p)
+			}
+		}
+		t.store(key, kept)
+	}
+	return removed
+}
+
+// forget drops the heals of the controllers drop accepts.
+func (t *pendingTracker[K, T]) forget(drop func(K) bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for key := range t.pending {
+		if drop(key) {
+			delete(t.pending, key)
+		}
+	}
+}
+
+// removeValue drops the heal of the controller recorded with value.
+func (t *pendingTracker[K, T]) removeValue(key K, value T) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var kept []pendingEntry[T]
+	for _, p := range t.pending[key] {
+		if p.value != value {
+			kept = append(kept, p)
+		}
+	}
+	t.store(key, kept)
+}
+
+// find reports whether a pending heal matches.
+func (t *pendingTracker[K, T]) find(match func(pendingEntry[T]) bool) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, pending := range t.pending {
+		for _, p := range pending {
+			if match(p) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// empty reports whether no heals are pending.
+func (t *pendingTracker[K, T]) empty() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.pending) == 0
+}
+
+// len returns the number of pending heals.
+func (t *pendingTracker[K, T]) len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	n := 0
+	for _, pending := range t.pending {
+		n += len(pending)
+	}
+	return n
+}
+
+// values returns the values of the heals.
+func values[T comparable](heals []pendingEntry[T]) []T {
+	out := make([]T, 0, len(heals))
+	for _, p := range heals {
+		out = append(out, p.value)
+	}
+	return out
+}
+
+// store sets the controller's heals, dropping the key when none are left. Callers hold mu.
+func (t *pendingTracker[K, T]) store(key K, pending []pendingEntry[T]) {
+	if len(pending) == 0 {
+		delete(t.pending, key)
+	} else {
+		t.pending[key] = pending
+	}
+}
@@ -0,0 +1,58 @@
+package healer
+
+import (
+	"context"
+	"strings"
+
+	"github.com/daigoro86dev/k8s-healer/pkg/annotations"
+	v1 "k8s.io/api/core/v1"
+)
+
+// preDeleteHook returns the command and container of the pre-delete hook for the Pod. The Pod's
+// annotations override the configured command and container.
+func (h *Healer) preDeleteHook(pod *v1.Pod) ([]string, string) {
+	command := h.PreDeleteCommand
+	if override := annotations.ParsePreDeleteCommand(pod.Annotations); len(override) > 0 {
+		command = override
+	}
+	container := h.PreDeleteContainer
+	if override := pod.Annotations[annotations.PreDeleteContainer]; override != "" {
+		container = override
+	}
+	return command, container
+}
+
+// runPreDeleteHook execs the pre-delete hook (e.g. a graceful drain or a diagnostics dump) in a
+// running container of the Pod before it is deleted. Failures are logged but never block the heal:
+// a Pod that needs healing may well be unable to run the hook.
+func (h *Healer) runPreDeleteHook(ctx context.Context, pod *v1.Pod) {
+	command, container := h.preDeleteHook(pod)
+	if len(command) == 0 {
+		return
+	}
+
+	if container == "" {
+		for _, cs := range pod.Status.ContainerStatuses {
+			if cs.State.Running != nil {
+				container = cs.Name
+				break
+			}
+		}
+	}
+	if container == "" || !runningContainer(pod, container) {
+		h.Log.Info("No running container to run the pre-delete hook in", "pod", pod.Namespace+"/"+pod.Name)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, h.PreDeleteTimeout)
+	defer cancel()
+
+	stdout, stderr, err := h.execInContainer(ctx, pod, container, command)
+	if err != nil {
+		h.Log.Warn("Pre-delete hook failed", "pod", pod.Namespace+"/"+pod.Name, "container", container, "command", strings.Join(command, " "),
+			"err", err, "stderr", strings.TrimSpace(stderr))
+		return
+	}
+	h.Log.Info("Ran pre-delete hook", "pod", pod.Namespace+"/"+pod.Name, "container", container, "command", strings.Join(command, " "),
+		"stdout", strings.TrimSpace(stdout))
+}
@@ -0,0 +1,73 @@
+package healer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// controllerPreflight returns why the Pod's controller can't be relied on to replace the Pod, or ""
+// if it can: it no longer exists or is being deleted, is scaled to zero, or can't be looked up.
+// Healing then would permanently remove capacity instead of restoring it. Paused Deployments are
+// handled separately, see PausedDeploymentBehavior. Owners of kinds the healer doesn't know are
+// trusted.
+func (h *Healer) controllerPreflight(ctx context.Context, owner *OwnerInfo) string {
+	if owner == nil {
+		return ""
+	}
+	var (
+		meta     *metav1.ObjectMeta
+		replicas *int32
+		err      error
+	)
+	switch owner.Kind {
+	case "Deployment":
+		d := owner.Deployment
+		if d == nil {
+			d, err = h.owners.getDeployment(owner.Namespace, owner.Name)
+		}
+		if err == nil {
+			meta, replicas = &d.ObjectMeta, d.Spec.Replicas
+		}
+	case "StatefulSet":
+		sts := owner.StatefulSet
+		if sts == nil {
+			sts, err = h.owners.getStatefulSet(owner.Namespace, owner.Name)
+		}
+		if err == nil {
+			meta, replicas = &sts.ObjectMeta, sts.Spec.Replicas
+		}
+	case "ReplicaSet":
+		rs := owner.ReplicaSet
+		if rs == nil {
+			rs, err = h.owners.getReplicaSet(owner.Namespace, owner.Name)
+		}
+		if err == nil {
+			meta, replicas = &rs.ObjectMeta, rs.Spec.Replicas
+		}
+	case "DaemonSet":
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		ds, getErr := h.ClientSet.AppsV1().DaemonSets(owner.Namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+		if err = getErr; err == nil {
+			meta = &ds.ObjectMeta
+		}
+	default:
+		return ""
+	}
+
+	switch {
+	case apierrors.IsNotFound(err):
+		return fmt.Sprintf("its controller %s no longer exists", owner)
+	case err != nil:
+		return fmt.Sprintf("its controller %s can't be verified (%v)", owner, err)
+	case meta.DeletionTimestamp != nil:
+		return fmt.Sprintf("its controller %s is being deleted", owner)
+	case replicas != nil && *replicas == 0:
+		return fmt.Sprintf("its controller %s is scaled to zero", owner)
+	}
+	return ""
+}
@@ -0,0 +1,46 @@
+package healer
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/daigoro86dev/k8s-healer/pkg/util"
+	v1 "k8s.io/api/core/v1"
+)
+
+// SystemNamespaces are protected unless ProtectSystemNamespaces is turned off: the healer never acts
+// on the cluster's own components, even when a wildcard such as "kube-*" or all namespaces are
+// watched.
+var SystemNamespaces = []string{"kube-system", "kube-public", "kube-node-lease"}
+
+// protection returns why the healer must never act on the Pod, or "" if it may. Protected Pods are
+// still watched but neither healed nor cleaned up. Namespace patterns are globs or "re:" regexes,
+// Pod patterns globs; both are validated when loaded.
+func (h *Healer) protection(pod *v1.Pod) string {
+	namespaces := h.ProtectedNamespaces
+	if h.ProtectSystemNamespaces {
+		namespaces = append(append([]string{}, SystemNamespaces...), namespaces...)
+	}
+	for _, pattern := range namespaces {
+		if util.MatchNamespace(pattern, pod.Namespace) {
+			return fmt.Sprintf("namespace %s is protected", pod.Namespace)
+		}
+	}
+	for _, pattern := range h.ProtectedPods {
+		name := pod.Name
+		if strings.Contains(pattern, "/") {
+			name = pod.Namespace + "/" + pod.Name
+		}
+		ok, err := filepath.Match(pattern, name)
+		if err != nil {
+			// Unreachable for validated patterns; err on the side of the Pod
+			h.Log.Warn("Invalid protected pod pattern; treating the pod as protected", "pattern", pattern, "err", err)
+			return fmt.Sprintf("pod %s/%s is protected by the invalid pattern %q", pod.Namespace, pod.Name, pattern)
+		}
+		if ok {
+			return fmt.Sprintf("pod %s/%s is protected (%s)", pod.Namespace, pod.Name, pattern)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,150 @@
+package healer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/daigoro86dev/k8s-healer/pkg/annotations"
+	"github.com/daigoro86dev/k8s-healer/pkg/notify"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// DefaultFlapWindow is the window FlapThreshold heals are counted over.
+const DefaultFlapWindow = 30 * time.Minute
+
+// quarantineLabelGrace is how long a fresh quarantine is trusted while the owner cache doesn't show
+// the label yet.
+const quarantineLabelGrace = time.Minute
+
+// quarantines remembers the workloads quarantined by this healer, keyed by namespace/Kind/Name.
+type quarantines struct {
+	mu sync.Mutex
+	at map[string]time.Time
+}
+
+func newQuarantines() *quarantines {
+	return &quarantines{at: make(map[string]time.Time)}
+}
+
+func (q *quarantines) get(ownerKey string) (time.Time, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	at, ok := q.at[ownerKey]
+	return at, ok
+}
+
+func (q *quarantines) set(ownerKey string, at time.Time) {
+	q.mu.Lock()
+	q.at[ownerKey] = at
+	q.mu.Unlock()
+}
+
+// snapshot returns when each workload was quarantined.
+func (q *quarantines) snapshot() map[string]time.Time {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	out := make(map[string]time.Time, len(q.at))
+	for key, at := range q.at {
+		out[key] = at
+	}
+	return out
+}
+
+func (q *quarantines) forget(ownerKey string) {
+	q.mu.Lock()
+	delete(q.at, ownerKey)
+	q.mu.Unlock()
+}
+
+// quarantined reports whether the workload is quarantined: it carries the quarantine label, or was
+// quarantined by this healer and can't be labeled. Once the label is removed from a workload the
+// healer quarantined, its heal count starts over.
+func (h *Healer) quarantined(owner *OwnerInfo) bool {
+	if owner == nil {
+		return false
+	}
+	labels, labelable := workloadLabels(owner)
+	if labelable && labels[annotations.Quarantined] == "true" {
+		return true
+	}
+	key := owner.Namespace + "/" + owner.String()
+	at, ok := h.quarantines.get(key)
+	if !ok {
+		return false
+	}
+	if !labelable || time.Since(at) < quarantineLabelGrace {
+		return true
+	}
+	h.Log.Info("Workload no longer labeled quarantined; healing it again", "owner", owner.Namespace+"/"+owner.String(),
+		"label", annotations.Quarantined)
+	h.quarantines.forget(key)
+	h.ownerHeals.forget(key)
+	return false
+}
+
+// quarantineIfFlapping quarantines the Pod's workload if it was already healed FlapThreshold times
+// within FlapWindow: each of those heals was followed by another failure, so healing clearly
+// doesn't fix it. The workload is labeled and a notification sent. It returns whether the
+// workload is quarantined.
+func (h *Healer) quarantineIfFlapping(pod *v1.Pod, owner *OwnerInfo, f *failure) bool {
+	if h.FlapThreshold <= 0 || owner == nil {
+		return false
+	}
+	key := owner.Namespace + "/" + owner.String()
+	healed := h.ownerHeals.since(key, time.Now().Add(-h.FlapWindow))
+	if healed < h.FlapThreshold {
+		return false
+	}
+	h.quarantines.set(key, time.Now())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	labeled := "labeled " + annotations.Quarantined + "=true"
+	if err := h.labelWorkload(ctx, owner, annotations.Quarantined, "true"); err != nil {
+		labeled = fmt.Sprintf("not labeled (%v); quarantined until the healer restarts", err)
+	}
+	why := fmt.Sprintf("%s was healed %d times within %s without recovering", owner, healed, h.FlapWindow)
+	h.Log.Error("Workload quarantined; healing stopped", "owner", owner.Namespace+"/"+owner.String(), "why", why, "labeling", labeled)
+	h.notify(pod, notify.EventQuarantined, notify.SeverityCritical, f, "none",
+		fmt.Sprintf("Quarantined: %s. Healing stopped until the %s label is removed.", why, annotations.Quarantined))
+	return true
+}
+
+// workloadLabels returns the labels of the workload and whether the healer can label it.
+func workloadLabels(owner *OwnerInfo) (map[string]string, bool) {
+	switch {
+	case owner.Deployment != nil:
+		return owner.Deployment.Labels, true
+	case owner.StatefulSet != nil:
+		return owner.StatefulSet.Labels, true
+	case owner.ReplicaSet != nil && owner.Kind == "ReplicaSet":
+		return owner.ReplicaSet.Labels, true
+	}
+	return nil, false
+}
+
+// labelWorkload sets a label on the Deployment, StatefulSet or bare ReplicaSet.
+func (h *Healer) labelWorkload(ctx context.Context, owner *OwnerInfo, key, value string) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"labels": map[string]string{key: value}},
+	})
+	if err != nil {
+		return err
+	}
+	switch owner.Kind {
+	case "Deployment":
+		_, err = h.ClientSet.AppsV1().Deployments(owner.Namespace).Patch(ctx, owner.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	case "StatefulSet":
+		_, err = h.ClientSet.AppsV1().StatefulSets(owner.Namespace).Patch(ctx, owner.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	case "ReplicaSet":
+		_, err = h.ClientSet.AppsV1().ReplicaSets(owner.Namespace).Patch(ctx, owner.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	default:
+		err = fmt.Errorf("%s objects can't be labeled", owner.Kind)
+	}
+	return err
+}
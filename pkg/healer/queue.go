@@ -0,0 +1,252 @@
+package healer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+)
+
+// Defaults for the Pod processing queue.
+const (
+	DefaultQueueSize    = 1000
+	DefaultQueueWorkers = 4
+	DefaultHealRetries  = 5
+)
+
+// Backoff of the retries of a Pod whose heal failed transiently: 1s, 2s, 4s, ... up to a minute.
+const (
+	retryBaseDelay = time.Second
+	retryMaxDelay  = time.Minute
+)
+
+// podQueue decouples informer callbacks from processing. It is bounded, and holds at most one
+// entry per Pod: an update for a Pod that is still queued replaces the queued object, so bursts of
+// updates collapse into one check of the latest state. A Pod is never processed by two workers at
+// once; updates arriving meanwhile are processed afterwards. A Pod whose heal failed transiently is
+// requeued with exponential backoff.
+type podQueue struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	capacity int
+
+	order      []string           // queued keys, oldest first
+	queued     map[string]*v1.Pod // latest object per queued key
+	processing map[string]bool
+	deferred   map[string]*v1.Pod // updates that arrived while the key was processing
+	failures   map[string]int     // transient failures in a row per key
+	retrying   map[string]bool    // keys that asked for a retry while processing
+	shutdown   bool
+
+	merged  uint64 // updates folded into an already queued entry
+	dropped uint64 // updates dropped because the queue was full
+	retried uint64 // heals requeued after a transient failure
+}
+
+func newPodQueue(capacity int) *podQueue {
+	q := &podQueue{
+		capacity:   capacity,
+		queued:     make(map[string]*v1.Pod),
+		processing: make(map[string]bool),
+		deferred:   make(map[string]*v1.Pod),
+		failures:   make(map[string]int),
+		retrying:   make(map[string]bool),
+	}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// setCapacity bounds the queue, once QueueSize is known. Entries already queued are kept.
+func (q *podQueue) setCapacity(capacity int) {
+	q.mu.Lock()
+	q.capacity = capacity
+	q.mu.Unlock()
+}
+
+// add enqueues the Pod without blocking. It returns false if the update was dropped.
+func (q *podQueue) add(pod *v1.Pod) bool {
+	key := fmt.Sprintf("%s/%s", pod.Namespace, pod.Name)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.shutdown {
+		return false
+	}
+	if _, ok := q.queued[key]; ok {
+		q.queued[key] = pod
+		q.merged++
+		return true
+	}
+	if q.processing[key] {
+		if _, ok := q.deferred[key]; ok {
+			q.merged++
+		}
+		q.deferred[key] = pod
+		return true
+	}
+	if len(q.order) >= q.capacity {
+		// The informer resync redelivers the Pod, so a dropped update is only delayed.
+		q.dropped++
+		return false
+	}
+	q.order = append(q.order, key)
+	q.queued[key] = pod
+	q.cond.Signal()
+	return true
+}
+
+// get blocks until a Pod is available and marks it as processing. It returns false on shutdown.
+func (q *podQueue) get() (string, *v1.Pod, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for len(q.order) == 0 && !q.shutdown {
+		q.cond.Wait()
+	}
+	if q.shutdown {
+		return "", nil, false
+	}
+	key := q.order[0]
+	q.order = q.order[1:]
+	pod := q.queued[key]
+	delete(q.queued, key)
+	q.processing[key] = true
+	return key, pod, true
+}
+
+// done marks the key as processed and requeues an update that arrived meanwhile. Processing that
+// didn't ask for a retry ends the key's failure streak.
+func (q *podQueue) done(key string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.processing, key)
+	if !q.retrying[key] {
+		delete(q.failures, key)
+	}
+	delete(q.retrying, key)
+	if pod, ok := q.deferred[key]; ok {
+		delete(q.deferred, key)
+		q.order = append(q.order, key)
+		q.queued[key] = pod
+		q.cond.Signal()
+	}
+}
+
+// retry requeues the Pod being processed after a backoff that doubles with each failure in a row,
+// and returns the delay. It returns false once the Pod failed more than retries times in a row, and
+// starts the streak over.
+func (q *podQueue) retry(pod *v1.Pod, retries int) (time.Duration, bool) {
+	key := fmt.Sprintf("%s/%s", pod.Namespace, pod.Name)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.failures[key] >= retries {
+		delete(q.failures, key)
+		return 0, false
+	}
+	delay := retryBaseDelay
+	for i := 0; i < q.failures[key] && delay < retryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > retryMaxDelay {
+		delay = retryMaxDelay
+	}
+	q.failures[key]++
+	q.retrying[key] = true
+	q.retried++
+	time.AfterFunc(delay, func() { q.requeue(key, pod) })
+	return delay, true
+}
+
+// requeue adds the Pod back unless a newer update of it is queued or being processed.
+func (q *podQueue) requeue(key string, pod *v1.Pod) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.shutdown || q.queued[key] != nil || q.deferred[key] != nil {
+		return
+	}
+	if q.processing[key] {
+		q.deferred[key] = pod
+		return
+	}
+	q.order = append(q.order, key)
+	q.queued[key] = pod
+	q.cond.Signal()
+}
+
+// close wakes up all workers and makes them return.
+func (q *podQueue) close() {
+	q.mu.Lock()
+	q.shutdown = true
+	q.mu.Unlock()
+	q.cond.Broadcast()
+}
+
+// QueueStats describes the Pod processing queue.
+type QueueStats struct {
+	Depth    int    `json:"depth"`
+	Capacity int    `json:"capacity"`
+	Merged   uint64 `json:"merged"`
+	Dropped  uint64 `json:"dropped"`
+	Retried  uint64 `json:"retried"`
+}
+
+func (q *podQueue) stats() QueueStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return QueueStats{Depth: len(q.order) + len(q.deferred), Capacity: q.capacity, Merged: q.merged, Dropped: q.dropped, Retried: q.retried}
+}
+
+// transientError reports whether the API call may succeed if simply retried: the API server was
+// overloaded, unavailable or slow, or the connection failed. An eviction refused by a
+// PodDisruptionBudget counts too, since the budget frees up as replacements become ready.
+func transientError(err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case apierrors.IsTooManyRequests(err), apierrors.IsServerTimeout(err), apierrors.IsTimeout(err),
+		apierrors.IsServiceUnavailable(err), apierrors.IsInternalError(err):
+		return true
+	case errors.Is(err, context.DeadlineExceeded):
+		return true
+	}
+	return utilnet.IsConnectionReset(err) || utilnet.IsConnectionRefused(err) || utilnet.IsProbableEOF(err)
+}
+
+// enqueuePod hands a Pod update from an informer to the processing workers.
+func (h *Healer) enqueuePod(pod *v1.Pod) {
+	// Watching all namespaces includes the excluded ones, and a cluster-wide watch unwatched ones
+	if !h.watchesNamespace(pod.Namespace) {
+		return
+	}
+	if !h.queue.add(pod) {
+		if stats := h.queue.stats(); stats.Dropped == 1 || stats.Dropped%100 == 0 {
+			h.Log.Warn("Processing queue full; dropped updates are retried on resync", "capacity", stats.Capacity,
+				"dropped", stats.Dropped)
+		}
+	}
+}
+
+// startQueueWorkers processes queued Pods with QueueWorkers workers until the healer stops.
+func (h *Healer) startQueueWorkers(process func(*v1.Pod)) {
+	for i := 0; i < h.QueueWorkers; i++ {
+		go func() {
+			for {
+				key, pod, ok := h.queue.get()
+				if !ok {
+					return
+				}
+				process(pod)
+				h.queue.done(key)
+			}
+		}()
+	}
+	go func() {
+		<-h.StopCh
+		h.queue.close()
+	}()
+}
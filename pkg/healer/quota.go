@@ -0,0 +1,84 @@
+package healer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// quotaShortfall returns why the namespace's ResourceQuotas have no room for the Pod's replacement,
+// or "" if they do or can't be read. The controller creates the replacement while the deleted Pod
+// still counts against the quota, so the replacement needs headroom of its own; without it,
+// deleting a crash-looping Pod only leaves a missing one. Quotas limited by scopes are not
+// evaluated.
+func (h *Healer) quotaShortfall(ctx context.Context, pod *v1.Pod) string {
+	if !h.CheckResourceQuota {
+		return ""
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	quotas, err := h.ClientSet.CoreV1().ResourceQuotas(pod.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		h.Log.Warn("Can't check ResourceQuotas; healing without checking them", "pod", pod.Namespace+"/"+pod.Name, "err", err)
+		return ""
+	}
+	needs := podQuotaUsage(pod)
+	for _, quota := range quotas.Items {
+		if len(quota.Spec.Scopes) > 0 || quota.Spec.ScopeSelector != nil {
+			continue
+		}
+		var short []string
+		for name, need := range needs {
+			hard, ok := quota.Status.Hard[name]
+			if !ok {
+				continue
+			}
+			left := hard.DeepCopy()
+			if used, ok := quota.Status.Used[name]; ok {
+				left.Sub(used)
+			}
+			if left.Cmp(need) < 0 {
+				short = append(short, fmt.Sprintf("%s needs %s, %s left", name, need.String(), left.String()))
+			}
+		}
+		if len(short) > 0 {
+			sort.Strings(short)
+			return fmt.Sprintf("ResourceQuota %s has no room for its replacement (%s)", quota.Name, strings.Join(short, "; "))
+		}
+	}
+	return ""
+}
+
+// podQuotaUsage returns what a Pod like this one counts against a ResourceQuota. Init containers
+// are left out; they rarely request more than the app containers.
+func podQuotaUsage(pod *v1.Pod) v1.ResourceList {
+	usage := v1.ResourceList{v1.ResourcePods: resource.MustParse("1")}
+	add := func(name v1.ResourceName, q resource.Quantity) {
+		total := usage[name]
+		total.Add(q)
+		usage[name] = total
+	}
+	for _, c := range pod.Spec.Containers {
+		for _, r := range []v1.ResourceName{v1.ResourceCPU, v1.ResourceMemory} {
+			// Requests default to the limits when only limits are set
+			q, ok := c.Resources.Requests[r]
+			if !ok {
+				q, ok = c.Resources.Limits[r]
+			}
+			if ok {
+				add(r, q)
+				add(v1.ResourceName("requests."+string(r)), q)
+			}
+			if q, ok := c.Resources.Limits[r]; ok {
+				add(v1.ResourceName("limits."+string(r)), q)
+			}
+		}
+	}
+	return usage
+}
@@ -0,0 +1,23 @@
+package healer
+
+import (
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// newHealLimiter returns the cluster-wide token bucket for MaxHealsPerMinute, or nil for no limit.
+// The bucket holds a minute's worth of heals, so a short burst heals right away while a widespread
+// outage is spread out instead of deleting hundreds of Pods at once.
+func newHealLimiter(perMinute int) *rate.Limiter {
+	if perMinute <= 0 {
+		return nil
+	}
+	return rate.NewLimiter(rate.Every(time.Minute/time.Duration(perMinute)), perMinute)
+}
+
+// healAllowed takes a token for an automatic heal. Heals that find the bucket empty are deferred:
+// the Pod is checked again on its next update or reconciliation pass.
+func (h *Healer) healAllowed() bool {
+	return h.healLimiter == nil || h.healLimiter.Allow()
+}
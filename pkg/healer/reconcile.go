@@ -0,0 +1,69 @@
+package healer
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/labels"
+	corelisters "k8s.io/client-go/listers/core/v1"
+)
+
+// Defaults for ResyncPeriod and ReconcileInterval.
+const (
+	DefaultResyncPeriod      = 30 * time.Second
+	DefaultReconcileInterval = time.Minute
+)
+
+// setPodLister registers the synced Pod lister of a watch, unless the watch was stopped meanwhile.
+func (h *Healer) setPodLister(namespace string, stopCh <-chan struct{}, lister corelisters.PodLister) {
+	h.watches.mu.Lock()
+	defer h.watches.mu.Unlock()
+	select {
+	case <-stopCh:
+		return
+	default:
+	}
+	if h.watches.listers == nil {
+		h.watches.listers = make(map[string]corelisters.PodLister)
+	}
+	h.watches.listers[namespace] = lister
+}
+
+// podListers returns the Pod listers of the running watches.
+func (h *Healer) podListers() []corelisters.PodLister {
+	h.watches.mu.Lock()
+	defer h.watches.mu.Unlock()
+	out := make([]corelisters.PodLister, 0, len(h.watches.listers))
+	for _, lister := range h.watches.listers {
+		out = append(out, lister)
+	}
+	return out
+}
+
+// startReconciler re-checks every cached Pod each ReconcileInterval, independently of the informer
+// resync, so a Pod whose update was dropped or that turned unhealthy without changing is still
+// healed on time. The pass reads the informer caches only; it costs no API calls.
+func (h *Healer) startReconciler() {
+	if h.ReconcileInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(h.ReconcileInterval)
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				for _, lister := range h.podListers() {
+					pods, err := lister.List(labels.Everything())
+					if err != nil {
+						continue
+					}
+					for _, pod := range pods {
+						h.enqueuePod(pod)
+					}
+				}
+			case <-h.StopCh:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+}
@@ -0,0 +1,188 @@
+package healer
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/daigoro86dev/k8s-healer/pkg/history"
+	"github.com/daigoro86dev/k8s-healer/pkg/notify"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// DefaultRecoveryTimeout is how long a replacement has to become Ready after a heal.
+const DefaultRecoveryTimeout = 5 * time.Minute
+
+// RecoveryBuckets are the upper bounds, in seconds, of the recovery time histogram.
+var RecoveryBuckets = []float64{5, 10, 30, 60, 120, 300, 600}
+
+// RecoveryHistogram counts the verified heals since the healer started by the time their
+// replacement took to become Ready.
+type RecoveryHistogram struct {
+	Buckets     []RecoveryBucket `json:"buckets"` // Cumulative, like a Prometheus histogram
+	Count       int              `json:"count"`
+	SumSeconds  float64          `json:"sumSeconds"`
+	Unrecovered int              `json:"unrecovered"` // Heals whose replacement didn't become Ready in time
+}
+
+// RecoveryBucket counts the recoveries that took at most LE seconds.
+type RecoveryBucket struct {
+	LE    float64 `json:"le"`
+	Count int     `json:"count"`
+}
+
+// recoveryStats accumulates the RecoveryHistogram.
+type recoveryStats struct {
+	mu          sync.Mutex
+	counts      []int // Per bucket, not cumulative; the last one counts the rest
+	count       int
+	sum         float64
+	unrecovered int
+}
+
+func (s *recoveryStats) observe(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.counts == nil {
+		s.counts = make([]int, len(RecoveryBuckets)+1)
+	}
+	i := sort.SearchFloat64s(RecoveryBuckets, d.Seconds())
+	s.counts[i]++
+	s.count++
+	s.sum += d.Seconds()
+}
+
+func (s *recoveryStats) fail() {
+	s.mu.Lock()
+	s.unrecovered++
+	s.mu.Unlock()
+}
+
+func (s *recoveryStats) histogram() RecoveryHistogram {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	hist := RecoveryHistogram{Count: s.count, SumSeconds: s.sum, Unrecovered: s.unrecovered}
+	cumulative := 0
+	for i, le := range RecoveryBuckets {
+		if s.counts != nil {
+			cumulative += s.counts[i]
+		}
+		hist.Buckets = append(hist.Buckets, RecoveryBucket{LE: le, Count: cumulative})
+	}
+	return hist
+}
+
+// pendingRecovery is a deleted or evicted Pod whose replacement hasn't become Ready yet.
+type pendingRecovery struct {
+	at      time.Time
+	pod     *v1.Pod // The healed Pod
+	owner   string  // namespace/Kind/Name of its owner
+	failure *failure
+	action  string
+}
+
+// recoveryTracker follows heals until a Pod of the healed Pod's controller, created after the heal,
+// becomes Ready. Heals are keyed by the controller's UID.
+type recoveryTracker struct {
+	pending *pendingTracker[types.UID, pendingRecovery]
+}
+
+func newRecoveryTracker() *recoveryTracker {
+	return &recoveryTracker{pending: newPendingTracker[types.UID, pendingRecovery]()}
+}
+
+func (t *recoveryTracker) expect(controller types.UID, r pendingRecovery) {
+	t.pending.add(controller, r.at, r)
+}
+
+// recovered takes the heals of the Pod's controller that the Pod replaces, if it is Ready.
+func (t *recoveryTracker) recovered(pod *v1.Pod) []pendingRecovery {
+	ref := metav1.GetControllerOf(pod)
+	if ref == nil || !t.pending.has(ref.UID) || !podReady(pod) {
+		return nil
+	}
+	return values(t.pending.takeAll(ref.UID, pod, func(p pendingEntry[pendingRecovery]) bool {
+		return p.value.pod.UID != pod.UID
+	}))
+}
+
+// forget drops the heals of the owner, whose outcome is already known.
+func (t *recoveryTracker) forget(owner string) {
+	t.pending.remove(func(p pendingEntry[pendingRecovery]) bool { return p.value.owner == owner })
+}
+
+// expired takes the heals older than timeout.
+func (t *recoveryTracker) expired(now time.Time, timeout time.Duration) []pendingRecovery {
+	return values(t.pending.remove(func(p pendingEntry[pendingRecovery]) bool { return now.Sub(p.at) >= timeout }))
+}
+
+// expectRecovery starts verifying that a successfully deleted or evicted Pod gets a Ready
+// replacement within RecoveryTimeout.
+func (h *Healer) expectRecovery(pod *v1.Pod, owner *OwnerInfo, f *failure, rec history.Record) {
+	if h.RecoveryTimeout <= 0 || rec.Result != "success" || (rec.Action != ActionDelete && rec.Action != StrategyEvict) {
+		return
+	}
+	ref := metav1.GetControllerOf(pod)
+	if ref == nil {
+		return
+	}
+	r := pendingRecovery{at: rec.Time, pod: pod, failure: f, action: rec.Action}
+	if owner != nil {
+		r.owner = owner.Namespace + "/" + owner.String()
+	}
+	h.recoveries.expect(ref.UID, r)
+}
+
+// observeRecovery completes the verification of the heals a Ready Pod replaces.
+func (h *Healer) observeRecovery(pod *v1.Pod) {
+	for _, r := range h.recoveries.recovered(pod) {
+		after := time.Since(r.at)
+		h.setRecovered(r.at, r.pod.Namespace, r.pod.Name, after)
+		h.recoveryStats.observe(after)
+		h.Log.Info("Heal recovered: replacement became Ready", "pod", pod.Namespace+"/"+pod.Name, "healed", r.pod.Name,
+			"after", after.Round(time.Second))
+	}
+}
+
+// startRecoveryVerifier fails the heals whose replacement didn't become Ready within
+// RecoveryTimeout: the heal is recorded as unrecovered, escalated through the notifiers, and the
+// workload's cooldown backs off as if it had been healed once more.
+func (h *Healer) startRecoveryVerifier() {
+	if h.RecoveryTimeout <= 0 {
+		return
+	}
+	ticker := time.NewTicker(15 * time.Second)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C:
+				for _, r := range h.recoveries.expired(now, h.RecoveryTimeout) {
+					h.failRecovery(r)
+				}
+			case <-h.StopCh:
+				return
+			}
+		}
+	}()
+}
+
+func (h *Healer) failRecovery(r pendingRecovery) {
+	podKey := r.pod.Namespace + "/" + r.pod.Name
+	h.setOutcome(r.at, r.pod.Namespace, r.pod.Name, history.OutcomeUnrecovered)
+	h.recoveryStats.fail()
+	if r.owner != "" {
+		h.effectiveness.forget(r.owner, r.at)
+	}
+	// The failed heal continues the workload's streak, so its cooldown backs off further
+	h.markHealed(r.pod, time.Now())
+	mark, _ := h.lastHealed(r.pod)
+	why := fmt.Sprintf("no replacement of pod %s became Ready within %s of the heal", podKey, h.RecoveryTimeout)
+	h.Log.Error("Heal failed to recover the workload", "pod", podKey, "why", why, "nextHeal", mark.Until.Format(time.RFC3339),
+		"streak", mark.Streak)
+	h.notify(r.pod, notify.EventHealUnrecovered, notify.SeverityCritical, r.failure, r.action,
+		fmt.Sprintf("Heal failed to recover the workload: %s.", why))
+}
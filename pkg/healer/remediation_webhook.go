@@ -0,0 +1,72 @@
+package healer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// callRemediationWebhook POSTs the heal decision to the remediation webhook. It returns true if the
+// endpoint approved the deletion (2xx), false if it declined, e.g. because it remediates the
+// workload itself, and an error if it could not be reached.
+func (h *Healer) callRemediationWebhook(ctx context.Context, pod *v1.Pod, owner *OwnerInfo, f *failure) (bool, string, error) {
+	if h.RemediationWebhookURL == "" {
+		return false, "", fmt.Errorf("no remediation webhook configured")
+	}
+
+	req := DecisionRequest{
+		Healer:    h.Identity(),
+		Cluster:   h.ClusterName,
+		Namespace: pod.Namespace,
+		Pod:       pod.Name,
+		Owner:     owner.String(),
+		Check:     f.Check,
+		Reason:    f.Reason,
+		Action:    ActionWebhook,
+	}
+	if f.Termination != nil {
+		req.ExitCode = &f.Termination.ExitCode
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return false, "", err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, h.RemediationWebhookTimeout)
+	defer cancel()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, h.RemediationWebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return false, "", err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	httpResp, err := webhookClient.Do(httpReq)
+	if err != nil {
+		return false, "", err
+	}
+	defer httpResp.Body.Close()
+
+	msg, _ := io.ReadAll(io.LimitReader(httpResp.Body, 4<<10))
+	return httpResp.StatusCode >= 200 && httpResp.StatusCode <= 299, fmt.Sprintf("%s %s", httpResp.Status, bytes.TrimSpace(msg)), nil
+}
+
+// remediateViaWebhook hands the heal to the remediation webhook and deletes the Pod only if it approves.
+func (h *Healer) remediateViaWebhook(ctx context.Context, pod *v1.Pod, owner *OwnerInfo, f *failure) (string, error) {
+	approved, response, err := h.callRemediationWebhook(ctx, pod, owner, f)
+	if err != nil {
+		h.Log.Error("Remediation webhook failed", "pod", pod.Namespace+"/"+pod.Name, "err", err)
+		return ActionWebhook, fmt.Errorf("remediation webhook failed: %w", err)
+	}
+	if !approved {
+		h.Log.Info("Remediation webhook declined deleting the pod; leaving it to the endpoint", "pod", pod.Namespace+"/"+pod.Name, "response", response)
+		return ActionWebhook, nil
+	}
+	h.Log.Info("Remediation webhook approved deleting the pod", "pod", pod.Namespace+"/"+pod.Name, "response", response)
+	return ActionDelete, h.triggerPodDeletion(ctx, pod, f)
+}
@@ -0,0 +1,130 @@
+package healer
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/daigoro86dev/k8s-healer/pkg/annotations"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// replacementWindow is how long after a heal a new Pod of the same controller counts as the
+// healed Pod's replacement.
+const replacementWindow = 10 * time.Minute
+
+// pendingReplacement is a deleted Pod whose replacement has not been seen yet.
+type pendingReplacement struct {
+	at        time.Time
+	namespace string
+	pod       string
+	reason    string
+}
+
+// replacementTracker pairs deleted Pods with the Pods their controller creates to replace them,
+// keyed by the controller's UID.
+type replacementTracker struct {
+	pending *pendingTracker[types.UID, pendingReplacement]
+}
+
+func newReplacementTracker() *replacementTracker {
+	return &replacementTracker{pending: newPendingTracker[types.UID, pendingReplacement]()}
+}
+
+// expect records that the controller is about to replace the Pod.
+func (t *replacementTracker) expect(controller types.UID, r pendingReplacement) {
+	t.pending.add(controller, r.at, r)
+}
+
+// cancel forgets an expectation whose Pod wasn't removed after all.
+func (t *replacementTracker) cancel(controller types.UID, r pendingReplacement) {
+	t.pending.removeValue(controller, r)
+}
+
+// expects reports whether a replacement of the Pod's controller is expected.
+func (t *replacementTracker) expects(pod *v1.Pod) bool {
+	ref := metav1.GetControllerOf(pod)
+	return ref != nil && t.pending.has(ref.UID)
+}
+
+// forgetNamespace drops the expectations of Pods in the namespace.
+func (t *replacementTracker) forgetNamespace(namespace string) {
+	t.pending.remove(func(p pendingEntry[pendingReplacement]) bool { return p.value.namespace == namespace })
+}
+
+// prune drops expectations whose replacement never showed up.
+func (t *replacementTracker) prune(now time.Time) {
+	t.pending.remove(func(p pendingEntry[pendingReplacement]) bool { return now.Sub(p.at) > replacementWindow })
+}
+
+// claim returns the oldest heal of the Pod's controller the Pod may replace, i.e. one that happened
+// before the Pod was created, at most replacementWindow before, and forgets it.
+func (t *replacementTracker) claim(pod *v1.Pod) (pendingReplacement, bool) {
+	ref := metav1.GetControllerOf(pod)
+	if ref == nil {
+		return pendingReplacement{}, false
+	}
+	p, ok := t.pending.takeOldest(ref.UID, pod, func(p pendingEntry[pendingReplacement]) bool {
+		return time.Since(p.at) <= replacementWindow && pod.CreationTimestamp.Time.Before(p.at.Add(replacementWindow))
+	})
+	return p.value, ok
+}
+
+// expectReplacement remembers a Pod about to be deleted or evicted, so its replacement can be
+// annotated. It is called before the Pod is removed, since the controller may create the
+// replacement before the call returns; the returned func cancels the expectation if the removal
+// fails.
+func (h *Healer) expectReplacement(pod *v1.Pod, f *failure) (cancel func()) {
+	ref := metav1.GetControllerOf(pod)
+	if h.ReplacementAnnotationPrefix == "" || ref == nil {
+		return func() {}
+	}
+	r := pendingReplacement{at: time.Now(), namespace: pod.Namespace, pod: pod.Name}
+	if f != nil {
+		r.reason = f.Reason
+	}
+	h.replacements.expect(ref.UID, r)
+	return func() { h.replacements.cancel(ref.UID, r) }
+}
+
+// queueReplacement queues a new Pod that may replace a healed one, so the queue workers annotate it
+// instead of the informer's event handler.
+func (h *Healer) queueReplacement(pod *v1.Pod) {
+	if h.ReplacementAnnotationPrefix != "" && h.replacements.expects(pod) {
+		h.enqueuePod(pod)
+	}
+}
+
+// annotateReplacement marks a new Pod that replaces a healed one with the healed Pod's name and the
+// heal reason, so whoever inspects it sees why it exists. It runs on the queue workers.
+func (h *Healer) annotateReplacement(pod *v1.Pod) {
+	if h.ReplacementAnnotationPrefix == "" || pod.DeletionTimestamp != nil {
+		return
+	}
+	r, ok := h.replacements.claim(pod)
+	if !ok {
+		return
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{
+				annotations.WithPrefix(annotations.HealedFrom, h.ReplacementAnnotationPrefix): r.pod,
+				annotations.WithPrefix(annotations.HealReason, h.ReplacementAnnotationPrefix): r.reason,
+			},
+		},
+	})
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err = h.ClientSet.CoreV1().Pods(pod.Namespace).Patch(ctx, pod.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		h.Log.Warn("Failed to annotate replacement pod", "pod", pod.Namespace+"/"+pod.Name, "err", err)
+		return
+	}
+	h.Log.Info("Annotated replacement of healed pod", "pod", pod.Namespace+"/"+pod.Name, "healed", r.pod)
+}
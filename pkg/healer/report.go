@@ -0,0 +1,152 @@
+package healer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/daigoro86dev/k8s-healer/pkg/notify"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	corelisters "k8s.io/client-go/listers/core/v1"
+)
+
+// EventStartupReport carries the one-time reconciliation report sent after startup.
+const EventStartupReport notify.EventType = "startup-report"
+
+// reportEntry is one unhealthy Pod found by the startup reconciliation.
+type reportEntry struct {
+	pod    string
+	owner  string
+	reason string
+	intent string
+}
+
+// reconcileOnStartup waits until the initial caches synced and reports every unhealthy Pod in the
+// watched scope together with what the healer intends to do about it. The report only observes;
+// the heals themselves happen as the Pods are next updated or resynced.
+func (h *Healer) reconcileOnStartup(wg *sync.WaitGroup, mu *sync.Mutex, listers *[]corelisters.PodLister) {
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	var entries []reportEntry
+	for _, lister := range *listers {
+		pods, err := lister.List(labels.Everything())
+		if err != nil {
+			continue
+		}
+		for _, pod := range pods {
+			if !h.watchesNamespace(pod.Namespace) || !h.eligibleOwner(pod) || pod.DeletionTimestamp != nil || pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+				continue
+			}
+			f := h.detectFailure(pod)
+			if f == nil {
+				continue
+			}
+			owner := h.owners.Resolve(pod)
+			entries = append(entries, reportEntry{
+				pod:    fmt.Sprintf("%s/%s", pod.Namespace, pod.Name),
+				owner:  owner.String(),
+				reason: f.Reason,
+				intent: h.intendedAction(pod, owner, f),
+			})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].pod < entries[j].pod })
+
+	h.Log.Info("Startup reconciliation complete", "unhealthy", len(entries))
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "POD\tOWNER\tREASON\tINTENDED ACTION")
+	for _, e := range entries {
+		h.Log.Info("Unhealthy pod found on startup", "pod", e.pod, "owner", e.owner, "reason", e.reason, "intent", e.intent)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", e.pod, e.owner, e.reason, e.intent)
+	}
+	_ = w.Flush()
+
+	if h.StartupReportNotify && h.Notifier != nil {
+		go h.Notifier.Dispatch(notify.Event{
+			Type:     EventStartupReport,
+			Severity: notify.SeverityInfo,
+			Cluster:  h.ClusterName,
+			Reason:   fmt.Sprintf("%d unhealthy pod(s) found on startup", len(entries)),
+			Message:  b.String(),
+			Time:     time.Now(),
+		})
+	}
+}
+
+// intendedAction describes what checkAndHealPod would currently do with the failing Pod, without
+// acting, notifying or consulting the decision webhook.
+func (h *Healer) intendedAction(pod *v1.Pod, owner *OwnerInfo, f *failure) string {
+	if why := h.protection(pod); why != "" {
+		return fmt.Sprintf("none (%s)", why)
+	}
+	if h.inFlight.active(pod.UID) {
+		return "wait for replacement"
+	}
+	if _, ok := h.coolingDown(pod); ok {
+		return "wait for cooldown"
+	}
+	if minAge := h.minPodAgeFor(pod); time.Since(pod.CreationTimestamp.Time) < minAge {
+		return fmt.Sprintf("wait until pod is %s old", minAge)
+	}
+	if why := h.optedOut(pod, owner); why != "" {
+		return fmt.Sprintf("none (%s)", why)
+	}
+	if h.quarantined(owner) {
+		return "none (quarantined)"
+	}
+	if h.chronicFailure(owner) != "" {
+		return "notify only (chronic failure)"
+	}
+	if by, _, paused := h.paused.get(); paused {
+		return fmt.Sprintf("notify only (paused by %s)", by)
+	}
+	if h.breakerOpen() != "" {
+		return "notify only (circuit breaker open)"
+	}
+	if b := h.activeBlackout(pod.Namespace); b != nil {
+		return fmt.Sprintf("notify only (blackout %s)", b.ID)
+	}
+	if w := h.activeFreeze(pod.Namespace); w != nil {
+		return fmt.Sprintf("notify only (%s)", describeFreeze(w))
+	}
+	if w, until := h.activeNoHealWindow(pod.Namespace); w != nil {
+		return fmt.Sprintf("notify only (%s)", describeNoHealWindow(w, until))
+	}
+	if owner != nil && owner.Deployment != nil && owner.Deployment.Spec.Paused && h.PausedDeploymentBehavior != PausedHeal {
+		return fmt.Sprintf("%s (deployment paused)", h.PausedDeploymentBehavior)
+	}
+	if why := h.controllerPreflight(context.Background(), owner); why != "" {
+		return fmt.Sprintf("none (%s)", why)
+	}
+
+	action := h.actionFor(pod, f)
+	switch action {
+	case ActionSkip:
+		return "skip (policy)"
+	case ActionNotify:
+		return "notify only (policy)"
+	}
+	var conditions []string
+	if h.MinUnhealthyDuration > 0 {
+		conditions = append(conditions, fmt.Sprintf("after %s unhealthy", h.MinUnhealthyDuration))
+	}
+	if h.DecisionWebhookURL != "" {
+		conditions = append(conditions, "if the decision webhook allows")
+	}
+	if h.requiresApproval(pod.Namespace) && approvedBy(pod) == "" {
+		conditions = append(conditions, "once approved")
+	}
+	if len(conditions) > 0 {
+		return fmt.Sprintf("%s %s", action, strings.Join(conditions, ", "))
+	}
+	return action
+}
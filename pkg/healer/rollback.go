@@ -0,0 +1,120 @@
+package healer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// revisionAnnotation is the revision the Deployment controller stamps on its ReplicaSets.
+const revisionAnnotation = "deployment.kubernetes.io/revision"
+
+// rollbackTarget checks whether the Pod's Deployment should be rolled back: the Pod belongs to the
+// newest ReplicaSet and every Pod of that ReplicaSet is crash-looping. It returns the ReplicaSet
+// of the previous revision to roll back to.
+func (h *Healer) rollbackTarget(ctx context.Context, owner *OwnerInfo) (*appsv1.ReplicaSet, error) {
+	if owner == nil || owner.Deployment == nil || owner.ReplicaSet == nil {
+		return nil, fmt.Errorf("%s is not a Deployment", owner)
+	}
+	d := owner.Deployment
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	selector := metav1.FormatLabelSelector(d.Spec.Selector)
+	rsList, err := h.ClientSet.AppsV1().ReplicaSets(d.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ReplicaSets of %s: %w", owner, err)
+	}
+	var revisions []*appsv1.ReplicaSet
+	for i := range rsList.Items {
+		rs := &rsList.Items[i]
+		if ref := metav1.GetControllerOf(rs); ref != nil && ref.UID == d.UID {
+			revisions = append(revisions, rs)
+		}
+	}
+	sort.Slice(revisions, func(i, j int) bool { return revision(revisions[i]) > revision(revisions[j]) })
+
+	if len(revisions) < 2 {
+		return nil, fmt.Errorf("%s has no previous revision", owner)
+	}
+	newest := revisions[0]
+	if newest.UID != owner.ReplicaSet.UID {
+		return nil, fmt.Errorf("pod belongs to %s, not to the newest revision %s", owner.ReplicaSet.Name, newest.Name)
+	}
+
+	pods, err := h.ClientSet.CoreV1().Pods(d.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: metav1.FormatLabelSelector(newest.Spec.Selector),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods of %s: %w", newest.Name, err)
+	}
+	owned := 0
+	for i := range pods.Items {
+		p := &pods.Items[i]
+		if ref := metav1.GetControllerOf(p); ref == nil || ref.UID != newest.UID {
+			continue
+		}
+		owned++
+		if !crashLooping(p) {
+			return nil, fmt.Errorf("pod %s of revision %d is not crash-looping", p.Name, revision(newest))
+		}
+	}
+	if owned == 0 {
+		return nil, fmt.Errorf("revision %d has no pods", revision(newest))
+	}
+	return revisions[1], nil
+}
+
+// rollbackDeployment rolls the Pod's Deployment back to the previous revision the way
+// `kubectl rollout undo` does, by copying that ReplicaSet's pod template into the Deployment.
+func (h *Healer) rollbackDeployment(ctx context.Context, pod *v1.Pod, owner *OwnerInfo, previous *appsv1.ReplicaSet) error {
+	template := previous.Spec.Template.DeepCopy()
+	delete(template.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+
+	patch, err := json.Marshal([]map[string]interface{}{
+		{"op": "replace", "path": "/spec/template", "value": template},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to build rollback patch: %w", err)
+	}
+
+	_, err = h.ClientSet.AppsV1().Deployments(owner.Namespace).Patch(ctx, owner.Name, types.JSONPatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		h.Log.Error("Failed to roll back", "owner", owner.String(), "pod", pod.Namespace+"/"+pod.Name, "err", err)
+		return err
+	}
+
+	h.Log.Info("Rolled back", "owner", owner.String(), "from", revision(owner.ReplicaSet), "to", revision(previous))
+	return nil
+}
+
+// revision returns the Deployment revision of the ReplicaSet, or 0 if unknown.
+func revision(rs *appsv1.ReplicaSet) int64 {
+	v, err := strconv.ParseInt(rs.Annotations[revisionAnnotation], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+// crashLooping reports whether one of the Pod's containers is in CrashLoopBackOff or has
+// restarted and is not ready, i.e. it is cycling between crashes.
+func crashLooping(pod *v1.Pod) bool {
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.State.Waiting != nil && cs.State.Waiting.Reason == "CrashLoopBackOff" {
+			return true
+		}
+		if cs.RestartCount > 0 && !cs.Ready {
+			return true
+		}
+	}
+	return false
+}
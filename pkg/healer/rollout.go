@@ -0,0 +1,64 @@
+package healer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// RestartedAtAnnotation is the pod template annotation `kubectl rollout restart` sets to roll a workload.
+const RestartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+
+// rolloutRestart restarts the Pod's owning Deployment or StatefulSet the way `kubectl rollout restart`
+// does, by stamping its pod template. The controller then replaces the Pods within the workload's
+// surge/unavailable budgets instead of us deleting them directly.
+func (h *Healer) rolloutRestart(ctx context.Context, pod *v1.Pod, owner *OwnerInfo) error {
+	var template *v1.PodTemplateSpec
+	switch {
+	case owner == nil:
+		return fmt.Errorf("pod has no owner to restart")
+	case owner.Deployment != nil:
+		template = &owner.Deployment.Spec.Template
+	case owner.StatefulSet != nil:
+		template = &owner.StatefulSet.Spec.Template
+	default:
+		return fmt.Errorf("rollout restart is not supported for %s", owner)
+	}
+
+	// Several Pods of the same workload fail together; one restart within the cooldown covers them all.
+	if last, err := time.Parse(time.RFC3339, template.Annotations[RestartedAtAnnotation]); err == nil && time.Since(last) < h.HealCooldown {
+		h.Log.Info("Workload already restarted; not restarting again", "owner", owner.String(), "restartedAt", last.Format(time.RFC3339))
+		return nil
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]string{RestartedAtAnnotation: time.Now().Format(time.RFC3339)},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to build restart patch: %w", err)
+	}
+
+	if owner.Deployment != nil {
+		_, err = h.ClientSet.AppsV1().Deployments(owner.Namespace).Patch(ctx, owner.Name, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+	} else {
+		_, err = h.ClientSet.AppsV1().StatefulSets(owner.Namespace).Patch(ctx, owner.Name, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+	}
+	if err != nil {
+		h.Log.Error("Failed to restart workload", "owner", owner.String(), "pod", pod.Namespace+"/"+pod.Name, "err", err)
+		return err
+	}
+
+	h.Log.Info("Triggered rollout restart", "owner", owner.String(), "namespace", owner.Namespace)
+	return nil
+}
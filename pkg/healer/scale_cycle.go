@@ -0,0 +1,178 @@
+package healer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/daigoro86dev/k8s-healer/pkg/annotations"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// DefaultScaleCyclePause is how long a workload stays scaled to zero during a scale cycle.
+const DefaultScaleCyclePause = 10 * time.Second
+
+// scaleCycle cold-restarts the Pod's owning Deployment or StatefulSet by scaling it to zero and,
+// after ScaleCyclePause, back to its original replica count. The original count is recorded on the
+// workload first, so the next healer to start can restore it should this one die during the pause.
+func (h *Healer) scaleCycle(ctx context.Context, pod *v1.Pod, owner *OwnerInfo) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	scale, err := h.getScale(ctx, owner)
+	if err != nil {
+		return fmt.Errorf("failed to read scale of %s: %w", owner, err)
+	}
+	replicas := scale.Spec.Replicas
+	if replicas == 0 {
+		h.Log.Info("Workload already scaled to zero; nothing to cycle", "owner", owner.String())
+		return nil
+	}
+
+	if err := h.annotateWorkload(ctx, owner, annotations.ScaleCycleReplicas, strconv.Itoa(int(replicas))); err != nil {
+		return fmt.Errorf("failed to record replicas of %s: %w", owner, err)
+	}
+	scale.Spec.Replicas = 0
+	if err := h.updateScale(ctx, owner, scale); err != nil {
+		h.Log.Error("Failed to scale workload to zero", "owner", owner.String(), "pod", pod.Namespace+"/"+pod.Name, "err", err)
+		return err
+	}
+	h.Log.Info("Scaled workload to zero", "owner", owner.String(), "replicas", replicas, "restoreIn", h.ScaleCyclePause)
+
+	go h.restoreScale(owner, replicas)
+	return nil
+}
+
+// restoreScale scales the workload back up after the pause, retrying until it succeeds or the healer
+// stops. A healer stopping during the pause restores the workload right away.
+func (h *Healer) restoreScale(owner *OwnerInfo, replicas int32) {
+	select {
+	case <-time.After(h.ScaleCyclePause):
+	case <-h.StopCh:
+		if err := h.scaleBack(owner, replicas); err != nil {
+			h.Log.Warn("Stopping while workload is scaled to zero; the next start restores it", "owner", owner.String(),
+				"replicas", replicas, "annotation", annotations.ScaleCycleReplicas, "err", err)
+		} else {
+			h.Log.Info("Scaled workload back before stopping", "owner", owner.String(), "replicas", replicas)
+		}
+		return
+	}
+	h.retryScaleBack(owner, replicas)
+}
+
+// retryScaleBack scales the workload back up, retrying until it succeeds or the healer stops.
+func (h *Healer) retryScaleBack(owner *OwnerInfo, replicas int32) {
+	for attempt := 1; ; attempt++ {
+		err := h.scaleBack(owner, replicas)
+		if err == nil {
+			h.Log.Info("Scaled workload back", "owner", owner.String(), "replicas", replicas)
+			return
+		}
+
+		h.Log.Error("Failed to scale workload back", "owner", owner.String(), "replicas", replicas, "attempt", attempt, "err", err)
+		select {
+		case <-time.After(time.Duration(attempt) * 5 * time.Second):
+		case <-h.StopCh:
+			return
+		}
+	}
+}
+
+// scaleBack restores the workload's replica count and removes the annotation recording it. A
+// workload no longer at zero was scaled by someone else and keeps its replicas. The context is
+// detached from the heal's, so a stopping healer can still restore it.
+func (h *Healer) scaleBack(owner *OwnerInfo, replicas int32) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	scale, err := h.getScale(ctx, owner)
+	if err != nil {
+		return err
+	}
+	if scale.Spec.Replicas == 0 {
+		scale.Spec.Replicas = replicas
+		if err := h.updateScale(ctx, owner, scale); err != nil {
+			return err
+		}
+	}
+	return h.annotateWorkload(ctx, owner, annotations.ScaleCycleReplicas, "")
+}
+
+// resumeScaleCycles scales back the workloads a previous run left at zero during a scale cycle,
+// found by their ScaleCycleReplicas annotation.
+func (h *Healer) resumeScaleCycles() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	resume := func(kind string, meta metav1.ObjectMeta) {
+		replicas, ok, err := annotations.ParseScaleCycleReplicas(meta.Annotations)
+		if !ok || !h.watchesNamespace(meta.Namespace) {
+			return
+		}
+		owner := &OwnerInfo{Kind: kind, Name: meta.Name, Namespace: meta.Namespace, UID: meta.UID}
+		if err != nil {
+			h.Log.Warn("Can't resume interrupted scale cycle; restore the workload by hand", "owner", owner.String(), "err", err)
+			return
+		}
+		h.Log.Info("Resuming interrupted scale cycle", "owner", owner.String(), "replicas", replicas)
+		go h.retryScaleBack(owner, replicas)
+	}
+	for _, ns := range h.Namespaces {
+		deployments, err := h.ClientSet.AppsV1().Deployments(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			h.Log.Warn("Failed to look for interrupted scale cycles", "namespace", ns, "err", err)
+			continue
+		}
+		for _, d := range deployments.Items {
+			resume("Deployment", d.ObjectMeta)
+		}
+		statefulSets, err := h.ClientSet.AppsV1().StatefulSets(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			h.Log.Warn("Failed to look for interrupted scale cycles", "namespace", ns, "err", err)
+			continue
+		}
+		for _, s := range statefulSets.Items {
+			resume("StatefulSet", s.ObjectMeta)
+		}
+	}
+}
+
+func (h *Healer) getScale(ctx context.Context, owner *OwnerInfo) (*autoscalingv1.Scale, error) {
+	if owner.Kind == "StatefulSet" {
+		return h.ClientSet.AppsV1().StatefulSets(owner.Namespace).GetScale(ctx, owner.Name, metav1.GetOptions{})
+	}
+	return h.ClientSet.AppsV1().Deployments(owner.Namespace).GetScale(ctx, owner.Name, metav1.GetOptions{})
+}
+
+func (h *Healer) updateScale(ctx context.Context, owner *OwnerInfo, scale *autoscalingv1.Scale) error {
+	var err error
+	if owner.Kind == "StatefulSet" {
+		_, err = h.ClientSet.AppsV1().StatefulSets(owner.Namespace).UpdateScale(ctx, owner.Name, scale, metav1.UpdateOptions{})
+	} else {
+		_, err = h.ClientSet.AppsV1().Deployments(owner.Namespace).UpdateScale(ctx, owner.Name, scale, metav1.UpdateOptions{})
+	}
+	return err
+}
+
+// annotateWorkload sets (or, with an empty value, removes) an annotation on the Deployment/StatefulSet.
+func (h *Healer) annotateWorkload(ctx context.Context, owner *OwnerInfo, key, value string) error {
+	var v interface{} = value
+	if value == "" {
+		v = nil
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"annotations": map[string]interface{}{key: v}},
+	})
+	if err != nil {
+		return err
+	}
+	if owner.Kind == "StatefulSet" {
+		_, err = h.ClientSet.AppsV1().StatefulSets(owner.Namespace).Patch(ctx, owner.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	} else {
+		_, err = h.ClientSet.AppsV1().Deployments(owner.Namespace).Patch(ctx, owner.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	}
+	return err
+}
@@ -0,0 +1,415 @@
+package healer
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"github.com/daigoro86dev/k8s-healer/pkg/history"
+	"github.com/daigoro86dev/k8s-healer/pkg/state"
+	v1 "k8s.io/api/core/v1"
+)
+
+// historyKeyFormat formats heal record times in State keys, so keys sort by time.
+const historyKeyFormat = "2006-01-02T15:04:05.000000000Z"
+
+// workloadClaimTTL bounds how long a workload stays claimed in the State store by a healer that
+// stopped before releasing it.
+const workloadClaimTTL = 5 * time.Minute
+
+// restoreState loads the heal history, cooldowns and quarantines saved to the State store by a
+// previous run, or by the other healers sharing it.
+func (h *Healer) restoreState() {
+	if h.State == nil {
+		return
+	}
+	h.savedMu.Lock()
+	defer h.savedMu.Unlock()
+	marks := make(map[string]healMark)
+	err := h.State.ForEach(state.BucketCooldowns, func(key string, value []byte) error {
+		var mark healMark
+		if err := json.Unmarshal(value, &mark); err != nil {
+			return fmt.Errorf("cooldown %s: %w", key, err)
+		}
+		marks[key] = mark
+		h.markSaved(state.BucketCooldowns, key, value)
+		return nil
+	})
+	if err != nil {
+		h.Log.Warn("Failed to restore cooldowns from the state store", "err", err)
+	}
+	cooldowns := h.mergeCooldowns(marks)
+
+	quarantined := 0
+	err = h.State.ForEach(state.BucketQuarantines, func(key string, value []byte) error {
+		var at time.Time
+		if err := json.Unmarshal(value, &at); err != nil {
+			return fmt.Errorf("quarantine %s: %w", key, err)
+		}
+		h.markSaved(state.BucketQuarantines, key, value)
+		if _, ok := h.quarantines.get(key); !ok {
+			h.quarantines.set(key, at)
+			quarantined++
+		}
+		return nil
+	})
+	if err != nil {
+		h.Log.Warn("Failed to restore quarantines from the state store", "err", err)
+	}
+
+	records, rollups, err := LoadHistory(h.State)
+	if err != nil {
+		h.Log.Warn("Failed to restore the heal history from the state store", "err", err)
+	}
+	h.History.Restore(records, rollups)
+	for _, r := range records {
+		if raw, err := json.Marshal(r); err == nil {
+			h.markSaved(state.BucketHistory, historyKey(r), raw)
+		}
+	}
+	for _, r := range rollups {
+		if raw, err := json.Marshal(r); err == nil {
+			h.markSaved(state.BucketRollups, rollupKey(r), raw)
+		}
+	}
+	h.Log.Info("Restored state from the state store", "cooldowns", cooldowns, "quarantines", quarantined, "records", len(records))
+}
+
+// saveState syncs the heal history, cooldowns and quarantines with the State store. Entries other
+// healers sharing the store added since the last sync are merged in, the local entries that changed
+// since they were last written or read are written, and the ones this healer wrote before but has
+// since pruned, compacted or cleared are deleted.
+func (h *Healer) saveState() error {
+	buckets := map[string]map[string][]byte{
+		state.BucketCooldowns:   {},
+		state.BucketQuarantines: {},
+		state.BucketHistory:     {},
+		state.BucketRollups:     {},
+	}
+	var err error
+	put := func(bucket, key string, v interface{}) {
+		if err != nil {
+			return
+		}
+		var raw []byte
+		if raw, err = json.Marshal(v); err != nil {
+			err = fmt.Errorf("failed to encode %s %s: %w", bucket, key, err)
+			return
+		}
+		buckets[bucket][key] = raw
+	}
+
+	h.savedMu.Lock()
+	defer h.savedMu.Unlock()
+	if err := h.pullState(); err != nil {
+		return err
+	}
+
+	for key, mark := range h.cooldowns() {
+		put(state.BucketCooldowns, key, mark)
+	}
+	for key, at := range h.quarantines.snapshot() {
+		put(state.BucketQuarantines, key, at)
+	}
+	for _, r := range h.History.Records() {
+		put(state.BucketHistory, historyKey(r), r)
+	}
+	for _, r := range h.History.Rollups() {
+		put(state.BucketRollups, rollupKey(r), r)
+	}
+	if err != nil {
+		return err
+	}
+
+	for bucket, entries := range buckets {
+		saved := h.savedState[bucket]
+		changed := make(map[string][]byte)
+		for key, raw := range entries {
+			if sum, ok := saved[key]; !ok || sum != stateChecksum(raw) {
+				changed[key] = raw
+			}
+		}
+		if err := h.State.Put(bucket, changed); err != nil {
+			return fmt.Errorf("failed to save %s: %w", bucket, err)
+		}
+		for key, raw := range changed {
+			h.markSaved(bucket, key, raw)
+		}
+		var gone []string
+		for key := range saved {
+			if _, ok := entries[key]; !ok {
+				gone = append(gone, key)
+			}
+		}
+		if err := h.State.Delete(bucket, gone); err != nil {
+			return fmt.Errorf("failed to delete %s: %w", bucket, err)
+		}
+		for _, key := range gone {
+			delete(saved, key)
+		}
+	}
+	return nil
+}
+
+// pullState merges in the cooldowns, quarantines and heal records other healers wrote to the State
+// store since the last sync, and drops the cooldowns they cleared. Expired cooldowns are deleted
+// from the store. Callers hold savedMu.
+func (h *Healer) pullState() error {
+	now := time.Now()
+	marks := make(map[string]healMark)
+	read := make(map[string][]byte)
+	var expired []string
+	err := h.State.ForEach(state.BucketCooldowns, func(key string, value []byte) error {
+		var mark healMark
+		if err := json.Unmarshal(value, &mark); err != nil {
+			return fmt.Errorf("cooldown %s: %w", key, err)
+		}
+		if now.After(mark.Until.Add(h.CooldownReset)) {
+			expired = append(expired, key)
+			return nil
+		}
+		marks[key] = mark
+		read[key] = append([]byte(nil), value...)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read cooldowns: %w", err)
+	}
+	h.healedMu.Lock()
+	for key := range h.savedState[state.BucketCooldowns] {
+		if _, ok := read[key]; !ok {
+			// Cleared by another healer, or expired
+			h.HealedPods.delete(key)
+			delete(h.savedState[state.BucketCooldowns], key)
+		}
+	}
+	for key := range marks {
+		// Cooldowns this healer wrote but no longer holds were pruned or cleared
+		if _, ok := h.HealedPods.peek(key); !ok && h.saved(state.BucketCooldowns, key) {
+			delete(marks, key)
+		}
+	}
+	h.healedMu.Unlock()
+	h.mergeCooldowns(marks)
+	for key := range marks {
+		h.markSaved(state.BucketCooldowns, key, read[key])
+	}
+	if err := h.State.Delete(state.BucketCooldowns, expired); err != nil {
+		return fmt.Errorf("failed to delete expired cooldowns: %w", err)
+	}
+
+	err = h.State.ForEach(state.BucketQuarantines, func(key string, value []byte) error {
+		if h.saved(state.BucketQuarantines, key) {
+			return nil
+		}
+		var at time.Time
+		if err := json.Unmarshal(value, &at); err != nil {
+			return fmt.Errorf("quarantine %s: %w", key, err)
+		}
+		if _, ok := h.quarantines.get(key); !ok {
+			h.quarantines.set(key, at)
+			h.markSaved(state.BucketQuarantines, key, value)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read quarantines: %w", err)
+	}
+
+	// Records this healer wrote but no longer holds were compacted; the rest are new.
+	var records []history.Record
+	raws := make(map[string][]byte)
+	err = h.State.ForEach(state.BucketHistory, func(key string, value []byte) error {
+		if h.saved(state.BucketHistory, key) {
+			return nil
+		}
+		var r history.Record
+		if err := json.Unmarshal(value, &r); err != nil {
+			return fmt.Errorf("heal record %s: %w", key, err)
+		}
+		records = append(records, r)
+		raws[key] = append([]byte(nil), value...)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read the heal history: %w", err)
+	}
+	if len(records) > 0 {
+		held := make(map[string]bool)
+		for _, r := range h.History.Records() {
+			held[historyKey(r)] = true
+		}
+		var added []history.Record
+		for _, r := range records {
+			if key := historyKey(r); !held[key] {
+				added = append(added, r)
+				h.markSaved(state.BucketHistory, key, raws[key])
+			}
+		}
+		h.History.Restore(added, nil)
+	}
+	return nil
+}
+
+// claimWorkload reserves the Pod's workload among the queue workers and manual heals and, if the
+// State store is shared, among the healers sharing it. It returns false if the workload is already
+// being healed.
+func (h *Healer) claimWorkload(pod *v1.Pod) bool {
+	if !h.heals.claim(pod) {
+		return false
+	}
+	claimer, ok := h.State.(state.Claimer)
+	if !ok {
+		return true
+	}
+	claimed, err := claimer.Claim(cooldownKey(pod), h.Identity(), workloadClaimTTL)
+	if err != nil {
+		// The cooldown read from the store still catches the heals the other healers finished
+		h.Log.Warn("Failed to claim workload in the state store", "pod", pod.Namespace+"/"+pod.Name, "err", err)
+		return true
+	}
+	if !claimed {
+		h.heals.release(pod)
+	}
+	return claimed
+}
+
+// releaseWorkload gives up the reservation of the Pod's workload.
+func (h *Healer) releaseWorkload(pod *v1.Pod) {
+	if claimer, ok := h.State.(state.Claimer); ok {
+		if err := claimer.Release(cooldownKey(pod), h.Identity()); err != nil {
+			h.Log.Warn("Failed to release workload in the state store", "pod", pod.Namespace+"/"+pod.Name, "err", err)
+		}
+	}
+	h.heals.release(pod)
+}
+
+// refreshCooldown reads the cooldown of the Pod's workload from the State store before a heal is
+// decided, so heals by the other healers sharing it, and cooldowns they cleared, apply right away.
+func (h *Healer) refreshCooldown(pod *v1.Pod) {
+	if h.State == nil {
+		return
+	}
+	key := cooldownKey(pod)
+	raw, ok, err := h.State.Get(state.BucketCooldowns, key)
+	if err != nil {
+		h.Log.Warn("Failed to read cooldown from the state store", "pod", pod.Namespace+"/"+pod.Name, "err", err)
+		return
+	}
+	h.savedMu.Lock()
+	defer h.savedMu.Unlock()
+	if !ok {
+		// Cleared by another healer since this one wrote or read it
+		if h.saved(state.BucketCooldowns, key) {
+			delete(h.savedState[state.BucketCooldowns], key)
+			h.healedMu.Lock()
+			h.HealedPods.delete(key)
+			h.healedMu.Unlock()
+		}
+		return
+	}
+	var mark healMark
+	if err := json.Unmarshal(raw, &mark); err != nil {
+		h.Log.Warn("Ignoring invalid cooldown in the state store", "key", key, "err", err)
+		return
+	}
+	h.mergeCooldowns(map[string]healMark{key: mark})
+	h.markSaved(state.BucketCooldowns, key, raw)
+}
+
+// storeCooldown writes the cooldown to the State store as soon as it is recorded.
+func (h *Healer) storeCooldown(key string, mark healMark) {
+	if h.State == nil {
+		return
+	}
+	raw, err := json.Marshal(mark)
+	if err == nil {
+		h.savedMu.Lock()
+		defer h.savedMu.Unlock()
+		err = h.State.Put(state.BucketCooldowns, map[string][]byte{key: raw})
+	}
+	if err != nil {
+		h.Log.Warn("Failed to save cooldown to the state store", "key", key, "err", err)
+		return
+	}
+	h.markSaved(state.BucketCooldowns, key, raw)
+}
+
+// deleteCooldowns removes cleared cooldowns from the State store, so the healers sharing it, and
+// this one's next sync, don't bring them back.
+func (h *Healer) deleteCooldowns(keys []string) {
+	if h.State == nil || len(keys) == 0 {
+		return
+	}
+	h.savedMu.Lock()
+	defer h.savedMu.Unlock()
+	if err := h.State.Delete(state.BucketCooldowns, keys); err != nil {
+		h.Log.Warn("Failed to delete cleared cooldowns from the state store", "err", err)
+		return
+	}
+	for _, key := range keys {
+		delete(h.savedState[state.BucketCooldowns], key)
+	}
+}
+
+// saved reports whether the entry was written to or read from the State store. Callers hold
+// savedMu.
+func (h *Healer) saved(bucket, key string) bool {
+	_, ok := h.savedState[bucket][key]
+	return ok
+}
+
+// markSaved remembers the entry as it is in the State store. Callers hold savedMu.
+func (h *Healer) markSaved(bucket, key string, raw []byte) {
+	if h.savedState == nil {
+		h.savedState = make(map[string]map[string]uint64)
+	}
+	if h.savedState[bucket] == nil {
+		h.savedState[bucket] = make(map[string]uint64)
+	}
+	h.savedState[bucket][key] = stateChecksum(raw)
+}
+
+// stateChecksum identifies an entry's value, so unchanged entries aren't written again.
+func stateChecksum(raw []byte) uint64 {
+	sum := fnv.New64a()
+	_, _ = sum.Write(raw)
+	return sum.Sum64()
+}
+
+// rollupKey is the State key of a monthly rollup.
+func rollupKey(r history.Rollup) string {
+	return fmt.Sprintf("%s/%s/%s", r.Month, r.Namespace, r.Check)
+}
+
+// historyKey is the State key of a heal record.
+func historyKey(r history.Record) string {
+	return fmt.Sprintf("%s/%s/%s", r.Time.UTC().Format(historyKeyFormat), r.Namespace, r.Pod)
+}
+
+// LoadHistory reads the heal records, oldest first, and the monthly rollups from a state store.
+func LoadHistory(db state.Store) ([]history.Record, []history.Rollup, error) {
+	var records []history.Record
+	err := db.ForEach(state.BucketHistory, func(key string, value []byte) error {
+		var r history.Record
+		if err := json.Unmarshal(value, &r); err != nil {
+			return fmt.Errorf("heal record %s: %w", key, err)
+		}
+		records = append(records, r)
+		return nil
+	})
+	if err != nil {
+		return records, nil, err
+	}
+	var rollups []history.Rollup
+	err = db.ForEach(state.BucketRollups, func(key string, value []byte) error {
+		var r history.Rollup
+		if err := json.Unmarshal(value, &r); err != nil {
+			return fmt.Errorf("rollup %s: %w", key, err)
+		}
+		rollups = append(rollups, r)
+		return nil
+	})
+	return records, rollups, err
+}
@@ -0,0 +1,152 @@
+package healer
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/daigoro86dev/k8s-healer/pkg/control"
+	"github.com/daigoro86dev/k8s-healer/pkg/freeze"
+	"github.com/daigoro86dev/k8s-healer/pkg/history"
+)
+
+// Status is the healer's self-reported state, served as JSON on /status.
+type Status struct {
+	Healer     string      `json:"healer"`
+	Cluster    string      `json:"cluster,omitempty"`
+	StartedAt  time.Time   `json:"startedAt"`
+	Namespaces []string    `json:"namespaces"`
+	APIState   APIState    `json:"apiState"`
+	Queue      *QueueStats `json:"queue,omitempty"`
+	Paused     bool        `json:"paused"`
+	PausedBy   string      `json:"pausedBy,omitempty"`
+
+	HealsLastHour       int `json:"healsLastHour"`
+	FailedHealsLastHour int `json:"failedHealsLastHour"`
+	HealsTotal          int `json:"healsTotal"`
+	HealsInProgress     int `json:"healsInProgress"` // Removed Pods whose replacement isn't seen yet
+
+	// DegradedNamespaces lists namespaces with heals in the last hour, most heals first.
+	DegradedNamespaces []NamespaceHeals `json:"degradedNamespaces,omitempty"`
+	// CircuitBreakers lists the scopes in which healing is currently halted.
+	CircuitBreakers []string           `json:"circuitBreakers,omitempty"`
+	Blackouts       []control.Blackout `json:"blackouts,omitempty"`
+	Freezes         []freeze.Window    `json:"freezes,omitempty"` // Calendar freeze windows in effect
+
+	// ChronicFailures lists the workloads healed so often that healing them is notify-only.
+	ChronicFailures []ChronicFailure `json:"chronicFailures,omitempty"`
+
+	// Effectiveness reports per check how many heals fixed the workload for good.
+	Effectiveness []history.Effectiveness `json:"effectiveness,omitempty"`
+
+	// RecoveryTime is the distribution of the time from a heal until its replacement was Ready, and
+	// HealSuccessRate the share of retained heals that neither failed, stayed unrecovered nor relapsed.
+	RecoveryTime    RecoveryHistogram `json:"recoveryTime"`
+	HealSuccessRate *float64          `json:"healSuccessRate,omitempty"`
+}
+
+// NamespaceHeals counts recent heals in one namespace.
+type NamespaceHeals struct {
+	Namespace string `json:"namespace"`
+	Heals     int    `json:"heals"`
+	Failures  int    `json:"failures"`
+}
+
+// Status returns a snapshot of the healer's state.
+func (h *Healer) Status() Status {
+	now := time.Now()
+	st := Status{
+		Healer:     h.Identity(),
+		Cluster:    h.ClusterName,
+		StartedAt:  h.startedAt,
+		Namespaces: h.Namespaces,
+		APIState:   h.apiHealth.State(),
+
+		HealsInProgress: h.inFlight.count(),
+		ChronicFailures: h.chronicFailures(),
+		Effectiveness:   h.History.Effectiveness(),
+		RecoveryTime:    h.recoveryStats.histogram(),
+	}
+
+	// Discovered namespaces change over time
+	if h.discoversNamespaces() {
+		st.Namespaces = h.watchedNamespaces()
+		sort.Strings(st.Namespaces)
+	}
+
+	queue := h.queue.stats()
+	st.Queue = &queue
+	if by, reason, paused := h.paused.get(); paused {
+		st.Paused, st.PausedBy = true, fmt.Sprintf("%s (%s)", by, reason)
+	}
+	if why := h.breakerOpen(); why != "" {
+		st.CircuitBreakers = append(st.CircuitBreakers, "cluster: "+why)
+	}
+
+	perNamespace := make(map[string]*NamespaceHeals)
+	succeeded := 0
+	for _, r := range h.History.Records() {
+		if r.Action == ActionCleanup {
+			continue
+		}
+		st.HealsTotal++
+		if r.Result == "success" && r.Outcome != history.OutcomeUnrecovered && r.Outcome != history.OutcomeRelapsed {
+			succeeded++
+		}
+		if now.Sub(r.Time) > time.Hour {
+			continue
+		}
+		st.HealsLastHour++
+		ns, ok := perNamespace[r.Namespace]
+		if !ok {
+			ns = &NamespaceHeals{Namespace: r.Namespace}
+			perNamespace[r.Namespace] = ns
+		}
+		ns.Heals++
+		if r.Result != "success" {
+			st.FailedHealsLastHour++
+			ns.Failures++
+		}
+	}
+	if st.HealsTotal > 0 {
+		rate := float64(succeeded) / float64(st.HealsTotal)
+		st.HealSuccessRate = &rate
+	}
+	for _, ns := range perNamespace {
+		st.DegradedNamespaces = append(st.DegradedNamespaces, *ns)
+	}
+	sort.Slice(st.DegradedNamespaces, func(i, j int) bool {
+		if st.DegradedNamespaces[i].Heals != st.DegradedNamespaces[j].Heals {
+			return st.DegradedNamespaces[i].Heals > st.DegradedNamespaces[j].Heals
+		}
+		return st.DegradedNamespaces[i].Namespace < st.DegradedNamespaces[j].Namespace
+	})
+
+	if state := h.control.get(); state != nil {
+		for _, b := range state.Blackouts {
+			if b.Active(now) {
+				st.Blackouts = append(st.Blackouts, b)
+			}
+		}
+	}
+	for _, w := range h.freezes.get() {
+		if w.Active(now) {
+			st.Freezes = append(st.Freezes, w)
+		}
+	}
+	return st
+}
+
+// StatusHandler serves the healer's status and control API.
+func (h *Healer) StatusHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, h.Status())
+	})
+	h.registerControlAPI(mux)
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	return mux
+}
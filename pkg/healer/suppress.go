@@ -0,0 +1,54 @@
+package healer
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/daigoro86dev/k8s-healer/pkg/notify"
+	v1 "k8s.io/api/core/v1"
+)
+
+// suppressions remembers which Pods were recently reported as notify-only, so a suppressed heal
+// produces one notification per cooldown instead of one per informer resync.
+type suppressions struct {
+	mu     sync.Mutex
+	lastAt map[string]time.Time
+}
+
+func newSuppressions() *suppressions {
+	return &suppressions{lastAt: make(map[string]time.Time)}
+}
+
+// shouldReport returns true if the key was not reported within the interval, and marks it reported.
+func (s *suppressions) shouldReport(key string, interval time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if t, ok := s.lastAt[key]; ok && time.Since(t) < interval {
+		return false
+	}
+	s.lastAt[key] = time.Now()
+	return true
+}
+
+// prune forgets entries older than maxAge.
+func (s *suppressions) prune(now time.Time, maxAge time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, t := range s.lastAt {
+		if now.Sub(t) > maxAge {
+			delete(s.lastAt, key)
+		}
+	}
+}
+
+// suppressHeal downgrades a heal to notify-only: the failure is logged and notified (once per
+// cooldown) but no action is taken against the Pod.
+func (h *Healer) suppressHeal(pod *v1.Pod, f *failure, why string) {
+	podKey := fmt.Sprintf("%s/%s", pod.Namespace, pod.Name)
+	if !h.suppressed.shouldReport(podKey, h.cooldownFor(pod)) {
+		return
+	}
+	h.Log.Warn("Healing suppressed; notifying only", "pod", podKey, "reason", f.Reason, "why", why)
+	h.notify(pod, notify.EventHealSuppressed, notify.SeverityWarning, f, "none", fmt.Sprintf("Healing suppressed: %s.", why))
+}
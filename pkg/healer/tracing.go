@@ -0,0 +1,76 @@
+package healer
+
+import (
+	"net/http"
+
+	v1 "k8s.io/api/core/v1"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer creates the spans of heals: detection, the decision and the action, with the API calls
+// made along the way. Nothing is recorded until a tracer provider is installed (--otlp-endpoint).
+var tracer = otel.Tracer("github.com/daigoro86dev/k8s-healer/pkg/healer")
+
+// Span attributes beyond the semantic conventions.
+const (
+	attrCheck     = attribute.Key("k8s_healer.check")
+	attrReason    = attribute.Key("k8s_healer.reason")
+	attrAction    = attribute.Key("k8s_healer.action")
+	attrOwner     = attribute.Key("k8s_healer.owner")
+	attrSkipCause = attribute.Key("k8s_healer.skip.cause")
+)
+
+// podAttributes identify the Pod a span is about.
+func podAttributes(pod *v1.Pod) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		semconv.K8SNamespaceName(pod.Namespace),
+		semconv.K8SPodName(pod.Name),
+		semconv.K8SPodUID(string(pod.UID)),
+	}
+}
+
+// endSpan marks the span failed if err is set and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// traceTransport records a client span for every request made within a traced heal and passes the
+// trace on to the server. Requests outside of a heal, like the informers' list and watch calls, are
+// left alone. It is installed on the Kubernetes clients through rest.Config.Wrap.
+func traceTransport(rt http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if !trace.SpanContextFromContext(req.Context()).IsValid() {
+			return rt.RoundTrip(req)
+		}
+		ctx, span := tracer.Start(req.Context(), req.Method, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(req.Method),
+			semconv.ServerAddress(req.URL.Hostname()),
+			semconv.URLPath(req.URL.Path),
+		))
+		req = req.Clone(ctx)
+		otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+		resp, err := rt.RoundTrip(req)
+		if resp != nil {
+			span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+			if resp.StatusCode >= 400 && err == nil {
+				span.SetStatus(codes.Error, resp.Status)
+			}
+		}
+		endSpan(span, err)
+		return resp, err
+	})
+}
+
+// webhookClient calls the decision and remediation webhooks, which join the heal's trace.
+var webhookClient = &http.Client{Transport: traceTransport(http.DefaultTransport)}
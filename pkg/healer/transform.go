@@ -0,0 +1,67 @@
+package healer
+
+import (
+	"strings"
+
+	"github.com/daigoro86dev/k8s-healer/pkg/annotations"
+	v1 "k8s.io/api/core/v1"
+)
+
+// maxCachedAnnotationBytes is the largest foreign annotation value kept in the Pod cache. Bigger ones
+// (kubectl's last-applied-configuration, sidecar injection status and the like) are dropped; the
+// healer's own annotations are always kept.
+const maxCachedAnnotationBytes = 512
+
+// slimPod is the transform of the Pod informers: it strips what health evaluation never reads before
+// the Pod is cached, which dominates the healer's memory on large clusters. managedFields and large
+// foreign annotations are always dropped. The spec is cut down to the containers' names, resources
+// and probes, the PVC volumes, the node and the restart policy, unless CustomConditions are set:
+// CEL expressions may read any field, so the spec is then cached in full.
+func (h *Healer) slimPod(obj interface{}) (interface{}, error) {
+	pod, ok := obj.(*v1.Pod)
+	if !ok {
+		// Tombstones (cache.DeletedFinalStateUnknown) carry objects that were already transformed
+		return obj, nil
+	}
+	pod.ManagedFields = nil
+	for key, value := range pod.Annotations {
+		if len(value) > maxCachedAnnotationBytes && !strings.HasPrefix(key, annotations.Prefix) {
+			delete(pod.Annotations, key)
+		}
+	}
+	if len(h.CustomConditions) > 0 {
+		return pod, nil
+	}
+
+	spec := v1.PodSpec{
+		Containers:     slimContainers(pod.Spec.Containers),
+		InitContainers: slimContainers(pod.Spec.InitContainers),
+		NodeName:       pod.Spec.NodeName,
+		RestartPolicy:  pod.Spec.RestartPolicy,
+	}
+	for _, vol := range pod.Spec.Volumes {
+		if vol.PersistentVolumeClaim != nil {
+			spec.Volumes = append(spec.Volumes, v1.Volume{Name: vol.Name, VolumeSource: v1.VolumeSource{PersistentVolumeClaim: vol.PersistentVolumeClaim}})
+		}
+	}
+	pod.Spec = spec
+	return pod, nil
+}
+
+// slimContainers keeps the container fields the checks and heal actions use.
+func slimContainers(containers []v1.Container) []v1.Container {
+	if len(containers) == 0 {
+		return nil
+	}
+	out := make([]v1.Container, len(containers))
+	for i, c := range containers {
+		out[i] = v1.Container{
+			Name:           c.Name,
+			Resources:      c.Resources,
+			StartupProbe:   c.StartupProbe,
+			LivenessProbe:  c.LivenessProbe,
+			ReadinessProbe: c.ReadinessProbe,
+		}
+	}
+	return out
+}
@@ -0,0 +1,54 @@
+package healer
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// unhealthyTracker remembers since when each Pod has been continuously unhealthy.
+type unhealthyTracker struct {
+	mu    sync.Mutex
+	since map[types.UID]unhealthySpan
+}
+
+type unhealthySpan struct {
+	since    time.Time // First observation of the current unhealthy streak
+	lastSeen time.Time // Last observation, used to forget Pods that disappeared
+}
+
+func newUnhealthyTracker() *unhealthyTracker {
+	return &unhealthyTracker{since: make(map[types.UID]unhealthySpan)}
+}
+
+// observe records that the Pod is unhealthy now and returns how long it has been unhealthy.
+func (t *unhealthyTracker) observe(uid types.UID, now time.Time) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	span, ok := t.since[uid]
+	if !ok {
+		span.since = now
+	}
+	span.lastSeen = now
+	t.since[uid] = span
+	return now.Sub(span.since)
+}
+
+// clear ends the unhealthy streak of the Pod.
+func (t *unhealthyTracker) clear(uid types.UID) {
+	t.mu.Lock()
+	delete(t.since, uid)
+	t.mu.Unlock()
+}
+
+// prune forgets Pods that have not been observed for maxAge (e.g. because they were deleted).
+func (t *unhealthyTracker) prune(now time.Time, maxAge time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for uid, span := range t.since {
+		if now.Sub(span.lastSeen) > maxAge {
+			delete(t.since, uid)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package healer
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Watch strategies: WatchPerNamespace runs informers per watched namespace, WatchCluster runs a
+// single set of cluster-wide informers and filters namespaces in process, and WatchAuto (default)
+// picks the cluster-wide strategy when more than ClusterWatchThreshold namespaces are watched.
+const (
+	WatchAuto         = "auto"
+	WatchPerNamespace = "per-namespace"
+	WatchCluster      = "cluster"
+)
+
+// DefaultClusterWatchThreshold is the number of watched namespaces above which WatchAuto switches
+// to a single cluster-wide informer.
+const DefaultClusterWatchThreshold = 10
+
+// useClusterWatch decides the watch strategy for the namespaces about to be watched, once at startup.
+// Watching all namespaces is cluster-wide anyway.
+func (h *Healer) useClusterWatch(namespaces []string) bool {
+	if len(namespaces) == 1 && namespaces[0] == metav1.NamespaceAll {
+		return false
+	}
+	switch h.WatchStrategy {
+	case WatchCluster:
+		return true
+	case WatchPerNamespace:
+		return false
+	}
+	return len(namespaces) > h.ClusterWatchThreshold
+}
+
+// watchesNamespace reports whether Pods of the namespace are processed: it isn't excluded and, with
+// a cluster-wide watch, it is one of the watched namespaces.
+func (h *Healer) watchesNamespace(namespace string) bool {
+	if h.ExcludesNamespace(namespace) {
+		return false
+	}
+	return !h.clusterWatch || h.isNamespaceWatched(namespace)
+}
+
+// describeWatchStrategy formats the strategy in use for the startup log.
+func (h *Healer) describeWatchStrategy() string {
+	if h.clusterWatch {
+		return fmt.Sprintf("one cluster-wide informer filtered to %d namespace(s)", len(h.Namespaces))
+	}
+	return "informers per namespace"
+}
@@ -0,0 +1,114 @@
+package healer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/daigoro86dev/k8s-healer/pkg/notify"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// checkStuckTerminating is the check name for Pods wedged in Terminating by their finalizers.
+const checkStuckTerminating = "stuck-terminating"
+
+// ActionRemoveFinalizers patches a wedged Pod's finalizers away and force-deletes it.
+const ActionRemoveFinalizers = "remove-finalizers"
+
+// DefaultStuckTerminatingAfter is how long past its grace period a Pod must still be terminating
+// before it is considered wedged.
+const DefaultStuckTerminatingAfter = 15 * time.Minute
+
+// handleWedgedPod deals with Pods stuck Terminating because finalizers are never removed (e.g. the
+// controller owning them is gone). Removing finalizers skips whatever cleanup they guard, so it only
+// happens with AllowFinalizerRemoval, and passes the same guards as heals; otherwise the Pod is
+// reported. It returns true if the Pod is terminating, so it is never healed otherwise.
+func (h *Healer) handleWedgedPod(pod *v1.Pod) bool {
+	if pod.DeletionTimestamp == nil {
+		return false
+	}
+	if len(pod.Finalizers) == 0 {
+		return true
+	}
+
+	// DeletionTimestamp already includes the grace period
+	stuckFor := time.Since(pod.DeletionTimestamp.Time)
+	if stuckFor < h.StuckTerminatingAfter {
+		return true
+	}
+
+	podKey := fmt.Sprintf("%s/%s", pod.Namespace, pod.Name)
+	f := &failure{
+		Check: checkStuckTerminating,
+		Reason: fmt.Sprintf("Stuck Terminating for %s on finalizers %s",
+			stuckFor.Round(time.Second), strings.Join(pod.Finalizers, ", ")),
+	}
+	if !h.AllowFinalizerRemoval {
+		h.suppressHeal(pod, f, "finalizer removal is not enabled (--allow-finalizer-removal)")
+		return true
+	}
+	if _, ok := h.coolingDown(pod); ok {
+		return true
+	}
+	ctx := context.Background()
+	if owner := h.owners.Resolve(pod); h.quarantined(owner) {
+		h.recordSkip(ctx, pod, f, skipQuarantined)
+		h.Log.Info("Not releasing wedged pod: workload is quarantined", "pod", podKey, "owner", owner.String())
+		return true
+	}
+	if cause, why := h.halted(pod.Namespace); cause != "" {
+		h.recordSkip(ctx, pod, f, cause)
+		h.suppressHeal(pod, f, why)
+		return true
+	}
+	if ok, why := h.apiAllows(actionHeal); !ok {
+		h.recordSkip(ctx, pod, f, skipAPIDegraded)
+		h.Log.Info("Deferring release of wedged pod: API server is struggling", "pod", podKey, "why", why)
+		return true
+	}
+	if !h.healAllowed() {
+		h.recordSkip(ctx, pod, f, skipRateLimit)
+		h.Log.Info("Deferring release of wedged pod: heal rate limit reached", "pod", podKey, "maxHealsPerMinute", h.MaxHealsPerMinute)
+		return true
+	}
+
+	h.Log.Warn("Removing finalizers of wedged pod", "pod", podKey, "finalizers", pod.Finalizers,
+		"terminatingFor", stuckFor.Round(time.Second))
+	err := h.removeFinalizers(pod)
+	h.recordBreakerHeal()
+	h.markHealed(pod, time.Now())
+	h.recordHeal(pod, f, ActionRemoveFinalizers, err)
+	if err != nil {
+		h.Log.Error("Failed to release wedged pod", "pod", podKey, "err", err)
+		h.notify(pod, notify.EventHealFailed, notify.SeverityCritical, f, ActionRemoveFinalizers, err.Error())
+	} else {
+		h.Log.Info("Released wedged pod", "pod", podKey)
+		h.notify(pod, notify.EventHeal, notify.SeverityWarning, f, ActionRemoveFinalizers, "Finalizers removed and Pod force-deleted.")
+	}
+	return true
+}
+
+// removeFinalizers clears the Pod's finalizers and force-deletes it, so it does not linger waiting
+// for its containers. Force deletion is still subject to the data-sensitivity guard.
+func (h *Healer) removeFinalizers(pod *v1.Pod) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// The resourceVersion precondition makes the patch fail if the finalizers changed meanwhile.
+	patch := fmt.Sprintf(`{"metadata":{"finalizers":null,"resourceVersion":%q}}`, pod.ResourceVersion)
+	_, err := h.ClientSet.CoreV1().Pods(pod.Namespace).Patch(ctx, pod.Name, types.MergePatchType, []byte(patch), metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to remove finalizers: %w", err)
+	}
+
+	zero := int64(0)
+	err = h.deletePod(ctx, pod, metav1.DeleteOptions{GracePeriodSeconds: &zero})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to force-delete: %w", err)
+	}
+	return nil
+}
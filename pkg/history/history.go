@@ -0,0 +1,377 @@
+package history
+
+import (
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Record is a single healing action taken by the healer.
+type Record struct {
+	Time      time.Time `json:"time"`
+	Namespace string    `json:"namespace"`
+	Pod       string    `json:"pod"`
+	Owner     string    `json:"owner,omitempty"`
+	Check     string    `json:"check"`
+	Reason    string    `json:"reason"`
+	Action    string    `json:"action"`
+	Result    string    `json:"result"`
+	Outcome   string    `json:"outcome,omitempty"` // One of the Outcome constants once known
+
+	// RecoverySeconds is how long a replacement took to become Ready after the heal.
+	RecoverySeconds float64 `json:"recoverySeconds,omitempty"`
+}
+
+// Heal outcomes: whether the replacement became Ready within the recovery timeout, and whether it
+// then stayed healthy for the effectiveness window.
+const (
+	OutcomeRecovered   = "recovered"   // The replacement became Ready; effective or relapsed follows
+	OutcomeUnrecovered = "unrecovered" // No replacement became Ready in time
+	OutcomeEffective   = "effective"
+	OutcomeRelapsed    = "relapsed"
+)
+
+// Rollup summarizes the compacted records of one month, namespace and check.
+type Rollup struct {
+	Month       string `json:"month"` // YYYY-MM, UTC
+	Namespace   string `json:"namespace"`
+	Check       string `json:"check"`
+	Heals       int    `json:"heals"`
+	Failures    int    `json:"failures"`
+	Effective   int    `json:"effective,omitempty"`
+	Relapsed    int    `json:"relapsed,omitempty"`
+	Unrecovered int    `json:"unrecovered,omitempty"`
+}
+
+// Effectiveness summarizes how often heals detected by one check actually fixed the workload.
+type Effectiveness struct {
+	Check       string  `json:"check"`
+	Effective   int     `json:"effective"`
+	Relapsed    int     `json:"relapsed"`
+	Unrecovered int     `json:"unrecovered"`
+	Score       float64 `json:"score"` // Effective / (Effective + Relapsed + Unrecovered)
+}
+
+// Retention bounds how much heal history is kept. Zero values disable the respective limit.
+type Retention struct {
+	MaxAge       time.Duration // Detailed records older than this are folded into monthly rollups
+	MaxRecords   int           // At most this many detailed records are kept; the oldest are rolled up first
+	RollupMonths int           // Monthly rollups older than this many months are dropped
+}
+
+// DefaultRetention keeps 30 days / 1000 detailed records and one year of monthly rollups.
+var DefaultRetention = Retention{
+	MaxAge:       30 * 24 * time.Hour,
+	MaxRecords:   1000,
+	RollupMonths: 12,
+}
+
+type rollupKey struct {
+	month     string
+	namespace string
+	check     string
+}
+
+// Store is an in-memory heal history. Detailed records are compacted into monthly rollups
+// according to its Retention, so long-running installations don't grow unbounded state.
+type Store struct {
+	mu        sync.Mutex
+	retention Retention
+	records   []Record // ordered by Time, oldest first
+	rollups   map[rollupKey]*Rollup
+}
+
+// NewStore returns an empty store using the given retention policy.
+func NewStore(retention Retention) *Store {
+	return &Store{
+		retention: retention,
+		rollups:   make(map[rollupKey]*Rollup),
+	}
+}
+
+// Add appends a record to the history.
+func (s *Store) Add(r Record) {
+	if r.Time.IsZero() {
+		r.Time = time.Now()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Records almost always arrive in order; keep the slice sorted for the rare exception.
+	i := sort.Search(len(s.records), func(i int) bool { return s.records[i].Time.After(r.Time) })
+	s.records = append(s.records, Record{})
+	copy(s.records[i+1:], s.records[i:])
+	s.records[i] = r
+}
+
+// Records returns a copy of the detailed records, oldest first.
+func (s *Store) Records() []Record {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Record(nil), s.records...)
+}
+
+// Rollups returns the monthly rollups, ordered by month, namespace and check.
+func (s *Store) Rollups() []Rollup {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make([]Rollup, 0, len(s.rollups))
+	for _, r := range s.rollups {
+		out = append(out, *r)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Month != out[j].Month {
+			return out[i].Month < out[j].Month
+		}
+		if out[i].Namespace != out[j].Namespace {
+			return out[i].Namespace < out[j].Namespace
+		}
+		return out[i].Check < out[j].Check
+	})
+	return out
+}
+
+// Restore adds records and rollups saved from another store, such as one persisted before a
+// restart. Rollups are merged with the existing ones.
+func (s *Store) Restore(records []Record, rollups []Rollup) {
+	for _, r := range records {
+		s.Add(r)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range rollups {
+		key := rollupKey{month: r.Month, namespace: r.Namespace, check: r.Check}
+		existing, ok := s.rollups[key]
+		if !ok {
+			r := r
+			s.rollups[key] = &r
+			continue
+		}
+		existing.Heals += r.Heals
+		existing.Failures += r.Failures
+		existing.Effective += r.Effective
+		existing.Relapsed += r.Relapsed
+		existing.Unrecovered += r.Unrecovered
+	}
+}
+
+// SetOutcome records the outcome of the heal of the Pod at the given time. It returns false if the
+// record is no longer held in detail (e.g. it was compacted).
+func (s *Store) SetOutcome(at time.Time, namespace, pod, outcome string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r := s.find(at, namespace, pod)
+	if r == nil {
+		return false
+	}
+	r.Outcome = outcome
+	return true
+}
+
+// SetRecovered records that a replacement of the Pod healed at the given time became Ready after
+// the given time. It returns false if the record is no longer held in detail.
+func (s *Store) SetRecovered(at time.Time, namespace, pod string, after time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r := s.find(at, namespace, pod)
+	if r == nil {
+		return false
+	}
+	r.Outcome = OutcomeRecovered
+	r.RecoverySeconds = after.Seconds()
+	return true
+}
+
+// find returns the record of the heal of the Pod at the given time. The caller holds s.mu.
+func (s *Store) find(at time.Time, namespace, pod string) *Record {
+	i := sort.Search(len(s.records), func(i int) bool { return !s.records[i].Time.Before(at) })
+	for ; i < len(s.records) && s.records[i].Time.Equal(at); i++ {
+		if s.records[i].Namespace == namespace && s.records[i].Pod == pod {
+			return &s.records[i]
+		}
+	}
+	return nil
+}
+
+// Effectiveness returns the outcome counts per check over the whole retained history, including
+// rollups, ordered by check.
+func (s *Store) Effectiveness() []Effectiveness {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	byCheck := make(map[string]*Effectiveness)
+	add := func(check string, effective, relapsed, unrecovered int) {
+		if effective+relapsed+unrecovered == 0 {
+			return
+		}
+		e, ok := byCheck[check]
+		if !ok {
+			e = &Effectiveness{Check: check}
+			byCheck[check] = e
+		}
+		e.Effective += effective
+		e.Relapsed += relapsed
+		e.Unrecovered += unrecovered
+	}
+	for _, r := range s.records {
+		switch r.Outcome {
+		case OutcomeEffective:
+			add(r.Check, 1, 0, 0)
+		case OutcomeRelapsed:
+			add(r.Check, 0, 1, 0)
+		case OutcomeUnrecovered:
+			add(r.Check, 0, 0, 1)
+		}
+	}
+	for _, r := range s.rollups {
+		add(r.Check, r.Effective, r.Relapsed, r.Unrecovered)
+	}
+
+	out := make([]Effectiveness, 0, len(byCheck))
+	for _, e := range byCheck {
+		e.Score = float64(e.Effective) / float64(e.Effective+e.Relapsed+e.Unrecovered)
+		out = append(out, *e)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Check < out[j].Check })
+	return out
+}
+
+// WorkloadStats summarizes the heals of one workload.
+type WorkloadStats struct {
+	Namespace   string  `json:"namespace"`
+	Owner       string  `json:"owner"`
+	Heals       int     `json:"heals"`
+	Failures    int     `json:"failures"`    // The healing action itself failed
+	Unrecovered int     `json:"unrecovered"` // No replacement became Ready in time
+	Relapsed    int     `json:"relapsed"`
+	SuccessRate float64 `json:"successRate"` // Heals that neither failed, stayed unrecovered nor relapsed
+
+	// Time from the heal until a replacement was Ready, over the heals with a known recovery time.
+	RecoveryP50Seconds float64 `json:"recoveryP50Seconds,omitempty"`
+	RecoveryP90Seconds float64 `json:"recoveryP90Seconds,omitempty"`
+	RecoveryMaxSeconds float64 `json:"recoveryMaxSeconds,omitempty"`
+}
+
+// Workloads summarizes the records per owning workload, ordered by namespace and owner. Records of
+// Pods without an owner are left out.
+func Workloads(records []Record) []WorkloadStats {
+	type workload struct {
+		stats    WorkloadStats
+		recovery []float64
+	}
+	byOwner := make(map[string]*workload)
+	for _, r := range records {
+		if r.Owner == "" || r.Owner == "<none>" {
+			continue
+		}
+		key := r.Namespace + "/" + r.Owner
+		w, ok := byOwner[key]
+		if !ok {
+			w = &workload{stats: WorkloadStats{Namespace: r.Namespace, Owner: r.Owner}}
+			byOwner[key] = w
+		}
+		w.stats.Heals++
+		switch {
+		case r.Result != "" && r.Result != "success":
+			w.stats.Failures++
+		case r.Outcome == OutcomeUnrecovered:
+			w.stats.Unrecovered++
+		case r.Outcome == OutcomeRelapsed:
+			w.stats.Relapsed++
+		}
+		if r.RecoverySeconds > 0 {
+			w.recovery = append(w.recovery, r.RecoverySeconds)
+		}
+	}
+
+	out := make([]WorkloadStats, 0, len(byOwner))
+	for _, w := range byOwner {
+		st := w.stats
+		st.SuccessRate = float64(st.Heals-st.Failures-st.Unrecovered-st.Relapsed) / float64(st.Heals)
+		if n := len(w.recovery); n > 0 {
+			sort.Float64s(w.recovery)
+			st.RecoveryP50Seconds = w.recovery[(n-1)/2]
+			st.RecoveryP90Seconds = w.recovery[(n-1)*9/10]
+			st.RecoveryMaxSeconds = w.recovery[n-1]
+		}
+		out = append(out, st)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Namespace != out[j].Namespace {
+			return out[i].Namespace < out[j].Namespace
+		}
+		return out[i].Owner < out[j].Owner
+	})
+	return out
+}
+
+// Compact folds records that fall outside the retention policy into monthly rollups and drops
+// expired rollups. It returns the number of detailed records that were compacted.
+func (s *Store) Compact(now time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cut := 0
+	if s.retention.MaxAge > 0 {
+		cutoff := now.Add(-s.retention.MaxAge)
+		cut = sort.Search(len(s.records), func(i int) bool { return s.records[i].Time.After(cutoff) })
+	}
+	if s.retention.MaxRecords > 0 && len(s.records)-cut > s.retention.MaxRecords {
+		cut = len(s.records) - s.retention.MaxRecords
+	}
+
+	for _, r := range s.records[:cut] {
+		key := rollupKey{month: r.Time.UTC().Format("2006-01"), namespace: r.Namespace, check: r.Check}
+		rollup, ok := s.rollups[key]
+		if !ok {
+			rollup = &Rollup{Month: key.month, Namespace: key.namespace, Check: key.check}
+			s.rollups[key] = rollup
+		}
+		rollup.Heals++
+		if r.Result != "" && r.Result != "success" {
+			rollup.Failures++
+		}
+		switch r.Outcome {
+		case OutcomeEffective:
+			rollup.Effective++
+		case OutcomeRelapsed:
+			rollup.Relapsed++
+		case OutcomeUnrecovered:
+			rollup.Unrecovered++
+		}
+	}
+	s.records = append([]Record(nil), s.records[cut:]...)
+
+	if s.retention.RollupMonths > 0 {
+		oldest := now.UTC().AddDate(0, -s.retention.RollupMonths, 0).Format("2006-01")
+		for key := range s.rollups {
+			if key.month < oldest {
+				delete(s.rollups, key)
+			}
+		}
+	}
+
+	return cut
+}
+
+// RunCompactor compacts the store every interval until stopCh is closed.
+func (s *Store) RunCompactor(interval time.Duration, stopCh <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if n := s.Compact(time.Now()); n > 0 {
+				slog.Info("Compacted heal records into monthly rollups", "records", n)
+			}
+		case <-stopCh:
+			return
+		}
+	}
+}
@@ -0,0 +1,187 @@
+// Package metrics renders metrics in the Prometheus text exposition format. It covers what the
+// healer needs — labeled counters and values computed at scrape time — without the Prometheus
+// client library.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Collector writes one or more metric families.
+type Collector interface {
+	Collect(w *Writer)
+}
+
+// CollectorFunc adapts a function to a Collector.
+type CollectorFunc func(w *Writer)
+
+// Collect calls f.
+func (f CollectorFunc) Collect(w *Writer) { f(w) }
+
+// Registry holds the collectors served on /metrics.
+type Registry struct {
+	mu         sync.Mutex
+	collectors []Collector
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds collectors to the registry.
+func (r *Registry) Register(cs ...Collector) {
+	r.mu.Lock()
+	r.collectors = append(r.collectors, cs...)
+	r.mu.Unlock()
+}
+
+// Write writes all metrics to out.
+func (r *Registry) Write(out io.Writer) error {
+	r.mu.Lock()
+	collectors := append([]Collector(nil), r.collectors...)
+	r.mu.Unlock()
+
+	w := &Writer{w: bufio.NewWriter(out)}
+	for _, c := range collectors {
+		c.Collect(w)
+	}
+	return w.w.Flush()
+}
+
+// Handler serves the metrics.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = r.Write(w)
+	})
+}
+
+// Writer writes metric families in the text exposition format.
+type Writer struct {
+	w *bufio.Writer
+}
+
+// Header starts a metric family of the given type (counter, gauge or histogram).
+func (w *Writer) Header(name, help, typ string) {
+	fmt.Fprintf(w.w, "# HELP %s %s\n# TYPE %s %s\n", name, helpEscaper.Replace(help), name, typ)
+}
+
+// Sample writes one sample. labels alternate names and values.
+func (w *Writer) Sample(name string, value float64, labels ...string) {
+	w.w.WriteString(name)
+	if len(labels) > 0 {
+		w.w.WriteByte('{')
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				w.w.WriteByte(',')
+			}
+			fmt.Fprintf(w.w, "%s=\"%s\"", labels[i], labelEscaper.Replace(labels[i+1]))
+		}
+		w.w.WriteByte('}')
+	}
+	w.w.WriteByte(' ')
+	w.w.WriteString(formatValue(value))
+	w.w.WriteByte('\n')
+}
+
+// Gauge writes a family with a single unlabeled gauge.
+func (w *Writer) Gauge(name, help string, value float64) {
+	w.Header(name, help, "gauge")
+	w.Sample(name, value)
+}
+
+// Histogram writes a histogram family. buckets are the upper bounds and counts the cumulative
+// counts of observations at most as large.
+func (w *Writer) Histogram(name, help string, buckets []float64, counts []int, count int, sum float64) {
+	w.Header(name, help, "histogram")
+	for i, le := range buckets {
+		w.Sample(name+"_bucket", float64(counts[i]), "le", formatValue(le))
+	}
+	w.Sample(name+"_bucket", float64(count), "le", "+Inf")
+	w.Sample(name+"_sum", sum)
+	w.Sample(name+"_count", float64(count))
+}
+
+// CounterVec is a counter partitioned by labels.
+type CounterVec struct {
+	name, help string
+	labels     []string
+
+	mu     sync.Mutex
+	values map[string]float64 // keyed by the label values joined by labelSep
+}
+
+const labelSep = "\xff"
+
+// NewCounterVec returns a counter with the given label names.
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	return &CounterVec{name: name, help: help, labels: labels, values: make(map[string]float64)}
+}
+
+// Inc increments the counter of the label values, given in the order of the label names.
+func (c *CounterVec) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+// Add adds v to the counter of the label values.
+func (c *CounterVec) Add(v float64, values ...string) {
+	key := strings.Join(values, labelSep)
+	c.mu.Lock()
+	c.values[key] += v
+	c.mu.Unlock()
+}
+
+// Collect writes the counter, ordered by label values.
+func (c *CounterVec) Collect(w *Writer) {
+	c.mu.Lock()
+	keys := make([]string, 0, len(c.values))
+	for key := range c.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	values := make([]float64, len(keys))
+	for i, key := range keys {
+		values[i] = c.values[key]
+	}
+	c.mu.Unlock()
+
+	w.Header(c.name, c.help, "counter")
+	for i, key := range keys {
+		labelValues := strings.Split(key, labelSep)
+		labels := make([]string, 0, 2*len(c.labels))
+		for j, name := range c.labels {
+			value := ""
+			if j < len(labelValues) {
+				value = labelValues[j]
+			}
+			labels = append(labels, name, value)
+		}
+		w.Sample(c.name, values[i], labels...)
+	}
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
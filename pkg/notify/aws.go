@@ -0,0 +1,175 @@
+package notify
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	snstypes "github.com/aws/aws-sdk-go-v2/service/sns/types"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// The "sqs" and "sns" sinks publish every event they are routed as JSON, for serverless
+// consumers. Credentials come from the SDK's default chain, which covers IRSA: the
+// AWS_ROLE_ARN and AWS_WEB_IDENTITY_TOKEN_FILE set by EKS for the service account.
+
+// SQSConfig configures the "sqs" sink.
+type SQSConfig struct {
+	QueueURL string `json:"queueURL"`
+	Region   string `json:"region,omitempty"` // The queue's, from its URL, if unset
+}
+
+// SNSConfig configures the "sns" sink.
+type SNSConfig struct {
+	TopicARN string `json:"topicARN"`
+	Region   string `json:"region,omitempty"` // The topic's, from its ARN, if unset
+}
+
+// SQSNotifier sends events to an SQS queue.
+type SQSNotifier struct {
+	client   *sqs.Client
+	queueURL string
+	fifo     bool
+}
+
+// NewSQSNotifier validates the configuration and returns the sink.
+func NewSQSNotifier(cfg SQSConfig) (*SQSNotifier, error) {
+	u, err := url.Parse(cfg.QueueURL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return nil, fmt.Errorf("invalid queueURL %q", cfg.QueueURL)
+	}
+	region := cfg.Region
+	if region == "" {
+		// https://sqs.<region>.amazonaws.com/<account>/<queue>
+		if parts := strings.Split(u.Host, "."); len(parts) > 2 && parts[0] == "sqs" {
+			region = parts[1]
+		}
+	}
+	awsConfig, err := loadAWSConfig(region)
+	if err != nil {
+		return nil, err
+	}
+	return &SQSNotifier{client: sqs.NewFromConfig(awsConfig), queueURL: cfg.QueueURL,
+		fifo: strings.HasSuffix(u.Path, ".fifo")}, nil
+}
+
+// Name implements Notifier.
+func (s *SQSNotifier) Name() string { return "sqs" }
+
+// Notify implements Notifier.
+func (s *SQSNotifier) Notify(ctx context.Context, ev Event) error {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("encoding event: %w", err)
+	}
+	in := &sqs.SendMessageInput{
+		QueueUrl:          aws.String(s.queueURL),
+		MessageBody:       aws.String(string(body)),
+		MessageAttributes: make(map[string]sqstypes.MessageAttributeValue),
+	}
+	for name, value := range awsAttributes(ev) {
+		in.MessageAttributes[name] = sqstypes.MessageAttributeValue{DataType: aws.String("String"), StringValue: aws.String(value)}
+	}
+	if s.fifo {
+		in.MessageGroupId, in.MessageDeduplicationId = awsFIFOKeys(ev, body)
+	}
+	_, err = s.client.SendMessage(ctx, in)
+	return err
+}
+
+// SNSNotifier publishes events to an SNS topic.
+type SNSNotifier struct {
+	client   *sns.Client
+	topicARN string
+	fifo     bool
+}
+
+// NewSNSNotifier validates the configuration and returns the sink.
+func NewSNSNotifier(cfg SNSConfig) (*SNSNotifier, error) {
+	// arn:<partition>:sns:<region>:<account>:<topic>
+	parts := strings.Split(cfg.TopicARN, ":")
+	if len(parts) != 6 || parts[0] != "arn" || parts[2] != "sns" {
+		return nil, fmt.Errorf("invalid topicARN %q", cfg.TopicARN)
+	}
+	region := cfg.Region
+	if region == "" {
+		region = parts[3]
+	}
+	awsConfig, err := loadAWSConfig(region)
+	if err != nil {
+		return nil, err
+	}
+	return &SNSNotifier{client: sns.NewFromConfig(awsConfig), topicARN: cfg.TopicARN,
+		fifo: strings.HasSuffix(parts[5], ".fifo")}, nil
+}
+
+// Name implements Notifier.
+func (s *SNSNotifier) Name() string { return "sns" }
+
+// Notify implements Notifier.
+func (s *SNSNotifier) Notify(ctx context.Context, ev Event) error {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("encoding event: %w", err)
+	}
+	in := &sns.PublishInput{
+		TopicArn:          aws.String(s.topicARN),
+		Message:           aws.String(string(body)),
+		Subject:           aws.String(truncate(ev.Title(), 97)), // SNS's limit is 100, for email subscriptions
+		MessageAttributes: make(map[string]snstypes.MessageAttributeValue),
+	}
+	for name, value := range awsAttributes(ev) {
+		in.MessageAttributes[name] = snstypes.MessageAttributeValue{DataType: aws.String("String"), StringValue: aws.String(value)}
+	}
+	if s.fifo {
+		in.MessageGroupId, in.MessageDeduplicationId = awsFIFOKeys(ev, body)
+	}
+	_, err = s.client.Publish(ctx, in)
+	return err
+}
+
+// loadAWSConfig loads the default configuration and credential chain. Credentials are only
+// resolved on the first event.
+func loadAWSConfig(region string) (aws.Config, error) {
+	var opts []func(*config.LoadOptions) error
+	if region != "" {
+		opts = append(opts, config.WithRegion(region))
+	}
+	awsConfig, err := config.LoadDefaultConfig(context.Background(), opts...)
+	if err != nil {
+		return aws.Config{}, fmt.Errorf("loading AWS configuration: %w", err)
+	}
+	if awsConfig.Region == "" {
+		return aws.Config{}, fmt.Errorf("region is required")
+	}
+	return awsConfig, nil
+}
+
+// awsAttributes are set on messages so subscriptions and consumers can filter without parsing
+// them, e.g. with an SNS filter policy on type. Empty values are invalid and left out.
+func awsAttributes(ev Event) map[string]string {
+	attrs := make(map[string]string)
+	for name, value := range map[string]string{
+		"type": string(ev.Type), "severity": string(ev.Severity), "namespace": ev.Namespace, "cluster": ev.Cluster,
+	} {
+		if value != "" {
+			attrs[name] = value
+		}
+	}
+	return attrs
+}
+
+// awsFIFOKeys order the events of a workload within FIFO queues and topics, deduplicating retries
+// of the same event.
+func awsFIFOKeys(ev Event, body []byte) (group, dedup *string) {
+	sum := sha256.Sum256(body)
+	return aws.String(truncate(workload(ev), 125)), aws.String(hex.EncodeToString(sum[:]))
+}
@@ -0,0 +1,107 @@
+package notify
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The chat sinks (Slack, Teams) render events alike: a title, the message and the details below.
+
+// detail is one labeled value of an event shown by chat sinks.
+type detail struct {
+	name, value string
+}
+
+// eventDetails returns the event's Pod, owner, action, restart count and reason, omitting those
+// that are unset.
+func eventDetails(ev Event) []detail {
+	var details []detail
+	add := func(name, value string) {
+		if value != "" {
+			details = append(details, detail{name, value})
+		}
+	}
+	if ev.Pod != "" {
+		add("Pod", ev.Namespace+"/"+ev.Pod)
+	}
+	add("Owner", ev.Owner)
+	add("Action", ev.Action)
+	if ev.RestartCount > 0 {
+		add("Restarts", strconv.Itoa(int(ev.RestartCount)))
+	}
+	add("Reason", ev.Reason)
+	return details
+}
+
+// severityColor is the hex color chat services show next to an event of the severity.
+func severityColor(s Severity) string {
+	switch s {
+	case SeverityCritical:
+		return "#d32f2f"
+	case SeverityWarning:
+		return "#f9a825"
+	}
+	return "#1976d2"
+}
+
+// severityEmoji prefixes message titles in chat services.
+func severityEmoji(s Severity) string {
+	switch s {
+	case SeverityCritical:
+		return "🚨"
+	case SeverityWarning:
+		return "⚠️"
+	}
+	return "ℹ️"
+}
+
+// channelMatcher picks the channel of an event: the one set by its route, else the one of its
+// namespace, else the default.
+type channelMatcher struct {
+	fallback   string
+	namespaces map[string]string
+	patterns   []string // Keys of namespaces, most specific first
+}
+
+func newChannelMatcher(fallback string, namespaces map[string]string) (*channelMatcher, error) {
+	patterns := make([]string, 0, len(namespaces))
+	for p := range namespaces {
+		if _, err := filepath.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid namespace glob %q: %w", p, err)
+		}
+		patterns = append(patterns, p)
+	}
+	// Exact names before globs, then the globs with the most literal characters
+	literal := func(p string) int { return len(p) - strings.Count(p, "*") - strings.Count(p, "?") }
+	sort.Slice(patterns, func(i, j int) bool {
+		gi, gj := strings.ContainsAny(patterns[i], "*?["), strings.ContainsAny(patterns[j], "*?[")
+		if gi != gj {
+			return !gi
+		}
+		if li, lj := literal(patterns[i]), literal(patterns[j]); li != lj {
+			return li > lj
+		}
+		return patterns[i] < patterns[j]
+	})
+	return &channelMatcher{fallback: fallback, namespaces: namespaces, patterns: patterns}, nil
+}
+
+func (m *channelMatcher) channel(ev Event) string {
+	if ev.Channel != "" {
+		return ev.Channel
+	}
+	return m.forNamespace(ev.Namespace)
+}
+
+// forNamespace returns the channel of the namespace, ignoring routes.
+func (m *channelMatcher) forNamespace(namespace string) string {
+	for _, p := range m.patterns {
+		if ok, _ := filepath.Match(p, namespace); ok {
+			return m.namespaces[p]
+		}
+	}
+	return m.fallback
+}
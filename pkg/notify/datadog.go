@@ -0,0 +1,166 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// DefaultDatadogSite is the site of accounts in the US1 region.
+const DefaultDatadogSite = "datadoghq.com"
+
+// Metrics submitted to Datadog, as counts.
+const (
+	datadogEventsMetric = "k8s_healer.events" // Every event, tagged with its type
+	datadogHealsMetric  = "k8s_healer.heals"  // Heals, tagged with their result
+)
+
+// DatadogConfig configures the "datadog" sink, which posts every event it is routed to the event
+// stream and counts them as metrics, so healing overlays dashboards and can drive monitors.
+type DatadogConfig struct {
+	APIKey string `json:"apiKey"`
+	// Site is the account's Datadog site, e.g. datadoghq.eu or us5.datadoghq.com.
+	Site string `json:"site,omitempty"`
+	// Tags are added to every event and metric, e.g. env:prod.
+	Tags []string `json:"tags,omitempty"`
+}
+
+// DatadogNotifier posts events and metrics to Datadog. Both are tagged like the Datadog Agent's
+// Kubernetes integration (kube_namespace, kube_deployment, ...), so they line up with its data.
+type DatadogNotifier struct {
+	cfg     DatadogConfig
+	baseURL string
+}
+
+// NewDatadogNotifier validates the configuration and returns the sink.
+func NewDatadogNotifier(cfg DatadogConfig) (*DatadogNotifier, error) {
+	cfg.APIKey = secret(cfg.APIKey)
+	if cfg.APIKey == "" {
+		return nil, fmt.Errorf("apiKey is required")
+	}
+	if cfg.Site == "" {
+		cfg.Site = DefaultDatadogSite
+	}
+	return &DatadogNotifier{cfg: cfg, baseURL: "https://api." + strings.TrimPrefix(cfg.Site, "app.")}, nil
+}
+
+// Name implements Notifier.
+func (d *DatadogNotifier) Name() string { return "datadog" }
+
+// Notify implements Notifier. The event and the metrics are submitted independently; either
+// failing doesn't keep the other from being sent.
+func (d *DatadogNotifier) Notify(ctx context.Context, ev Event) error {
+	headers := map[string]string{"DD-API-KEY": d.cfg.APIKey}
+	tags := d.tags(ev)
+	eventTags := tags[:len(tags):len(tags)]
+	if ev.Pod != "" {
+		// Only on the event: as a metric tag, every Pod would be a new custom metric
+		eventTags = append(eventTags, "pod_name:"+ev.Pod)
+	}
+
+	_, eventErr := postJSON(ctx, d.baseURL+"/api/v1/events", datadogEvent{
+		Title:          truncate(ev.Title(), 97),
+		Text:           ev.Message,
+		AlertType:      datadogAlertType(ev),
+		AggregationKey: truncate(workload(ev), 97),
+		DateHappened:   ev.Time.Unix(),
+		Tags:           eventTags,
+	}, headers)
+	if eventErr != nil {
+		eventErr = fmt.Errorf("posting event: %w", eventErr)
+	}
+
+	count := float64(max(ev.Count, 1))
+	series := []datadogSeries{d.count(datadogEventsMetric, ev, count, tags)}
+	switch ev.Type {
+	case EventHeal:
+		series = append(series, d.count(datadogHealsMetric, ev, count, append(tags[:len(tags):len(tags)], "result:success")))
+	case EventHealFailed:
+		series = append(series, d.count(datadogHealsMetric, ev, count, append(tags[:len(tags):len(tags)], "result:failure")))
+	}
+	_, metricsErr := postJSON(ctx, d.baseURL+"/api/v2/series", map[string][]datadogSeries{"series": series}, headers)
+	if metricsErr != nil {
+		metricsErr = fmt.Errorf("submitting metrics: %w", metricsErr)
+	}
+	return errors.Join(eventErr, metricsErr)
+}
+
+type datadogEvent struct {
+	Title          string   `json:"title"`
+	Text           string   `json:"text"`
+	AlertType      string   `json:"alert_type"`
+	AggregationKey string   `json:"aggregation_key,omitempty"`
+	DateHappened   int64    `json:"date_happened"`
+	Tags           []string `json:"tags,omitempty"`
+}
+
+type datadogSeries struct {
+	Metric string         `json:"metric"`
+	Type   int            `json:"type"` // 1 for counts
+	Points []datadogPoint `json:"points"`
+	Tags   []string       `json:"tags,omitempty"`
+}
+
+type datadogPoint struct {
+	Timestamp int64   `json:"timestamp"`
+	Value     float64 `json:"value"`
+}
+
+func (d *DatadogNotifier) count(metric string, ev Event, value float64, tags []string) datadogSeries {
+	return datadogSeries{
+		Metric: metric,
+		Type:   1,
+		Points: []datadogPoint{{Timestamp: ev.Time.Unix(), Value: value}},
+		Tags:   tags,
+	}
+}
+
+// datadogOwnerTags are the tag keys of the Datadog Agent for the owner kinds.
+var datadogOwnerTags = map[string]string{
+	"Deployment":  "kube_deployment",
+	"StatefulSet": "kube_stateful_set",
+	"DaemonSet":   "kube_daemon_set",
+	"ReplicaSet":  "kube_replica_set",
+	"Job":         "kube_job",
+	"CronJob":     "kube_cronjob",
+}
+
+// tags returns the tags of the event and its metrics: the cluster, namespace and owner, the event
+// type and severity, the check that failed as the reason, and the action.
+func (d *DatadogNotifier) tags(ev Event) []string {
+	tags := append([]string{"source:k8s-healer", "event_type:" + string(ev.Type)}, d.cfg.Tags...)
+	add := func(key, value string) {
+		if value != "" {
+			tags = append(tags, key+":"+value)
+		}
+	}
+	add("kube_cluster_name", ev.Cluster)
+	add("kube_namespace", ev.Namespace)
+	if kind, name, ok := strings.Cut(owner(ev), "/"); ok {
+		if key, known := datadogOwnerTags[kind]; known {
+			add(key, name)
+		} else {
+			add("kube_owner_ref_name", name)
+		}
+	}
+	add("severity", string(ev.Severity))
+	add("reason", ev.Check)
+	add("action", ev.Action)
+	return tags
+}
+
+// datadogAlertType maps the event's severity to the event stream's alert types; recoveries are
+// successes.
+func datadogAlertType(ev Event) string {
+	if ev.Type == EventRecovered {
+		return "success"
+	}
+	switch ev.Severity {
+	case SeverityCritical:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	}
+	return "info"
+}
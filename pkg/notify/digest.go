@@ -0,0 +1,215 @@
+package notify
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Grouping of aggregated events.
+const (
+	AggregateByOwner     = "owner"
+	AggregateByNamespace = "namespace"
+)
+
+// DefaultAggregateTypes are the event types aggregated unless configured otherwise. Escalations
+// stay individual.
+var DefaultAggregateTypes = []EventType{EventHeal, EventHealFailed, EventHealSuppressed}
+
+// AggregationConfig collapses bursts of events into digests: the events of a type for one owner
+// (or namespace) within Window of the first are sent as a single summary when it ends.
+type AggregationConfig struct {
+	Window *metav1.Duration `json:"window"`
+	By     string           `json:"by,omitempty"`    // owner (default) or namespace
+	Types  []EventType      `json:"types,omitempty"` // DefaultAggregateTypes if empty
+}
+
+// digestPodsListed caps the Pods named in a digest's message.
+const digestPodsListed = 10
+
+// aggregator buffers events into digests.
+type aggregator struct {
+	window  time.Duration
+	by      string
+	types   map[EventType]bool
+	deliver func(Event)
+
+	mu      sync.Mutex
+	pending map[string]*digest
+}
+
+// digest is the events of one group within a window.
+type digest struct {
+	events []Event
+	timer  *time.Timer
+}
+
+func newAggregator(cfg AggregationConfig, deliver func(Event)) (*aggregator, error) {
+	if cfg.Window == nil || cfg.Window.Duration <= 0 {
+		return nil, fmt.Errorf("aggregation window must be positive")
+	}
+	a := &aggregator{window: cfg.Window.Duration, by: cfg.By, types: make(map[EventType]bool), deliver: deliver,
+		pending: make(map[string]*digest)}
+	switch a.by {
+	case "":
+		a.by = AggregateByOwner
+	case AggregateByOwner, AggregateByNamespace:
+	default:
+		return nil, fmt.Errorf("invalid aggregation by %q (expected owner or namespace)", cfg.By)
+	}
+	types := cfg.Types
+	if len(types) == 0 {
+		types = DefaultAggregateTypes
+	}
+	for _, typ := range types {
+		a.types[typ] = true
+	}
+	return a, nil
+}
+
+// add buffers the event and reports whether it was, which it is unless its type isn't
+// aggregated. The first event of a group starts its window.
+func (a *aggregator) add(ev Event) bool {
+	if !a.types[ev.Type] {
+		return false
+	}
+	key := strings.Join([]string{string(ev.Type), ev.Namespace, a.group(ev)}, "/")
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if d, ok := a.pending[key]; ok {
+		d.events = append(d.events, ev)
+		return true
+	}
+	a.pending[key] = &digest{
+		events: []Event{ev},
+		timer:  time.AfterFunc(a.window, func() { a.flush(key) }),
+	}
+	return true
+}
+
+// group is the part of the key beyond the type and namespace.
+func (a *aggregator) group(ev Event) string {
+	if a.by == AggregateByNamespace {
+		return ""
+	}
+	if o := owner(ev); o != "" {
+		return o
+	}
+	return ev.Pod // Pods without an owner aren't aggregated
+}
+
+// flush delivers the group's digest.
+func (a *aggregator) flush(key string) {
+	a.mu.Lock()
+	d, ok := a.pending[key]
+	delete(a.pending, key)
+	a.mu.Unlock()
+	if ok {
+		a.deliver(a.summarize(d.events))
+	}
+}
+
+// flushAll delivers every pending digest without waiting for its window to end.
+func (a *aggregator) flushAll() {
+	a.mu.Lock()
+	keys := make([]string, 0, len(a.pending))
+	for key, d := range a.pending {
+		d.timer.Stop()
+		keys = append(keys, key)
+	}
+	a.mu.Unlock()
+	for _, key := range keys {
+		a.flush(key)
+	}
+}
+
+// summarize collapses the events of a group into one. A single event is passed on as is. The
+// digest keeps what its events share, so it is routed like them: the labels common to all, the
+// highest severity, and the owner, check, reason and action if they are the same for all.
+func (a *aggregator) summarize(events []Event) Event {
+	if len(events) == 1 {
+		return events[0]
+	}
+	ev := events[0]
+	ev.Pod, ev.RestartCount, ev.Count = "", 0, len(events)
+	ev.Labels = make(map[string]string, len(events[0].Labels))
+	for k, v := range events[0].Labels {
+		ev.Labels[k] = v
+	}
+
+	pods := make(map[string]bool)
+	for _, e := range events {
+		pods[e.Pod] = true
+		if severityRank(e.Severity) > severityRank(ev.Severity) {
+			ev.Severity = e.Severity
+		}
+		if e.Owner != ev.Owner {
+			ev.Owner = ""
+		}
+		if e.Reason != ev.Reason {
+			ev.Reason = ""
+		}
+		if e.Check != ev.Check {
+			ev.Check = ""
+		}
+		if e.Action != ev.Action {
+			ev.Action = ""
+		}
+		for k, v := range ev.Labels {
+			if e.Labels[k] != v {
+				delete(ev.Labels, k)
+			}
+		}
+	}
+	names := make([]string, 0, len(pods))
+	for pod := range pods {
+		names = append(names, pod)
+	}
+	sort.Strings(names)
+	if len(names) > digestPodsListed {
+		names = append(names[:digestPodsListed], fmt.Sprintf("and %d more", len(pods)-digestPodsListed))
+	}
+
+	target := ev.Owner
+	if target == "" || target == "<none>" {
+		target = "namespace " + ev.Namespace
+	}
+	span := shortDuration(a.window)
+	var what string
+	switch ev.Type {
+	case EventHeal:
+		what = fmt.Sprintf("Healed %d Pods of %s in %s", len(pods), target, span)
+	case EventHealFailed:
+		what = fmt.Sprintf("%d heals of %s failed in %s", len(events), target, span)
+	default:
+		what = fmt.Sprintf("%d %s events for %s in %s", len(events), ev.Type, target, span)
+	}
+	ev.Message = fmt.Sprintf("%s: %s.", what, strings.Join(names, ", "))
+	return ev
+}
+
+// shortDuration formats d without zero trailing units, e.g. 5m rather than 5m0s.
+func shortDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = s[:len(s)-2]
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = s[:len(s)-2]
+	}
+	return s
+}
+
+func severityRank(s Severity) int {
+	switch s {
+	case SeverityCritical:
+		return 2
+	case SeverityWarning:
+		return 1
+	}
+	return 0
+}
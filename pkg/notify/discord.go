@@ -0,0 +1,82 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DiscordConfig configures the "discord" sink, which posts embeds to a Discord webhook.
+type DiscordConfig struct {
+	WebhookURL string `json:"webhookURL"`
+	// Events enables or disables event types (e.g. heal: false). Types not listed are posted.
+	Events map[EventType]bool `json:"events,omitempty"`
+}
+
+// DiscordNotifier posts events to a Discord channel.
+type DiscordNotifier struct {
+	cfg DiscordConfig
+}
+
+// NewDiscordNotifier validates the configuration and returns the sink.
+func NewDiscordNotifier(cfg DiscordConfig) (*DiscordNotifier, error) {
+	cfg.WebhookURL = secret(cfg.WebhookURL)
+	if cfg.WebhookURL == "" {
+		return nil, fmt.Errorf("webhookURL is required")
+	}
+	return &DiscordNotifier{cfg: cfg}, nil
+}
+
+// Name implements Notifier.
+func (d *DiscordNotifier) Name() string { return "discord" }
+
+// Notify implements Notifier. Events of disabled types are dropped.
+func (d *DiscordNotifier) Notify(ctx context.Context, ev Event) error {
+	if enabled, ok := d.cfg.Events[ev.Type]; ok && !enabled {
+		return nil
+	}
+	_, err := postJSON(ctx, d.cfg.WebhookURL, discordMessage(ev), nil)
+	return err
+}
+
+type discordPayload struct {
+	Username string         `json:"username"`
+	Embeds   []discordEmbed `json:"embeds"`
+}
+
+type discordEmbed struct {
+	Title       string         `json:"title"`
+	Description string         `json:"description,omitempty"`
+	Color       int            `json:"color"`
+	Fields      []discordField `json:"fields,omitempty"`
+	Timestamp   string         `json:"timestamp"`
+}
+
+type discordField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+// discordMessage renders the event as an embed colored by severity, with the details as fields.
+func discordMessage(ev Event) discordPayload {
+	var fields []discordField
+	for _, d := range eventDetails(ev) {
+		fields = append(fields, discordField{Name: d.name, Value: d.value, Inline: d.name != "Reason"})
+	}
+	// Discord takes colors as integers
+	color, _ := strconv.ParseInt(strings.TrimPrefix(severityColor(ev.Severity), "#"), 16, 32)
+
+	return discordPayload{
+		Username: "k8s-healer",
+		Embeds: []discordEmbed{{
+			Title:       fmt.Sprintf("%s %s", severityEmoji(ev.Severity), ev.Title()),
+			Description: ev.Message,
+			Color:       int(color),
+			Fields:      fields,
+			Timestamp:   ev.Time.UTC().Format(time.RFC3339),
+		}},
+	}
+}
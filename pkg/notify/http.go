@@ -0,0 +1,67 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// httpClient delivers the notifications of the HTTP-based sinks. The router bounds each delivery
+// with its own timeout; this one guards against sinks used without it.
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// statusError is a response other than 2xx. It carries the start of the body, which is where the
+// services explain what was wrong.
+type statusError struct {
+	code int
+	msg  string
+}
+
+func (e *statusError) Error() string { return e.msg }
+
+// postJSON posts payload as JSON to url and returns the response body.
+func postJSON(ctx context.Context, url string, payload interface{}, headers map[string]string) ([]byte, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("encoding payload: %w", err)
+	}
+	return send(ctx, http.MethodPost, url, "application/json", body, headers)
+}
+
+// send makes the request and returns the response body. Responses other than 2xx are
+// *statusError.
+func send(ctx context.Context, method, url, contentType string, body []byte, headers map[string]string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return respBody, &statusError{code: resp.StatusCode, msg: fmt.Sprintf("%s: %s", resp.Status, truncate(string(respBody), 200))}
+	}
+	return respBody, nil
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
@@ -0,0 +1,104 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+)
+
+// KafkaConfig configures the "kafka" sink, which publishes every event it is routed as JSON to a
+// topic, for event buses consuming the activity of many clusters.
+type KafkaConfig struct {
+	Brokers []string `json:"brokers"`
+	Topic   string   `json:"topic"`
+	// SASL authenticates to the brokers.
+	SASL *KafkaSASLConfig `json:"sasl,omitempty"`
+	// TLS encrypts the connections; unset for plaintext.
+	TLS *TLSConfig `json:"tls,omitempty"`
+}
+
+// KafkaSASLConfig configures SASL authentication.
+type KafkaSASLConfig struct {
+	Mechanism string `json:"mechanism"` // PLAIN, SCRAM-SHA-256 or SCRAM-SHA-512
+	Username  string `json:"username"`
+	Password  string `json:"password"`
+}
+
+// KafkaNotifier publishes events to a Kafka topic. Messages are keyed by workload, so the events
+// of one workload stay in order on one partition, and carry the event type as a header.
+type KafkaNotifier struct {
+	writer *kafka.Writer
+}
+
+// NewKafkaNotifier validates the configuration and returns the sink. Brokers are connected to on
+// the first event.
+func NewKafkaNotifier(cfg KafkaConfig) (*KafkaNotifier, error) {
+	if len(cfg.Brokers) == 0 || cfg.Topic == "" {
+		return nil, fmt.Errorf("brokers and topic are required")
+	}
+	transport := &kafka.Transport{ClientID: "k8s-healer"}
+	if cfg.SASL != nil {
+		mechanism, err := kafkaSASL(*cfg.SASL)
+		if err != nil {
+			return nil, err
+		}
+		transport.SASL = mechanism
+	}
+	if cfg.TLS != nil {
+		tlsConfig, err := cfg.TLS.build()
+		if err != nil {
+			return nil, fmt.Errorf("invalid tls: %w", err)
+		}
+		transport.TLS = tlsConfig
+	}
+	return &KafkaNotifier{writer: &kafka.Writer{
+		Addr:         kafka.TCP(cfg.Brokers...),
+		Topic:        cfg.Topic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+		BatchTimeout: 10 * time.Millisecond, // Events are few; don't hold them for a batch
+		Transport:    transport,
+	}}, nil
+}
+
+func kafkaSASL(cfg KafkaSASLConfig) (sasl.Mechanism, error) {
+	username, password := secret(cfg.Username), secret(cfg.Password)
+	switch strings.ToUpper(cfg.Mechanism) {
+	case "PLAIN":
+		return plain.Mechanism{Username: username, Password: password}, nil
+	case "SCRAM-SHA-256":
+		return scram.Mechanism(scram.SHA256, username, password)
+	case "SCRAM-SHA-512":
+		return scram.Mechanism(scram.SHA512, username, password)
+	}
+	return nil, fmt.Errorf("invalid sasl mechanism %q (expected PLAIN, SCRAM-SHA-256 or SCRAM-SHA-512)", cfg.Mechanism)
+}
+
+// Name implements Notifier.
+func (k *KafkaNotifier) Name() string { return "kafka" }
+
+// Notify implements Notifier.
+func (k *KafkaNotifier) Notify(ctx context.Context, ev Event) error {
+	value, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("encoding event: %w", err)
+	}
+	return k.writer.WriteMessages(ctx, kafka.Message{
+		Key:     []byte(workload(ev)),
+		Value:   value,
+		Headers: []kafka.Header{{Key: "type", Value: []byte(ev.Type)}},
+		Time:    ev.Time,
+	})
+}
+
+// Close flushes and closes the connections to the brokers.
+func (k *KafkaNotifier) Close() error {
+	return k.writer.Close()
+}
@@ -0,0 +1,168 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// DefaultNATSSubject publishes the events of each namespace on a subject of its own.
+const DefaultNATSSubject = "k8s-healer.events.{{ .Namespace }}"
+
+// NATSConfig configures the "nats" sink, which publishes every event it is routed as JSON on a
+// subject rendered from the event.
+type NATSConfig struct {
+	Servers []string `json:"servers"` // e.g. nats://nats:4222
+	// Subject is a Go text/template over the Event, DefaultNATSSubject if empty.
+	Subject string `json:"subject,omitempty"`
+
+	// JetStream publishes with acknowledgements, so events are only delivered once a stream
+	// capturing the subject persisted them.
+	JetStream bool `json:"jetstream,omitempty"`
+	// Stream, with JetStream, is the stream expected to capture the subjects; events captured by
+	// another one or none are rejected.
+	Stream string `json:"stream,omitempty"`
+
+	// CredsFile authenticates with a user JWT and NKey seed; Token or Username and Password
+	// otherwise.
+	CredsFile string `json:"credsFile,omitempty"`
+	Token     string `json:"token,omitempty"`
+	Username  string `json:"username,omitempty"`
+	Password  string `json:"password,omitempty"`
+	// TLS encrypts the connection, verifying the server as configured.
+	TLS *TLSConfig `json:"tls,omitempty"`
+}
+
+// NATSNotifier publishes events to NATS.
+type NATSNotifier struct {
+	conn    *nats.Conn
+	js      jetstream.JetStream // nil unless publishing to JetStream
+	stream  string
+	subject *template.Template
+}
+
+// NewNATSNotifier validates the configuration and connects. A server that can't be reached isn't
+// an error: the connection is retried in the background, and events published meanwhile are
+// buffered.
+func NewNATSNotifier(cfg NATSConfig) (*NATSNotifier, error) {
+	if len(cfg.Servers) == 0 {
+		return nil, fmt.Errorf("servers is required")
+	}
+	if cfg.Subject == "" {
+		cfg.Subject = DefaultNATSSubject
+	}
+	subject, err := template.New("subject").Option("missingkey=error").Parse(cfg.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subject: %w", err)
+	}
+	n := &NATSNotifier{subject: subject, stream: cfg.Stream}
+	if _, err := n.render(Event{Type: EventHeal, Namespace: "default", Pod: "example", Time: time.Now()}); err != nil {
+		return nil, err
+	}
+	if cfg.Stream != "" && !cfg.JetStream {
+		return nil, fmt.Errorf("stream requires jetstream")
+	}
+
+	opts := []nats.Option{
+		nats.Name("k8s-healer"),
+		nats.RetryOnFailedConnect(true),
+		nats.MaxReconnects(-1),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			slog.Warn("Disconnected from NATS", "err", err)
+		}),
+		nats.ReconnectHandler(func(nc *nats.Conn) {
+			slog.Info("Reconnected to NATS", "server", nc.ConnectedUrlRedacted())
+		}),
+	}
+	switch {
+	case cfg.CredsFile != "":
+		opts = append(opts, nats.UserCredentials(cfg.CredsFile))
+	case cfg.Token != "":
+		opts = append(opts, nats.Token(secret(cfg.Token)))
+	case cfg.Username != "":
+		opts = append(opts, nats.UserInfo(secret(cfg.Username), secret(cfg.Password)))
+	}
+	if cfg.TLS != nil {
+		tlsConfig, err := cfg.TLS.build()
+		if err != nil {
+			return nil, fmt.Errorf("invalid tls: %w", err)
+		}
+		opts = append(opts, nats.Secure(tlsConfig))
+	}
+	if n.conn, err = nats.Connect(strings.Join(cfg.Servers, ","), opts...); err != nil {
+		return nil, fmt.Errorf("connecting to NATS: %w", err)
+	}
+	if cfg.JetStream {
+		if n.js, err = jetstream.New(n.conn); err != nil {
+			n.conn.Close()
+			return nil, fmt.Errorf("creating JetStream context: %w", err)
+		}
+	}
+	return n, nil
+}
+
+// Name implements Notifier.
+func (n *NATSNotifier) Name() string { return "nats" }
+
+// Notify implements Notifier.
+func (n *NATSNotifier) Notify(ctx context.Context, ev Event) error {
+	subject, err := n.render(ev)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("encoding event: %w", err)
+	}
+	// No headers: they can't be published while disconnected, before the server's support is known
+	msg := &nats.Msg{Subject: subject, Data: data}
+
+	if n.js != nil {
+		var opts []jetstream.PublishOpt
+		if n.stream != "" {
+			opts = append(opts, jetstream.WithExpectStream(n.stream))
+		}
+		_, err = n.js.PublishMsg(ctx, msg, opts...)
+		return err
+	}
+	if err := n.conn.PublishMsg(msg); err != nil {
+		return err
+	}
+	// Core NATS doesn't acknowledge messages; a round trip at least confirms the server has it
+	return n.conn.FlushWithContext(ctx)
+}
+
+// render returns the event's subject. Values that would split or wildcard it are rejected.
+func (n *NATSNotifier) render(ev Event) (string, error) {
+	var buf bytes.Buffer
+	if err := n.subject.Execute(&buf, ev); err != nil {
+		return "", fmt.Errorf("rendering subject: %w", err)
+	}
+	subject := buf.String()
+	for _, token := range strings.Split(subject, ".") {
+		if token == "" || strings.ContainsAny(token, " \t\r\n*>") {
+			return "", fmt.Errorf("invalid subject %q", subject)
+		}
+	}
+	return subject, nil
+}
+
+// Close flushes the buffered events and closes the connection.
+func (n *NATSNotifier) Close() error {
+	defer n.conn.Close()
+	if !n.conn.IsConnected() {
+		if buffered, _ := n.conn.Buffered(); buffered > 0 {
+			return fmt.Errorf("not connected; %d buffered bytes of events dropped", buffered)
+		}
+		return nil
+	}
+	return n.conn.FlushTimeout(5 * time.Second)
+}
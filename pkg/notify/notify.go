@@ -0,0 +1,99 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Severity ranks how urgently a human should look at an event.
+type Severity string
+
+const (
+	SeverityInfo     Severity = "info"
+	SeverityWarning  Severity = "warning"
+	SeverityCritical Severity = "critical"
+)
+
+// EventType identifies what happened.
+type EventType string
+
+const (
+	EventHeal       EventType = "heal"        // A healing action was performed
+	EventHealFailed EventType = "heal-failed" // A healing action was attempted but failed
+
+	EventHealSuppressed EventType = "heal-suppressed" // A Pod needs healing but policy made it notify-only
+
+	EventHealUnrecovered EventType = "heal-unrecovered" // A heal's replacement didn't become Ready in time
+	EventQuarantined     EventType = "quarantined"      // A flapping workload is no longer healed
+	EventRecovered       EventType = "recovered"        // A healed or escalated workload has a Ready Pod created since; resolves incidents
+)
+
+// Event is the notification payload shared by all sinks.
+type Event struct {
+	Type         EventType         `json:"type"`
+	Severity     Severity          `json:"severity"`
+	Cluster      string            `json:"cluster,omitempty"`
+	Namespace    string            `json:"namespace"`
+	Pod          string            `json:"pod,omitempty"`
+	Owner        string            `json:"owner,omitempty"`
+	Reason       string            `json:"reason,omitempty"`
+	Check        string            `json:"check,omitempty"` // The check that found the Pod unhealthy, e.g. crashloop
+	RestartCount int32             `json:"restartCount,omitempty"`
+	Action       string            `json:"action,omitempty"`
+	Message      string            `json:"message,omitempty"`
+	Labels       map[string]string `json:"labels,omitempty"`
+	Time         time.Time         `json:"time"`
+
+	// Channel is the destination selected by the routing rule (e.g. a Slack channel). Sinks
+	// without a notion of channels ignore it.
+	Channel string `json:"channel,omitempty"`
+
+	// Count is the number of events a digest summarizes; zero for single events. Digests have no
+	// Pod, and their Message names the Pods.
+	Count int `json:"count,omitempty"`
+}
+
+// Title returns a one-line summary of the event suitable for message headers.
+func (e Event) Title() string {
+	target := e.Namespace
+	if target == "" {
+		target = "all namespaces"
+	}
+	if e.Pod != "" {
+		target = fmt.Sprintf("%s/%s", e.Namespace, e.Pod)
+	} else if e.Owner != "" && e.Owner != "<none>" {
+		target = fmt.Sprintf("%s/%s", e.Namespace, e.Owner)
+	}
+	if e.Cluster != "" {
+		target = fmt.Sprintf("[%s] %s", e.Cluster, target)
+	}
+	if e.Count > 1 {
+		return fmt.Sprintf("k8s-healer %s (%d events): %s", e.Type, e.Count, target)
+	}
+	return fmt.Sprintf("k8s-healer %s: %s", e.Type, target)
+}
+
+// Notifier delivers events to one destination.
+type Notifier interface {
+	// Name identifies the sink in routing rules (e.g. "slack", "pagerduty").
+	Name() string
+	Notify(ctx context.Context, ev Event) error
+}
+
+// LogNotifier writes events to the default logger. It is always registered under the name "log".
+type LogNotifier struct{}
+
+// Name implements Notifier.
+func (LogNotifier) Name() string { return "log" }
+
+// Notify implements Notifier.
+func (LogNotifier) Notify(_ context.Context, ev Event) error {
+	attrs := []any{"severity", string(ev.Severity), "title", ev.Title(), "reason", ev.Reason}
+	if ev.Channel != "" {
+		attrs = append(attrs, "channel", ev.Channel)
+	}
+	slog.Info("Notification", attrs...)
+	return nil
+}
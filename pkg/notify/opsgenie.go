@@ -0,0 +1,149 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// opsgenieAlertsURL is the Alert API endpoint of US accounts.
+const opsgenieAlertsURL = "https://api.opsgenie.com/v2/alerts"
+
+// OpsgenieConfig configures the "opsgenie" sink, which creates and closes alerts through the Alert
+// API.
+type OpsgenieConfig struct {
+	APIKey string `json:"apiKey"` // Key of an API integration
+	// URL overrides the Alert API endpoint, e.g. https://api.eu.opsgenie.com/v2/alerts.
+	URL string `json:"url,omitempty"`
+
+	// Team is the responder of namespaces without a team of their own.
+	Team string `json:"team,omitempty"`
+	// NamespaceTeams maps namespace globs to responder teams.
+	NamespaceTeams map[string]string `json:"namespaceTeams,omitempty"`
+
+	// Priorities maps event types to alert priorities (P1 to P5), over DefaultOpsgeniePriorities.
+	// An empty priority drops the type.
+	Priorities map[EventType]string `json:"priorities,omitempty"`
+}
+
+// DefaultOpsgeniePriorities rank chronic failures, heals that didn't recover the workload and
+// quarantines, above failed heals and those above single heals. Other event types are dropped
+// unless given a priority.
+var DefaultOpsgeniePriorities = map[EventType]string{
+	EventHeal:            "P5",
+	EventHealFailed:      "P3",
+	EventHealUnrecovered: "P2",
+	EventQuarantined:     "P2",
+}
+
+// OpsgenieNotifier creates an alert per workload and priority, and closes them once the workload
+// recovers. Opsgenie deduplicates alerts by alias without raising their priority, so an
+// escalation opens an alert of its own.
+type OpsgenieNotifier struct {
+	cfg        OpsgenieConfig
+	teams      *channelMatcher
+	priorities map[EventType]string
+}
+
+// NewOpsgenieNotifier validates the configuration and returns the sink.
+func NewOpsgenieNotifier(cfg OpsgenieConfig) (*OpsgenieNotifier, error) {
+	cfg.APIKey = secret(cfg.APIKey)
+	if cfg.APIKey == "" {
+		return nil, fmt.Errorf("apiKey is required")
+	}
+	if cfg.URL == "" {
+		cfg.URL = opsgenieAlertsURL
+	}
+	priorities := make(map[EventType]string, len(DefaultOpsgeniePriorities))
+	for typ, p := range DefaultOpsgeniePriorities {
+		priorities[typ] = p
+	}
+	for typ, p := range cfg.Priorities {
+		switch p {
+		case "":
+			delete(priorities, typ)
+		case "P1", "P2", "P3", "P4", "P5":
+			priorities[typ] = p
+		default:
+			return nil, fmt.Errorf("invalid priority %q for %s (expected P1 to P5)", p, typ)
+		}
+	}
+	teams, err := newChannelMatcher(cfg.Team, cfg.NamespaceTeams)
+	if err != nil {
+		return nil, err
+	}
+	return &OpsgenieNotifier{cfg: cfg, teams: teams, priorities: priorities}, nil
+}
+
+// Name implements Notifier.
+func (o *OpsgenieNotifier) Name() string { return "opsgenie" }
+
+// Notify implements Notifier.
+func (o *OpsgenieNotifier) Notify(ctx context.Context, ev Event) error {
+	auth := map[string]string{"Authorization": "GenieKey " + o.cfg.APIKey}
+	if ev.Type == EventRecovered {
+		return o.close(ctx, ev, auth)
+	}
+	priority, ok := o.priorities[ev.Type]
+	if !ok {
+		return nil
+	}
+
+	alert := opsgenieAlert{
+		Message:     truncate(ev.Title(), 127), // Opsgenie's limit is 130
+		Alias:       alertKey(ev) + "/" + priority,
+		Description: ev.Message,
+		Entity:      workload(ev),
+		Source:      "k8s-healer",
+		Priority:    priority,
+		Tags:        []string{"k8s-healer", string(ev.Type)},
+		Details:     map[string]string{"namespace": ev.Namespace},
+	}
+	if ev.Cluster != "" {
+		alert.Details["cluster"] = ev.Cluster
+	}
+	for _, d := range eventDetails(ev) {
+		alert.Details[strings.ToLower(d.name)] = d.value
+	}
+	if team := o.teams.forNamespace(ev.Namespace); team != "" {
+		alert.Responders = []opsgenieResponder{{Name: team, Type: "team"}}
+	}
+	_, err := postJSON(ctx, o.cfg.URL, alert, auth)
+	return err
+}
+
+// close closes the workload's alerts of every priority. Opsgenie accepts requests to close alerts
+// that don't exist.
+func (o *OpsgenieNotifier) close(ctx context.Context, ev Event, auth map[string]string) error {
+	closed := make(map[string]bool)
+	for _, priority := range o.priorities {
+		if closed[priority] {
+			continue
+		}
+		closed[priority] = true
+		alias := alertKey(ev) + "/" + priority
+		endpoint := strings.TrimSuffix(o.cfg.URL, "/") + "/" + url.PathEscape(alias) + "/close?identifierType=alias"
+		if _, err := postJSON(ctx, endpoint, map[string]string{"source": "k8s-healer", "note": ev.Message}, auth); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type opsgenieAlert struct {
+	Message     string              `json:"message"`
+	Alias       string              `json:"alias"`
+	Description string              `json:"description,omitempty"`
+	Responders  []opsgenieResponder `json:"responders,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Details     map[string]string   `json:"details,omitempty"`
+	Entity      string              `json:"entity,omitempty"`
+	Source      string              `json:"source"`
+	Priority    string              `json:"priority"`
+}
+
+type opsgenieResponder struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
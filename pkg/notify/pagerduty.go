@@ -0,0 +1,102 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// pagerDutyEventsURL is the Events API v2 endpoint.
+const pagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// PagerDutyConfig configures the "pagerduty" sink, which opens and resolves alerts through the
+// Events API v2.
+type PagerDutyConfig struct {
+	RoutingKey string `json:"routingKey"` // Integration key of the service's Events API v2 integration
+	// URL overrides the Events API endpoint, e.g. https://events.eu.pagerduty.com/v2/enqueue.
+	URL string `json:"url,omitempty"`
+}
+
+// pagerDutySeverities are the alert severities of the event types that trigger alerts. Failed
+// verifications and quarantines page; heals are recorded at a low severity.
+var pagerDutySeverities = map[EventType]string{
+	EventHeal:            "info",
+	EventHealFailed:      "error",
+	EventHealUnrecovered: "critical",
+	EventQuarantined:     "critical",
+}
+
+// PagerDutyNotifier triggers an alert per workload, updated by each of its heals and escalations,
+// and resolves it once the workload recovers. Other event types are dropped.
+type PagerDutyNotifier struct {
+	cfg PagerDutyConfig
+}
+
+// NewPagerDutyNotifier validates the configuration and returns the sink.
+func NewPagerDutyNotifier(cfg PagerDutyConfig) (*PagerDutyNotifier, error) {
+	cfg.RoutingKey = secret(cfg.RoutingKey)
+	if cfg.RoutingKey == "" {
+		return nil, fmt.Errorf("routingKey is required")
+	}
+	if cfg.URL == "" {
+		cfg.URL = pagerDutyEventsURL
+	}
+	return &PagerDutyNotifier{cfg: cfg}, nil
+}
+
+// Name implements Notifier.
+func (p *PagerDutyNotifier) Name() string { return "pagerduty" }
+
+// Notify implements Notifier.
+func (p *PagerDutyNotifier) Notify(ctx context.Context, ev Event) error {
+	msg := pagerDutyEvent{RoutingKey: p.cfg.RoutingKey, DedupKey: alertKey(ev)}
+	if ev.Type == EventRecovered {
+		msg.EventAction = "resolve"
+	} else if severity, ok := pagerDutySeverities[ev.Type]; ok {
+		msg.EventAction = "trigger"
+		msg.Payload = pagerDutyPayloadOf(ev, severity)
+	} else {
+		return nil
+	}
+	_, err := postJSON(ctx, p.cfg.URL, msg, nil)
+	return err
+}
+
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string            `json:"summary"`
+	Source        string            `json:"source"`
+	Severity      string            `json:"severity"`
+	Timestamp     string            `json:"timestamp"`
+	Component     string            `json:"component,omitempty"`
+	Group         string            `json:"group,omitempty"`
+	Class         string            `json:"class,omitempty"`
+	CustomDetails map[string]string `json:"custom_details,omitempty"`
+}
+
+func pagerDutyPayloadOf(ev Event, severity string) *pagerDutyPayload {
+	details := map[string]string{"event": string(ev.Type)}
+	for _, d := range eventDetails(ev) {
+		details[strings.ToLower(d.name)] = d.value
+	}
+	if ev.Message != "" {
+		details["message"] = ev.Message
+	}
+	return &pagerDutyPayload{
+		Summary:       truncate(ev.Title()+": "+ev.Message, 1021), // PagerDuty's limit is 1024,
+		Source:        workload(ev),
+		Severity:      severity,
+		Timestamp:     ev.Time.UTC().Format(time.RFC3339),
+		Component:     owner(ev),
+		Group:         ev.Namespace,
+		Class:         ev.Reason,
+		CustomDetails: details,
+	}
+}
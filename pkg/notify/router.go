@@ -0,0 +1,235 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Route maps events to sinks. All non-empty matchers must match for the route to apply.
+type Route struct {
+	Clusters   []string          `json:"clusters,omitempty"`   // Cluster name globs
+	Namespaces []string          `json:"namespaces,omitempty"` // Namespace globs
+	Labels     map[string]string `json:"labels,omitempty"`     // Pod labels that must all be present
+	Severities []Severity        `json:"severities,omitempty"`
+	Sinks      []string          `json:"sinks"`
+	Channel    string            `json:"channel,omitempty"` // Optional channel override passed to the sinks
+
+	// Continue keeps evaluating the following routes after this one matched.
+	Continue bool `json:"continue,omitempty"`
+}
+
+// RoutingConfig is the on-disk routing configuration.
+type RoutingConfig struct {
+	// Sinks configures the sinks the routes deliver to.
+	Sinks SinkConfig `json:"sinks,omitempty"`
+
+	Routes []Route `json:"routes"`
+
+	// Default lists the sinks used when no route matches. When both Routes and Default are
+	// empty, every event is broadcast to every sink.
+	Default []string `json:"default,omitempty"`
+
+	// Aggregate collapses bursts of events into digests before they are routed.
+	Aggregate *AggregationConfig `json:"aggregate,omitempty"`
+}
+
+// LoadRoutingConfig reads a YAML routing configuration from path.
+func LoadRoutingConfig(path string) (*RoutingConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read routing config: %w", err)
+	}
+	cfg := &RoutingConfig{}
+	if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse routing config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// matches reports whether the route applies to the event.
+func (r *Route) matches(ev Event) bool {
+	if len(r.Clusters) > 0 && !matchesAnyGlob(r.Clusters, ev.Cluster) {
+		return false
+	}
+	if len(r.Namespaces) > 0 && !matchesAnyGlob(r.Namespaces, ev.Namespace) {
+		return false
+	}
+	for k, v := range r.Labels {
+		if ev.Labels[k] != v {
+			return false
+		}
+	}
+	if len(r.Severities) > 0 {
+		found := false
+		for _, s := range r.Severities {
+			if s == ev.Severity {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesAnyGlob(patterns []string, value string) bool {
+	for _, p := range patterns {
+		if ok, _ := filepath.Match(p, value); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// deliveryTimeout bounds the delivery of an event to a sink.
+const deliveryTimeout = 10 * time.Second
+
+// timeoutSink is implemented by sinks needing more than deliveryTimeout, e.g. to retry.
+type timeoutSink interface {
+	Timeout() time.Duration
+}
+
+// Router dispatches events to the sinks selected by the routing rules.
+type Router struct {
+	config     RoutingConfig
+	sinks      map[string]Notifier
+	order      []string    // registration order, used for broadcasts
+	aggregator *aggregator // nil unless events are aggregated
+}
+
+// NewRouter creates a router over the given sinks. A nil config broadcasts to every sink.
+func NewRouter(config *RoutingConfig, sinks ...Notifier) (*Router, error) {
+	r := &Router{sinks: make(map[string]Notifier)}
+	if config != nil {
+		r.config = *config
+	}
+	for _, s := range sinks {
+		r.sinks[s.Name()] = s
+		r.order = append(r.order, s.Name())
+	}
+
+	// Reject routes that reference sinks which were never configured
+	for i, route := range r.config.Routes {
+		for _, name := range route.Sinks {
+			if _, ok := r.sinks[name]; !ok {
+				return nil, fmt.Errorf("route %d references unknown sink %q", i, name)
+			}
+		}
+	}
+	for _, name := range r.config.Default {
+		if _, ok := r.sinks[name]; !ok {
+			return nil, fmt.Errorf("default route references unknown sink %q", name)
+		}
+	}
+	if r.config.Aggregate != nil {
+		var err error
+		if r.aggregator, err = newAggregator(*r.config.Aggregate, r.deliver); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// target is one sink delivery selected by routing.
+type target struct {
+	sink    string
+	channel string
+}
+
+// resolve returns the deliveries for the event, de-duplicated by sink and channel.
+func (r *Router) resolve(ev Event) []target {
+	var targets []target
+	seen := make(map[target]bool)
+	add := func(sinks []string, channel string) {
+		for _, name := range sinks {
+			t := target{sink: name, channel: channel}
+			if !seen[t] {
+				seen[t] = true
+				targets = append(targets, t)
+			}
+		}
+	}
+
+	for i := range r.config.Routes {
+		route := &r.config.Routes[i]
+		if !route.matches(ev) {
+			continue
+		}
+		add(route.Sinks, route.Channel)
+		if !route.Continue {
+			break
+		}
+	}
+
+	if len(targets) == 0 {
+		if len(r.config.Routes) == 0 && len(r.config.Default) == 0 {
+			add(r.order, "")
+		} else {
+			add(r.config.Default, "")
+		}
+	}
+	return targets
+}
+
+// Dispatch delivers the event to every routed sink, or adds it to a digest delivered at the end of
+// the aggregation window. Delivery failures are logged, never returned, so notifications can't
+// interfere with healing.
+func (r *Router) Dispatch(ev Event) {
+	if r == nil {
+		return
+	}
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	if r.aggregator != nil && r.aggregator.add(ev) {
+		return
+	}
+	r.deliver(ev)
+}
+
+// Close delivers the pending digests and closes the sinks holding connections, before exiting.
+func (r *Router) Close() {
+	if r == nil {
+		return
+	}
+	if r.aggregator != nil {
+		r.aggregator.flushAll()
+	}
+	for _, name := range r.order {
+		if c, ok := r.sinks[name].(io.Closer); ok {
+			if err := c.Close(); err != nil {
+				slog.Warn("Failed to close notification sink", "sink", name, "err", err)
+			}
+		}
+	}
+}
+
+// deliver sends the event to the sinks its routes select.
+func (r *Router) deliver(ev Event) {
+	for _, t := range r.resolve(ev) {
+		routed := ev
+		if t.channel != "" {
+			routed.Channel = t.channel
+		}
+
+		sink := r.sinks[t.sink]
+		timeout := deliveryTimeout
+		if s, ok := sink.(timeoutSink); ok {
+			timeout = s.Timeout()
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		if err := sink.Notify(ctx, routed); err != nil {
+			slog.Warn("Failed to deliver notification", "type", string(ev.Type), "sink", t.sink, "err", err)
+		}
+		cancel()
+	}
+}
@@ -0,0 +1,149 @@
+package notify
+
+import (
+	"fmt"
+	"os"
+)
+
+// SinkConfig configures the notification sinks besides "log", which is always registered. Secrets
+// may reference environment variables ($NAME or ${NAME}), so they can come from a Kubernetes Secret.
+type SinkConfig struct {
+	Slack     *SlackConfig     `json:"slack,omitempty"`
+	Teams     *TeamsConfig     `json:"teams,omitempty"`
+	Discord   *DiscordConfig   `json:"discord,omitempty"`
+	PagerDuty *PagerDutyConfig `json:"pagerduty,omitempty"`
+	Opsgenie  *OpsgenieConfig  `json:"opsgenie,omitempty"`
+	Kafka     *KafkaConfig     `json:"kafka,omitempty"`
+	NATS      *NATSConfig      `json:"nats,omitempty"`
+	SQS       *SQSConfig       `json:"sqs,omitempty"`
+	SNS       *SNSConfig       `json:"sns,omitempty"`
+	Datadog   *DatadogConfig   `json:"datadog,omitempty"`
+
+	// Webhooks are generic sinks, each named in the routes by its own name.
+	Webhooks []WebhookConfig `json:"webhooks,omitempty"`
+}
+
+// BuildSinks returns the log sink and the sinks configured in cfg, which may be nil.
+func BuildSinks(cfg *RoutingConfig) ([]Notifier, error) {
+	sinks := []Notifier{LogNotifier{}}
+	if cfg == nil {
+		return sinks, nil
+	}
+	if cfg.Sinks.Slack != nil {
+		slack, err := NewSlackNotifier(*cfg.Sinks.Slack)
+		if err != nil {
+			return nil, fmt.Errorf("invalid slack sink: %w", err)
+		}
+		sinks = append(sinks, slack)
+	}
+	if cfg.Sinks.Teams != nil {
+		teams, err := NewTeamsNotifier(*cfg.Sinks.Teams)
+		if err != nil {
+			return nil, fmt.Errorf("invalid teams sink: %w", err)
+		}
+		sinks = append(sinks, teams)
+	}
+	if cfg.Sinks.Discord != nil {
+		discord, err := NewDiscordNotifier(*cfg.Sinks.Discord)
+		if err != nil {
+			return nil, fmt.Errorf("invalid discord sink: %w", err)
+		}
+		sinks = append(sinks, discord)
+	}
+	if cfg.Sinks.PagerDuty != nil {
+		pagerDuty, err := NewPagerDutyNotifier(*cfg.Sinks.PagerDuty)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pagerduty sink: %w", err)
+		}
+		sinks = append(sinks, pagerDuty)
+	}
+	if cfg.Sinks.Opsgenie != nil {
+		opsgenie, err := NewOpsgenieNotifier(*cfg.Sinks.Opsgenie)
+		if err != nil {
+			return nil, fmt.Errorf("invalid opsgenie sink: %w", err)
+		}
+		sinks = append(sinks, opsgenie)
+	}
+	if cfg.Sinks.Kafka != nil {
+		kafka, err := NewKafkaNotifier(*cfg.Sinks.Kafka)
+		if err != nil {
+			return nil, fmt.Errorf("invalid kafka sink: %w", err)
+		}
+		sinks = append(sinks, kafka)
+	}
+	if cfg.Sinks.NATS != nil {
+		nats, err := NewNATSNotifier(*cfg.Sinks.NATS)
+		if err != nil {
+			return nil, fmt.Errorf("invalid nats sink: %w", err)
+		}
+		sinks = append(sinks, nats)
+	}
+	if cfg.Sinks.SQS != nil {
+		sqs, err := NewSQSNotifier(*cfg.Sinks.SQS)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sqs sink: %w", err)
+		}
+		sinks = append(sinks, sqs)
+	}
+	if cfg.Sinks.SNS != nil {
+		sns, err := NewSNSNotifier(*cfg.Sinks.SNS)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sns sink: %w", err)
+		}
+		sinks = append(sinks, sns)
+	}
+	if cfg.Sinks.Datadog != nil {
+		datadog, err := NewDatadogNotifier(*cfg.Sinks.Datadog)
+		if err != nil {
+			return nil, fmt.Errorf("invalid datadog sink: %w", err)
+		}
+		sinks = append(sinks, datadog)
+	}
+	for i, c := range cfg.Sinks.Webhooks {
+		webhook, err := NewWebhookNotifier(c)
+		if err != nil {
+			return nil, fmt.Errorf("invalid webhook sink %d: %w", i, err)
+		}
+		sinks = append(sinks, webhook)
+	}
+
+	names := make(map[string]bool, len(sinks))
+	for _, s := range sinks {
+		if names[s.Name()] {
+			return nil, fmt.Errorf("duplicate sink name %q", s.Name())
+		}
+		names[s.Name()] = true
+	}
+	return sinks, nil
+}
+
+// secret expands the environment variables referenced by a configured secret.
+func secret(value string) string {
+	return os.ExpandEnv(value)
+}
+
+// owner returns the event's owner, if the Pod has one.
+func owner(ev Event) string {
+	if ev.Owner == "<none>" {
+		return ""
+	}
+	return ev.Owner
+}
+
+// workload identifies the event's workload as cluster/namespace/owner, or by the Pod if it has no
+// owner.
+func workload(ev Event) string {
+	target := owner(ev)
+	if target == "" {
+		target = ev.Pod
+	}
+	if ev.Cluster == "" {
+		return ev.Namespace + "/" + target
+	}
+	return ev.Cluster + "/" + ev.Namespace + "/" + target
+}
+
+// alertKey deduplicates the alerts of a workload in incident management sinks.
+func alertKey(ev Event) string {
+	return "k8s-healer/" + workload(ev)
+}
@@ -0,0 +1,113 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// slackPostMessageURL is the Web API method used with bot tokens.
+const slackPostMessageURL = "https://slack.com/api/chat.postMessage"
+
+// SlackConfig configures the "slack" sink. Messages are posted through an incoming webhook, which
+// posts to the channel it was created for, or with a bot token to a channel chosen per event.
+type SlackConfig struct {
+	WebhookURL string `json:"webhookURL,omitempty"`
+	Token      string `json:"token,omitempty"` // Bot token (xoxb-...) with the chat:write scope
+
+	// Channel receives the events of namespaces without a channel of their own. With a bot token a
+	// channel is required, here, per namespace or in the routes.
+	Channel string `json:"channel,omitempty"`
+	// NamespaceChannels maps namespace globs to channels. A channel set by the matching route wins.
+	NamespaceChannels map[string]string `json:"namespaceChannels,omitempty"`
+}
+
+// SlackNotifier posts events to Slack.
+type SlackNotifier struct {
+	cfg      SlackConfig
+	channels *channelMatcher
+}
+
+// NewSlackNotifier validates the configuration and returns the sink.
+func NewSlackNotifier(cfg SlackConfig) (*SlackNotifier, error) {
+	cfg.WebhookURL, cfg.Token = secret(cfg.WebhookURL), secret(cfg.Token)
+	if (cfg.WebhookURL == "") == (cfg.Token == "") {
+		return nil, fmt.Errorf("exactly one of webhookURL and token is required")
+	}
+	channels, err := newChannelMatcher(cfg.Channel, cfg.NamespaceChannels)
+	if err != nil {
+		return nil, err
+	}
+	return &SlackNotifier{cfg: cfg, channels: channels}, nil
+}
+
+// Name implements Notifier.
+func (s *SlackNotifier) Name() string { return "slack" }
+
+// Notify implements Notifier.
+func (s *SlackNotifier) Notify(ctx context.Context, ev Event) error {
+	msg := slackMessage(ev)
+	msg.Channel = s.channels.channel(ev)
+
+	if s.cfg.WebhookURL != "" {
+		_, err := postJSON(ctx, s.cfg.WebhookURL, msg, nil)
+		return err
+	}
+	if msg.Channel == "" {
+		return fmt.Errorf("no channel for namespace %q", ev.Namespace)
+	}
+	body, err := postJSON(ctx, slackPostMessageURL, msg, map[string]string{"Authorization": "Bearer " + s.cfg.Token})
+	if err != nil {
+		return err
+	}
+	// The Web API reports failures in the body of 200 responses
+	var resp struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+	if !resp.OK {
+		return fmt.Errorf("chat.postMessage: %s", resp.Error)
+	}
+	return nil
+}
+
+type slackPayload struct {
+	Channel     string            `json:"channel,omitempty"`
+	Text        string            `json:"text"`
+	Attachments []slackAttachment `json:"attachments,omitempty"`
+}
+
+type slackAttachment struct {
+	Color  string       `json:"color"`
+	Text   string       `json:"text,omitempty"`
+	Fields []slackField `json:"fields,omitempty"`
+	Ts     int64        `json:"ts"`
+}
+
+type slackField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+// slackMessage renders the event: the title as the message text, which notifications show, and
+// the details in an attachment colored by severity.
+func slackMessage(ev Event) slackPayload {
+	var fields []slackField
+	for _, d := range eventDetails(ev) {
+		fields = append(fields, slackField{Title: d.name, Value: d.value, Short: d.name != "Reason"})
+	}
+
+	return slackPayload{
+		Text: fmt.Sprintf("%s %s", severityEmoji(ev.Severity), ev.Title()),
+		Attachments: []slackAttachment{{
+			Color:  severityColor(ev.Severity),
+			Text:   ev.Message,
+			Fields: fields,
+			Ts:     ev.Time.Unix(),
+		}},
+	}
+}
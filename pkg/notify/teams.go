@@ -0,0 +1,107 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// TeamsConfig configures the "teams" sink, which posts connector cards to Microsoft Teams incoming
+// webhooks. A webhook posts to the channel it was created for, so channels are named here and
+// mapped to their webhooks; routes and namespaces then pick channels by name, as with Slack.
+type TeamsConfig struct {
+	// WebhookURL receives the events of namespaces without a channel of their own.
+	WebhookURL string `json:"webhookURL,omitempty"`
+	// Channels maps channel names, as used by NamespaceChannels and the routes, to webhook URLs.
+	Channels map[string]string `json:"channels,omitempty"`
+	// NamespaceChannels maps namespace globs to channel names. A channel set by the matching route wins.
+	NamespaceChannels map[string]string `json:"namespaceChannels,omitempty"`
+}
+
+// TeamsNotifier posts events to Microsoft Teams.
+type TeamsNotifier struct {
+	cfg      TeamsConfig
+	channels *channelMatcher
+}
+
+// NewTeamsNotifier validates the configuration and returns the sink.
+func NewTeamsNotifier(cfg TeamsConfig) (*TeamsNotifier, error) {
+	cfg.WebhookURL = secret(cfg.WebhookURL)
+	webhooks := make(map[string]string, len(cfg.Channels))
+	for name, url := range cfg.Channels {
+		if webhooks[name] = secret(url); webhooks[name] == "" {
+			return nil, fmt.Errorf("channel %q has no webhook URL", name)
+		}
+	}
+	cfg.Channels = webhooks
+	if cfg.WebhookURL == "" && len(cfg.Channels) == 0 {
+		return nil, fmt.Errorf("webhookURL or channels is required")
+	}
+	for p, name := range cfg.NamespaceChannels {
+		if _, ok := cfg.Channels[name]; !ok {
+			return nil, fmt.Errorf("namespace %q references unknown channel %q", p, name)
+		}
+	}
+	channels, err := newChannelMatcher("", cfg.NamespaceChannels)
+	if err != nil {
+		return nil, err
+	}
+	return &TeamsNotifier{cfg: cfg, channels: channels}, nil
+}
+
+// Name implements Notifier.
+func (t *TeamsNotifier) Name() string { return "teams" }
+
+// Notify implements Notifier.
+func (t *TeamsNotifier) Notify(ctx context.Context, ev Event) error {
+	// Channels named for other sinks by a route shared with them go to the default webhook
+	url, ok := t.cfg.Channels[t.channels.channel(ev)]
+	if !ok {
+		url = t.cfg.WebhookURL
+	}
+	if url == "" {
+		return fmt.Errorf("no webhook for namespace %q", ev.Namespace)
+	}
+	_, err := postJSON(ctx, url, teamsMessage(ev), nil)
+	return err
+}
+
+type teamsCard struct {
+	Type       string         `json:"@type"`
+	Context    string         `json:"@context"`
+	ThemeColor string         `json:"themeColor"`
+	Summary    string         `json:"summary"`
+	Title      string         `json:"title"`
+	Text       string         `json:"text,omitempty"`
+	Sections   []teamsSection `json:"sections,omitempty"`
+}
+
+type teamsSection struct {
+	Facts []teamsFact `json:"facts"`
+}
+
+type teamsFact struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// teamsMessage renders the event as a connector card: the title, which notifications show, the
+// message and the details as facts, with the card's accent colored by severity.
+func teamsMessage(ev Event) teamsCard {
+	var facts []teamsFact
+	for _, d := range eventDetails(ev) {
+		facts = append(facts, teamsFact{Name: d.name, Value: d.value})
+	}
+	facts = append(facts, teamsFact{Name: "Time", Value: ev.Time.UTC().Format("2006-01-02 15:04:05 MST")})
+
+	title := fmt.Sprintf("%s %s", severityEmoji(ev.Severity), ev.Title())
+	return teamsCard{
+		Type:       "MessageCard",
+		Context:    "https://schema.org/extensions",
+		ThemeColor: strings.TrimPrefix(severityColor(ev.Severity), "#"),
+		Summary:    ev.Title(),
+		Title:      title,
+		Text:       ev.Message,
+		Sections:   []teamsSection{{Facts: facts}},
+	}
+}
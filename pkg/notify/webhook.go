@@ -0,0 +1,165 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Defaults of WebhookConfig.
+const (
+	DefaultWebhookTimeout      = 10 * time.Second
+	DefaultWebhookRetries      = 3
+	DefaultWebhookRetryBackoff = time.Second
+)
+
+// WebhookConfig configures a generic webhook sink, which sends events to any HTTP endpoint with a
+// body rendered from a template.
+type WebhookConfig struct {
+	// Name is the sink's name in the routes.
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Method string `json:"method,omitempty"` // POST unless set
+	// Headers are added to every request, e.g. Authorization. Values may reference environment
+	// variables.
+	Headers     map[string]string `json:"headers,omitempty"`
+	ContentType string            `json:"contentType,omitempty"` // application/json unless set
+	// Template is a Go text/template rendered over the Event. The event as JSON is sent if empty.
+	Template string `json:"template,omitempty"`
+
+	// Timeout bounds each attempt.
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// Retries is how many times a failed delivery is retried: on connection errors, 429 and 5xx
+	// responses. The wait between attempts starts at RetryBackoff and doubles.
+	Retries      *int             `json:"retries,omitempty"`
+	RetryBackoff *metav1.Duration `json:"retryBackoff,omitempty"`
+}
+
+// webhookFuncs are available to webhook templates besides the builtins.
+var webhookFuncs = template.FuncMap{
+	// json encodes a value, e.g. {{ json .Message }} for a quoted and escaped string
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// WebhookNotifier sends events to an HTTP endpoint.
+type WebhookNotifier struct {
+	cfg     WebhookConfig
+	tmpl    *template.Template
+	timeout time.Duration
+	retries int
+	backoff time.Duration
+}
+
+// NewWebhookNotifier validates the configuration, parses the template and returns the sink.
+func NewWebhookNotifier(cfg WebhookConfig) (*WebhookNotifier, error) {
+	if cfg.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	cfg.URL = secret(cfg.URL)
+	if u, err := url.Parse(cfg.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid url %q (expected an http or https URL)", cfg.URL)
+	}
+	if cfg.Method == "" {
+		cfg.Method = http.MethodPost
+	}
+	cfg.Method = strings.ToUpper(cfg.Method)
+	if cfg.ContentType == "" {
+		cfg.ContentType = "application/json"
+	}
+	headers := make(map[string]string, len(cfg.Headers))
+	for k, v := range cfg.Headers {
+		headers[k] = secret(v)
+	}
+	cfg.Headers = headers
+	if cfg.Template == "" {
+		cfg.Template = "{{ json . }}"
+	}
+	tmpl, err := template.New(cfg.Name).Funcs(webhookFuncs).Option("missingkey=error").Parse(cfg.Template)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+
+	w := &WebhookNotifier{cfg: cfg, tmpl: tmpl, timeout: DefaultWebhookTimeout, retries: DefaultWebhookRetries,
+		backoff: DefaultWebhookRetryBackoff}
+	if cfg.Timeout != nil {
+		w.timeout = cfg.Timeout.Duration
+	}
+	if cfg.Retries != nil {
+		w.retries = *cfg.Retries
+	}
+	if cfg.RetryBackoff != nil {
+		w.backoff = cfg.RetryBackoff.Duration
+	}
+	if w.timeout <= 0 || w.retries < 0 || w.backoff < 0 {
+		return nil, fmt.Errorf("timeout must be positive, retries and retryBackoff not negative")
+	}
+	// Catch templates that can't render an event at startup rather than on the first heal
+	if _, err := w.render(Event{Time: time.Now()}); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Name implements Notifier.
+func (w *WebhookNotifier) Name() string { return w.cfg.Name }
+
+// Timeout covers every attempt and the waits between them.
+func (w *WebhookNotifier) Timeout() time.Duration {
+	total := time.Duration(w.retries+1) * w.timeout
+	for i, wait := 0, w.backoff; i < w.retries; i, wait = i+1, wait*2 {
+		total += wait
+	}
+	return total
+}
+
+// Notify implements Notifier.
+func (w *WebhookNotifier) Notify(ctx context.Context, ev Event) error {
+	body, err := w.render(ev)
+	if err != nil {
+		return err
+	}
+	wait := w.backoff
+	for attempt := 0; ; attempt++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, w.timeout)
+		_, err = send(attemptCtx, w.cfg.Method, w.cfg.URL, w.cfg.ContentType, body, w.cfg.Headers)
+		cancel()
+		if err == nil || attempt == w.retries || !retryable(err) {
+			return err
+		}
+		select {
+		case <-time.After(wait):
+			wait *= 2
+		case <-ctx.Done():
+			return fmt.Errorf("giving up after %d attempts: %w", attempt+1, err)
+		}
+	}
+}
+
+func (w *WebhookNotifier) render(ev Event) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := w.tmpl.Execute(&buf, ev); err != nil {
+		return nil, fmt.Errorf("rendering template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// retryable reports whether a delivery that failed with err may succeed when retried.
+func retryable(err error) bool {
+	var status *statusError
+	if errors.As(err, &status) {
+		return status.code == http.StatusTooManyRequests || status.code >= 500
+	}
+	return true
+}
@@ -0,0 +1,117 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cron is a parsed five-field cron expression: minute, hour, day of month, month, day of week.
+// Fields support `*`, single values, ranges (`1-5`), lists (`1,3,5`) and steps (`*/15`, `0-30/10`).
+// Day of week is 0-6 with 0 = Sunday (7 is accepted as Sunday too).
+type Cron struct {
+	spec    string
+	minutes [60]bool
+	hours   [24]bool
+	days    [32]bool // 1-31
+	months  [13]bool // 1-12
+	weekday [7]bool
+
+	// As in classic cron, when both day of month and day of week are restricted a day matches if either does.
+	daysRestricted, weekdayRestricted bool
+}
+
+// ParseCron parses a five-field cron expression.
+func ParseCron(spec string) (*Cron, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron expression %q: expected 5 fields, got %d", spec, len(fields))
+	}
+
+	c := &Cron{spec: strings.Join(fields, " ")}
+	var err error
+	if err = parseField(fields[0], 0, 59, c.minutes[:]); err != nil {
+		return nil, fmt.Errorf("invalid minute in %q: %w", spec, err)
+	}
+	if err = parseField(fields[1], 0, 23, c.hours[:]); err != nil {
+		return nil, fmt.Errorf("invalid hour in %q: %w", spec, err)
+	}
+	if err = parseField(fields[2], 1, 31, c.days[:]); err != nil {
+		return nil, fmt.Errorf("invalid day of month in %q: %w", spec, err)
+	}
+	if err = parseField(fields[3], 1, 12, c.months[:]); err != nil {
+		return nil, fmt.Errorf("invalid month in %q: %w", spec, err)
+	}
+	var weekday [8]bool
+	if err = parseField(fields[4], 0, 7, weekday[:]); err != nil {
+		return nil, fmt.Errorf("invalid day of week in %q: %w", spec, err)
+	}
+	copy(c.weekday[:], weekday[:7])
+	c.weekday[0] = c.weekday[0] || weekday[7]
+
+	c.daysRestricted = fields[2] != "*"
+	c.weekdayRestricted = fields[4] != "*"
+	return c, nil
+}
+
+// String returns the normalized expression.
+func (c *Cron) String() string {
+	return c.spec
+}
+
+// parseField sets set[v] for every value matched by the field within [min, max].
+func parseField(field string, min, max int, set []bool) error {
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			s, err := strconv.Atoi(part[i+1:])
+			if err != nil || s < 1 {
+				return fmt.Errorf("invalid step %q", part[i+1:])
+			}
+			step, part = s, part[:i]
+		}
+
+		lo, hi := min, max
+		switch {
+		case part == "*":
+		case strings.Contains(part, "-"):
+			bounds := strings.SplitN(part, "-", 2)
+			var err error
+			if lo, err = strconv.Atoi(bounds[0]); err != nil {
+				return fmt.Errorf("invalid value %q", bounds[0])
+			}
+			if hi, err = strconv.Atoi(bounds[1]); err != nil {
+				return fmt.Errorf("invalid value %q", bounds[1])
+			}
+		default:
+			v, err := strconv.Atoi(part)
+			if err != nil {
+				return fmt.Errorf("invalid value %q", part)
+			}
+			lo, hi = v, v
+			if step > 1 {
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return fmt.Errorf("value %q out of range %d-%d", part, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			set[v] = true
+		}
+	}
+	return nil
+}
+
+// matchesDay reports whether the cron fires on the given date.
+func (c *Cron) matchesDay(t time.Time) bool {
+	if !c.months[t.Month()] {
+		return false
+	}
+	dom, dow := c.days[t.Day()], c.weekday[t.Weekday()]
+	if c.daysRestricted && c.weekdayRestricted {
+		return dom || dow
+	}
+	return dom && dow
+}
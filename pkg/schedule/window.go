@@ -0,0 +1,159 @@
+package schedule
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/daigoro86dev/k8s-healer/pkg/util"
+)
+
+// Window is a recurring period of time: it opens whenever its cron expression fires, evaluated on
+// the wall clock of its time zone, and stays open for its duration.
+//
+// Evaluation is DST-correct: start times are resolved on the local calendar, so "0 22 * * *" opens
+// at 22:00 local time all year round. A start time skipped by a spring-forward transition is shifted
+// forward by the length of the gap (02:30 becomes 03:30); an ambiguous start time during fall-back
+// uses its first occurrence. The duration is elapsed time.
+type Window struct {
+	Cron     *Cron
+	Duration time.Duration
+	Location *time.Location // nil means the Zones default at evaluation time
+}
+
+// ParseWindow parses a window spec of the form `[TZ=<IANA zone>] <cron expression>`, e.g.
+// `TZ=Europe/Berlin 0 22 * * 5-6`. Without a TZ prefix the window follows the Zones it is evaluated with.
+func ParseWindow(spec string, duration time.Duration) (*Window, error) {
+	if duration <= 0 {
+		return nil, fmt.Errorf("window %q needs a positive duration", spec)
+	}
+
+	w := &Window{Duration: duration}
+	spec = strings.TrimSpace(spec)
+	for _, prefix := range []string{"TZ=", "CRON_TZ="} {
+		if strings.HasPrefix(spec, prefix) {
+			fields := strings.SplitN(strings.TrimPrefix(spec, prefix), " ", 2)
+			loc, err := time.LoadLocation(fields[0])
+			if err != nil {
+				return nil, fmt.Errorf("invalid time zone in window %q: %w", spec, err)
+			}
+			w.Location = loc
+			spec = ""
+			if len(fields) == 2 {
+				spec = fields[1]
+			}
+			break
+		}
+	}
+
+	cron, err := ParseCron(spec)
+	if err != nil {
+		return nil, err
+	}
+	w.Cron = cron
+	return w, nil
+}
+
+// String returns the window in its spec form followed by its duration.
+func (w *Window) String() string {
+	s := w.Cron.String()
+	if w.Location != nil {
+		s = "TZ=" + w.Location.String() + " " + s
+	}
+	return fmt.Sprintf("%s (for %s)", s, w.Duration)
+}
+
+// ActiveAt returns whether the window is open at t, evaluated in loc unless the window has its own
+// time zone, together with the end of the current opening.
+func (w *Window) ActiveAt(t time.Time, loc *time.Location) (bool, time.Time) {
+	if w.Location != nil {
+		loc = w.Location
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	local := t.In(loc)
+
+	// Any opening covering t started at most Duration ago; walk back over the calendar days involved.
+	days := int(w.Duration/(24*time.Hour)) + 1
+	var end time.Time
+	for d := 0; d <= days; d++ {
+		y, m, day := local.Date()
+		date := time.Date(y, m, day-d, 12, 0, 0, 0, loc)
+		if !w.Cron.matchesDay(date) {
+			continue
+		}
+		for hour := 0; hour < 24; hour++ {
+			if !w.Cron.hours[hour] {
+				continue
+			}
+			for minute := 0; minute < 60; minute++ {
+				if !w.Cron.minutes[minute] {
+					continue
+				}
+				start := wallTime(date, hour, minute, loc)
+				if start.After(t) {
+					continue
+				}
+				if e := start.Add(w.Duration); t.Before(e) && e.After(end) {
+					end = e
+				}
+			}
+		}
+	}
+	return !end.IsZero(), end
+}
+
+// wallTime returns the instant the wall clock in loc shows hour:minute on the given date. Times in a
+// DST gap are shifted forward by time.Date; for ambiguous times the earlier occurrence is returned.
+func wallTime(date time.Time, hour, minute int, loc *time.Location) time.Time {
+	t := time.Date(date.Year(), date.Month(), date.Day(), hour, minute, 0, 0, loc)
+	for _, shift := range []time.Duration{time.Hour, 30 * time.Minute} {
+		earlier := t.Add(-shift)
+		if earlier.Hour() == hour && earlier.Minute() == minute && earlier.Day() == t.Day() {
+			return earlier
+		}
+	}
+	return t
+}
+
+// Zones resolves the time zone schedules are evaluated in: a global default, overridable per namespace.
+type Zones struct {
+	Default    *time.Location
+	Namespaces map[string]*time.Location // exact namespace names or globs
+}
+
+// ParseZones builds Zones from a default IANA zone name (empty means UTC) and namespace=zone overrides.
+func ParseZones(defaultZone string, overrides map[string]string) (*Zones, error) {
+	z := &Zones{Default: time.UTC, Namespaces: make(map[string]*time.Location, len(overrides))}
+	if defaultZone != "" {
+		loc, err := time.LoadLocation(defaultZone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time zone %q: %w", defaultZone, err)
+		}
+		z.Default = loc
+	}
+	for ns, zone := range overrides {
+		loc, err := time.LoadLocation(strings.TrimSpace(zone))
+		if err != nil {
+			return nil, fmt.Errorf("invalid time zone %q for %s: %w", zone, ns, err)
+		}
+		z.Namespaces[ns] = loc
+	}
+	return z, nil
+}
+
+// For returns the time zone for the namespace: the one of the most specific matching override, or
+// the default. An empty namespace yields the default.
+func (z *Zones) For(namespace string) *time.Location {
+	if z == nil {
+		return time.UTC
+	}
+	if pattern, ok := util.MostSpecificGlob(z.Namespaces, namespace); ok && namespace != "" {
+		return z.Namespaces[pattern]
+	}
+	if z.Default == nil {
+		return time.UTC
+	}
+	return z.Default
+}
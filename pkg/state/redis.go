@@ -0,0 +1,131 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultRedisPrefix prefixes the Redis keys of the state.
+const DefaultRedisPrefix = "k8s-healer"
+
+// redisTimeout bounds every Redis call.
+const redisTimeout = 10 * time.Second
+
+// releaseScript deletes a claim only if its holder still has it, so a claim that expired and was
+// taken by another healer isn't released.
+var releaseScript = redis.NewScript(`if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("DEL", KEYS[1]) end return 0`)
+
+// Redis keeps the state in Redis, one hash per bucket, so several healers share it. Claims are
+// separate keys that expire on their own.
+type Redis struct {
+	client *redis.Client
+	prefix string
+}
+
+// OpenRedis connects to the Redis server at url (redis://[user:password@]host:port/db, or rediss://
+// for TLS). Keys are prefixed with prefix, so healers sharing a prefix share their state.
+func OpenRedis(url, prefix string) (*Redis, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Redis URL: %w", err)
+	}
+	if prefix == "" {
+		prefix = DefaultRedisPrefix
+	}
+	r := &Redis{client: redis.NewClient(opts), prefix: prefix}
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	if err := r.client.Ping(ctx).Err(); err != nil {
+		_ = r.client.Close()
+		return nil, fmt.Errorf("failed to connect to Redis at %s: %w", opts.Addr, err)
+	}
+	return r, nil
+}
+
+func (r *Redis) key(bucket string) string {
+	return r.prefix + ":" + bucket
+}
+
+// Close closes the connection.
+func (r *Redis) Close() error {
+	return r.client.Close()
+}
+
+// Get returns the entry of the bucket, and whether it exists.
+func (r *Redis) Get(bucket, key string) ([]byte, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	value, err := r.client.HGet(ctx, r.key(bucket), key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+// Claim reserves the key for holder for ttl. It returns false if another holder has it.
+func (r *Redis) Claim(key, holder string, ttl time.Duration) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	return r.client.SetNX(ctx, r.key("claims:"+key), holder, ttl).Result()
+}
+
+// Release gives up holder's reservation of the key.
+func (r *Redis) Release(key, holder string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	return releaseScript.Run(ctx, r.client, []string{r.key("claims:" + key)}, holder).Err()
+}
+
+// Put adds or updates entries of the bucket.
+func (r *Redis) Put(bucket string, entries map[string][]byte) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	values := make([]interface{}, 0, 2*len(entries))
+	for key, value := range entries {
+		values = append(values, key, value)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	return r.client.HSet(ctx, r.key(bucket), values...).Err()
+}
+
+// Delete removes entries of the bucket.
+func (r *Redis) Delete(bucket string, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	return r.client.HDel(ctx, r.key(bucket), keys...).Err()
+}
+
+// ForEach calls fn for every entry of the bucket, in key order, stopping at the first error.
+func (r *Redis) ForEach(bucket string, fn func(key string, value []byte) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	entries, err := r.client.HGetAll(ctx, r.key(bucket)).Result()
+	if err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err := fn(key, []byte(entries[key])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
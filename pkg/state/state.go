@@ -0,0 +1,162 @@
+// Package state persists the healer's state (heal history, cooldowns, quarantines) across restarts,
+// in an embedded bbolt database or in Redis shared by several healers.
+package state
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// FileName is the database file created in the state directory.
+const FileName = "k8s-healer.db"
+
+// Buckets of the state. Each maps keys to JSON-encoded values.
+const (
+	BucketCooldowns   = "cooldowns"
+	BucketQuarantines = "quarantines"
+	BucketHistory     = "history" // Heal records, keyed by time so they iterate in order
+	BucketRollups     = "rollups" // Monthly history rollups
+)
+
+var buckets = []string{BucketCooldowns, BucketQuarantines, BucketHistory, BucketRollups}
+
+// Store holds buckets of entries. Several healers may share one, so entries are only ever added,
+// updated or deleted individually.
+type Store interface {
+	// Get returns the entry of the bucket, and whether it exists.
+	Get(bucket, key string) ([]byte, bool, error)
+	// Put adds or updates entries of the bucket.
+	Put(bucket string, entries map[string][]byte) error
+	// Delete removes entries of the bucket.
+	Delete(bucket string, keys []string) error
+	// ForEach calls fn for every entry of the bucket, in key order, stopping at the first error.
+	// The value is only valid during the call.
+	ForEach(bucket string, fn func(key string, value []byte) error) error
+	Close() error
+}
+
+// Claimer is a Store shared by several healers that can reserve keys for one of them at a time.
+type Claimer interface {
+	// Claim reserves the key for holder for ttl. It returns false if another holder has it.
+	Claim(key, holder string, ttl time.Duration) (bool, error)
+	// Release gives up holder's reservation of the key.
+	Release(key, holder string) error
+}
+
+// DB is the state database in a state directory.
+type DB struct {
+	db *bolt.DB
+}
+
+// Open opens the state database in dir, creating both if they don't exist. Only one process can
+// have it open for writing.
+func Open(dir string) (*DB, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create state directory %s: %w", dir, err)
+	}
+	path := filepath.Join(dir, FileName)
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state database %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range buckets {
+			if _, err := tx.CreateBucketIfNotExists([]byte(name)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to initialize state database %s: %w", path, err)
+	}
+	return &DB{db: db}, nil
+}
+
+// OpenReadOnly opens an existing state database for reading. It fails while a healer has the
+// database open.
+func OpenReadOnly(dir string) (*DB, error) {
+	path := filepath.Join(dir, FileName)
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("no state database in %s: %w", dir, err)
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second, ReadOnly: true})
+	if errors.Is(err, bolt.ErrTimeout) {
+		return nil, fmt.Errorf("state database %s is in use by a running healer", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state database %s: %w", path, err)
+	}
+	return &DB{db: db}, nil
+}
+
+// Close closes the database.
+func (d *DB) Close() error {
+	return d.db.Close()
+}
+
+// Get returns the entry of the bucket, and whether it exists.
+func (d *DB) Get(bucket, key string) ([]byte, bool, error) {
+	var value []byte
+	err := d.db.View(func(tx *bolt.Tx) error {
+		if b := tx.Bucket([]byte(bucket)); b != nil {
+			if v := b.Get([]byte(key)); v != nil {
+				value = append([]byte(nil), v...)
+			}
+		}
+		return nil
+	})
+	return value, value != nil, err
+}
+
+// Put adds or updates entries of the bucket.
+func (d *DB) Put(bucket string, entries map[string][]byte) error {
+	return d.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(bucket))
+		if err != nil {
+			return err
+		}
+		for key, value := range entries {
+			if err := b.Put([]byte(key), value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Delete removes entries of the bucket.
+func (d *DB) Delete(bucket string, keys []string) error {
+	return d.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+		if b == nil {
+			return nil
+		}
+		for _, key := range keys {
+			if err := b.Delete([]byte(key)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ForEach calls fn for every entry of the bucket, in key order, stopping at the first error. The
+// value is only valid during the call.
+func (d *DB) ForEach(bucket string, fn func(key string, value []byte) error) error {
+	return d.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			return fn(string(k), v)
+		})
+	})
+}
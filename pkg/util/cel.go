@@ -0,0 +1,102 @@
+package util
+
+import (
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// CELCondition is a user-defined unhealthiness condition written as a CEL expression over the Pod.
+// The Pod's top-level fields are exposed as the variables `metadata`, `spec` and `status`, and the
+// whole object as `pod`, e.g. `status.containerStatuses.exists(c, c.restartCount > 10)`.
+type CELCondition struct {
+	Expression string
+	program    cel.Program
+}
+
+// celEnv declares the variables available to custom condition expressions.
+func celEnv() (*cel.Env, error) {
+	return cel.NewEnv(
+		cel.Variable("pod", cel.DynType),
+		cel.Variable("metadata", cel.DynType),
+		cel.Variable("spec", cel.DynType),
+		cel.Variable("status", cel.DynType),
+	)
+}
+
+// CompileCELConditions parses and type-checks the given expressions. Every expression must evaluate to a bool.
+func CompileCELConditions(expressions []string) ([]*CELCondition, error) {
+	if len(expressions) == 0 {
+		return nil, nil
+	}
+
+	env, err := celEnv()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CEL environment: %w", err)
+	}
+
+	conditions := make([]*CELCondition, 0, len(expressions))
+	for _, expr := range expressions {
+		ast, issues := env.Compile(expr)
+		if issues != nil && issues.Err() != nil {
+			return nil, fmt.Errorf("invalid CEL condition %q: %w", expr, issues.Err())
+		}
+		if ast.OutputType() != cel.BoolType && ast.OutputType() != cel.DynType {
+			return nil, fmt.Errorf("CEL condition %q must evaluate to a bool, got %s", expr, ast.OutputType())
+		}
+
+		program, err := env.Program(ast)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build CEL program for %q: %w", expr, err)
+		}
+		conditions = append(conditions, &CELCondition{Expression: expr, program: program})
+	}
+
+	return conditions, nil
+}
+
+// Matches evaluates the condition against the Pod. Expressions referencing absent fields
+// (e.g. a Pod without container statuses yet) produce an error rather than a match.
+func (c *CELCondition) Matches(pod *v1.Pod) (bool, error) {
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(pod)
+	if err != nil {
+		return false, fmt.Errorf("failed to convert pod for CEL evaluation: %w", err)
+	}
+
+	out, _, err := c.program.Eval(map[string]interface{}{
+		"pod":      obj,
+		"metadata": obj["metadata"],
+		"spec":     obj["spec"],
+		"status":   obj["status"],
+	})
+	if err != nil {
+		return false, err
+	}
+
+	matched, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("CEL condition %q returned non-bool value %v", c.Expression, out.Value())
+	}
+	return matched, nil
+}
+
+// MatchingCELCondition returns the first condition that matches the Pod, or nil if none do.
+// Conditions that fail to evaluate don't match; onError, if not nil, is told about them.
+func MatchingCELCondition(pod *v1.Pod, conditions []*CELCondition, onError func(*CELCondition, error)) *CELCondition {
+	for _, c := range conditions {
+		matched, err := c.Matches(pod)
+		if err != nil {
+			// Missing fields are common for Pods that are still starting; treat them as "no match"
+			if onError != nil {
+				onError(c, err)
+			}
+			continue
+		}
+		if matched {
+			return c
+		}
+	}
+	return nil
+}
@@ -13,21 +13,24 @@ const DefaultRestartThreshold = 3
 // IsUnhealthy checks if a Pod exhibits signs of persistent failure that requires healing.
 // This function implements the core criteria: currently only CrashLoopBackOff.
 func IsUnhealthy(pod *v1.Pod) bool {
-	// A Pod is considered unhealthy if any of its containers are in CrashLoopBackOff
-	// and have exceeded the restart threshold.
+	_, failed := CrashLoopFailure(pod, DefaultRestartThreshold)
+	return failed
+}
+
+// CrashLoopFailure reports whether a container is in CrashLoopBackOff after restarting at least
+// threshold times. It returns the heal reason when the check fails.
+func CrashLoopFailure(pod *v1.Pod, threshold int32) (string, bool) {
 	for _, status := range pod.Status.ContainerStatuses {
 		if status.State.Waiting != nil && status.State.Waiting.Reason == "CrashLoopBackOff" {
-			if status.RestartCount >= DefaultRestartThreshold {
-				fmt.Printf("   [Check] 🚨 Pod %s/%s failed check: CrashLoopBackOff (Restarts: %d).\n",
-					pod.Namespace, pod.Name, status.RestartCount)
-				return true
+			if status.RestartCount >= threshold {
+				return fmt.Sprintf("Persistent CrashLoopBackOff (Restarts: %d)", status.RestartCount), true
 			}
 		}
 	}
 
 	// Add checks for other failure phases like PodFailed, or ImagePullBackOff here if needed.
 
-	return false
+	return "", false
 }
 
 // GetHealReason retrieves the specific reason for the healing action.
@@ -41,3 +44,132 @@ func GetHealReason(pod *v1.Pod) string {
 	}
 	return "Unspecified Failure"
 }
+
+// StartupProbeFailure reports whether a container keeps being restarted by its startupProbe without
+// ever starting. Such containers are killed and restarted by the kubelet and, depending on the probe
+// timings, may never be observed in CrashLoopBackOff. Containers must have been restarted at least
+// threshold times. It returns the heal reason when the check fails.
+func StartupProbeFailure(pod *v1.Pod, threshold int32) (string, bool) {
+	probed := make(map[string]bool)
+	for _, c := range pod.Spec.Containers {
+		if c.StartupProbe != nil {
+			probed[c.Name] = true
+		}
+	}
+	if len(probed) == 0 {
+		return "", false
+	}
+
+	for _, status := range pod.Status.ContainerStatuses {
+		if !probed[status.Name] || status.Started == nil || *status.Started {
+			continue
+		}
+		// Only count containers that were actually restarted, i.e. killed during the startup phase
+		if status.LastTerminationState.Terminated == nil || status.RestartCount < threshold {
+			continue
+		}
+		return fmt.Sprintf("Startup probe failing for container %s (Restarts: %d)", status.Name, status.RestartCount), true
+	}
+
+	return "", false
+}
+
+// Termination describes the most recent termination of a Pod's failing container.
+type Termination struct {
+	Container string
+	ExitCode  int32
+	Reason    string // e.g. "OOMKilled", "Error"
+}
+
+// LastTermination returns the last termination of the container with the most restarts,
+// or nil if no container has terminated yet.
+func LastTermination(pod *v1.Pod) *Termination {
+	var best *Termination
+	var bestRestarts int32 = -1
+	for _, status := range pod.Status.ContainerStatuses {
+		t := status.LastTerminationState.Terminated
+		if t == nil {
+			t = status.State.Terminated
+		}
+		if t == nil || status.RestartCount <= bestRestarts {
+			continue
+		}
+		best = &Termination{Container: status.Name, ExitCode: t.ExitCode, Reason: t.Reason}
+		bestRestarts = status.RestartCount
+	}
+	return best
+}
+
+// DescribeExitCode classifies well-known container exit codes.
+func DescribeExitCode(code int32) string {
+	switch code {
+	case 0:
+		return "completed"
+	case 1:
+		return "application error"
+	case 2:
+		return "misuse of shell builtin"
+	case 126:
+		return "command not executable"
+	case 127:
+		return "command not found"
+	case 134:
+		return "SIGABRT"
+	case 137:
+		return "SIGKILL (OOMKilled or force-killed)"
+	case 139:
+		return "SIGSEGV"
+	case 143:
+		return "SIGTERM"
+	}
+	if code > 128 && code < 160 {
+		return fmt.Sprintf("killed by signal %d", code-128)
+	}
+	return "unknown"
+}
+
+// String formats the termination for heal reasons, e.g. "exit code 137 (OOMKilled) in container app".
+func (t *Termination) String() string {
+	reason := t.Reason
+	if reason == "" || reason == "Error" {
+		reason = DescribeExitCode(t.ExitCode)
+	}
+	return fmt.Sprintf("exit code %d (%s) in container %s", t.ExitCode, reason, t.Container)
+}
+
+// disruptionReasons maps Pod status reasons set when a Pod is terminated by preemption or a node
+// shutdown to a human-readable description.
+var disruptionReasons = map[string]string{
+	"Preempted":    "preempted",
+	"Preempting":   "preempted by the kubelet",
+	"NodeShutdown": "terminated by node shutdown",
+	"Shutdown":     "terminated by node shutdown",
+	"Terminated":   "terminated by node shutdown",
+}
+
+// disruptionConditionReasons are the DisruptionTarget condition reasons that indicate preemption
+// or node shutdown rather than an application failure.
+var disruptionConditionReasons = map[string]string{
+	"PreemptionByScheduler": "preempted by the scheduler",
+	"TerminationByKubelet":  "terminated by the kubelet (node shutdown or pressure)",
+}
+
+// DisruptionReason reports whether a Failed Pod was terminated by preemption or a node shutdown,
+// as opposed to crashing. Such Pods linger in Failed state after their controller replaced them.
+func DisruptionReason(pod *v1.Pod) (string, bool) {
+	if pod.Status.Phase != v1.PodFailed {
+		return "", false
+	}
+	if desc, ok := disruptionReasons[pod.Status.Reason]; ok {
+		return fmt.Sprintf("Pod %s (%s)", desc, pod.Status.Reason), true
+	}
+	for _, c := range pod.Status.Conditions {
+		if c.Type != v1.DisruptionTarget || c.Status != v1.ConditionTrue {
+			continue
+		}
+		if desc, ok := disruptionConditionReasons[c.Reason]; ok {
+			return fmt.Sprintf("Pod %s (%s)", desc, c.Reason), true
+		}
+	}
+	return "", false
+}
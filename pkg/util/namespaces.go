@@ -0,0 +1,97 @@
+package util
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// RegexPrefix marks a namespace pattern as a regular expression (e.g. "re:^team-(a|b)-prod$")
+// instead of a glob.
+const RegexPrefix = "re:"
+
+// namespaceRegexps caches compiled namespace regexes by pattern.
+var namespaceRegexps sync.Map
+
+// ValidateNamespacePattern checks a namespace glob or "re:" regular expression.
+func ValidateNamespacePattern(pattern string) error {
+	if expr, ok := strings.CutPrefix(pattern, RegexPrefix); ok {
+		if _, err := regexp.Compile(expr); err != nil {
+			return fmt.Errorf("invalid namespace regex %q: %w", expr, err)
+		}
+		return nil
+	}
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid namespace glob %q: %w", pattern, err)
+	}
+	return nil
+}
+
+// MatchNamespace reports whether the namespace matches the pattern: a glob in filepath.Match
+// syntax, or a regular expression with the "re:" prefix. Regexes are unanchored unless they use ^
+// and $. Invalid patterns match nothing.
+func MatchNamespace(pattern, namespace string) bool {
+	expr, ok := strings.CutPrefix(pattern, RegexPrefix)
+	if !ok {
+		matched, _ := filepath.Match(pattern, namespace)
+		return matched
+	}
+	if re, ok := namespaceRegexps.Load(expr); ok {
+		return re.(*regexp.Regexp).MatchString(namespace)
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return false
+	}
+	namespaceRegexps.Store(expr, re)
+	return re.MatchString(namespace)
+}
+
+// GlobSpecificity ranks how narrowly a namespace glob matches: an exact name above any glob, and
+// globs with more literal characters above broader ones. "re:" regexes rank below every glob.
+func GlobSpecificity(pattern string) int {
+	if strings.HasPrefix(pattern, RegexPrefix) {
+		return -1
+	}
+	if !strings.ContainsAny(pattern, "*?[") {
+		return len(pattern) + 1<<16
+	}
+	literal := 0
+	inClass := false
+	for _, r := range pattern {
+		switch {
+		case r == '[':
+			inClass = true
+		case r == ']':
+			inClass = false
+		case !inClass && r != '*' && r != '?':
+			literal++
+		}
+	}
+	return literal
+}
+
+// MostSpecificGlob returns the key of the entries whose glob matches the namespace most narrowly,
+// per GlobSpecificity. Equally specific globs are ranked in lexical order, so the result doesn't
+// depend on map iteration order.
+func MostSpecificGlob[V any](entries map[string]V, namespace string) (string, bool) {
+	best, found := "", false
+	for pattern := range entries {
+		if ok, _ := filepath.Match(pattern, namespace); !ok {
+			continue
+		}
+		if !found || GlobSpecificity(pattern) > GlobSpecificity(best) ||
+			(GlobSpecificity(pattern) == GlobSpecificity(best) && pattern < best) {
+			best, found = pattern, true
+		}
+	}
+	return best, found
+}
+
+// IsLiteralNamespace reports whether the pattern names a single namespace rather than matching by
+// glob or regex.
+func IsLiteralNamespace(pattern string) bool {
+	return !strings.HasPrefix(pattern, RegexPrefix) && !strings.ContainsAny(pattern, "*?[")
+}
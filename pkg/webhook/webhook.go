@@ -0,0 +1,152 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/daigoro86dev/k8s-healer/pkg/annotations"
+	admissionv1 "k8s.io/api/admission/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Hints reported for Pods that are likely to need healing later.
+const (
+	HintNoLivenessProbe  = "no-liveness-probe"
+	HintNoReadinessProbe = "no-readiness-probe"
+	HintNoMemoryLimit    = "no-memory-limit"
+	HintNoCPULimit       = "no-cpu-limit"
+)
+
+// Server is an admission webhook that annotates (never blocks) incoming Pods lacking probes or
+// limits with healer hints. The annotation travels with the Pod, so the healer reads the hints
+// from the Pods it heals.
+type Server struct{}
+
+// NewServer returns a webhook server.
+func NewServer() *Server {
+	return &Server{}
+}
+
+// Handler returns the HTTP handler serving /mutate (AdmissionReview).
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/mutate", s.serveMutate)
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })
+	return mux
+}
+
+// PodHints returns the hints that apply to the Pod spec, sorted.
+func PodHints(pod *v1.Pod) []string {
+	set := make(map[string]bool)
+	for _, c := range pod.Spec.Containers {
+		if c.LivenessProbe == nil {
+			set[HintNoLivenessProbe] = true
+		}
+		if c.ReadinessProbe == nil {
+			set[HintNoReadinessProbe] = true
+		}
+		if _, ok := c.Resources.Limits[v1.ResourceMemory]; !ok {
+			set[HintNoMemoryLimit] = true
+		}
+		if _, ok := c.Resources.Limits[v1.ResourceCPU]; !ok {
+			set[HintNoCPULimit] = true
+		}
+	}
+
+	hints := make([]string, 0, len(set))
+	for h := range set {
+		hints = append(hints, h)
+	}
+	sort.Strings(hints)
+	return hints
+}
+
+func (s *Server) serveMutate(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, 4<<20))
+	if err != nil {
+		http.Error(w, "failed to read request", http.StatusBadRequest)
+		return
+	}
+
+	review := &admissionv1.AdmissionReview{}
+	if err := json.Unmarshal(body, review); err != nil || review.Request == nil {
+		http.Error(w, "malformed AdmissionReview", http.StatusBadRequest)
+		return
+	}
+
+	review.Response = s.review(review.Request)
+	review.Request = nil
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(review); err != nil {
+		slog.Error("Failed to write admission response", "err", err)
+	}
+}
+
+// review always allows the request; it only adds the hints annotation and warnings.
+func (s *Server) review(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	resp := &admissionv1.AdmissionResponse{UID: req.UID, Allowed: true}
+
+	if req.Kind.Kind != "Pod" {
+		return resp
+	}
+	pod := &v1.Pod{}
+	if err := json.Unmarshal(req.Object.Raw, pod); err != nil {
+		slog.Warn("Could not decode pod", "namespace", req.Namespace, "err", err)
+		return resp
+	}
+
+	hints := PodHints(pod)
+	if len(hints) == 0 {
+		return resp
+	}
+
+	patch, err := json.Marshal(hintsPatch(pod, annotations.FormatHints(hints)))
+	if err != nil {
+		return resp
+	}
+	patchType := admissionv1.PatchTypeJSONPatch
+	resp.Patch = patch
+	resp.PatchType = &patchType
+	resp.Warnings = []string{fmt.Sprintf("k8s-healer: pod is missing %s; it will be watched closely", strings.Join(hints, ", "))}
+
+	namespace := req.Namespace
+	if namespace == "" {
+		namespace = pod.Namespace
+	}
+	logHints(namespace, pod, hints)
+	return resp
+}
+
+type jsonPatchOp struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+// hintsPatch builds the JSON patch adding the hints annotation.
+func hintsPatch(pod *v1.Pod, value string) []jsonPatchOp {
+	if len(pod.Annotations) == 0 {
+		return []jsonPatchOp{{Op: "add", Path: "/metadata/annotations", Value: map[string]string{annotations.Hints: value}}}
+	}
+	return []jsonPatchOp{{Op: "add", Path: annotations.JSONPointer(annotations.Hints), Value: value}}
+}
+
+// logHints logs the hints added to the Pod, by its workload.
+func logHints(namespace string, pod *v1.Pod, hints []string) {
+	owner := "Pod/" + pod.Name
+	if pod.Name == "" {
+		owner = "Pod/" + pod.GenerateName
+	}
+	if ref := metav1.GetControllerOf(pod); ref != nil {
+		owner = fmt.Sprintf("%s/%s", ref.Kind, ref.Name)
+	}
+
+	slog.Info("Annotated workload with hints", "owner", owner, "namespace", namespace, "hints", strings.Join(hints, ","))
+}